@@ -28,6 +28,8 @@ import (
 	"github.com/etherzero/go-etherzero/accounts/abi/bind"
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/common/math"
+	"github.com/etherzero/go-etherzero/consensus"
+	"github.com/etherzero/go-etherzero/consensus/devote"
 	"github.com/etherzero/go-etherzero/consensus/ethash"
 	"github.com/etherzero/go-etherzero/core"
 	"github.com/etherzero/go-etherzero/core/bloombits"
@@ -53,6 +55,7 @@ var errGasEstimationFailed = errors.New("gas required exceeds allowance or alway
 type SimulatedBackend struct {
 	database   ethdb.Database   // In memory database to store our testing data
 	blockchain *core.BlockChain // Ethereum blockchain to handle the consensus
+	engine     consensus.Engine // Fake consensus engine generating the simulated blocks
 
 	mu           sync.Mutex
 	pendingBlock *types.Block   // Currently pending block that will be imported on request
@@ -69,15 +72,41 @@ func NewSimulatedBackend(alloc core.GenesisAlloc, gasLimit uint64) *SimulatedBac
 	database := ethdb.NewMemDatabase()
 	genesis := core.Genesis{Config: params.AllEthashProtocolChanges, GasLimit: gasLimit, Alloc: alloc}
 	genesis.MustCommit(database)
-	blockchain, _ := core.NewBlockChain(database, nil, genesis.Config, ethash.NewFaker(), vm.Config{}, nil)
+	return newSimulatedBackend(database, &genesis, ethash.NewFaker())
+}
+
+// NewDevoteSimulatedBackend creates a new binding backend on top of a simulated
+// devote chain for testing purposes. Witnesses are elected from the given
+// in-memory masternode set, blocks are sealed on the simulated time slots and
+// every block commits its own devote protocol, so power based gas accounting
+// and block rewards behave as they do on the live network.
+func NewDevoteSimulatedBackend(alloc core.GenesisAlloc, gasLimit uint64, witnesses []string) *SimulatedBackend {
+	database := ethdb.NewMemDatabase()
+
+	config := *params.AllEthashProtocolChanges
+	config.Ethash = nil
+	config.Devote = &params.DevoteConfig{Witnesses: witnesses}
+
+	genesis := core.Genesis{Config: &config, GasLimit: gasLimit, Difficulty: big.NewInt(1), Alloc: alloc}
+	genesis.MustCommit(database)
+	return newSimulatedBackend(database, &genesis, devote.NewFaker(witnesses, database))
+}
+
+// newSimulatedBackend creates a simulated backend on top of a committed genesis,
+// generating all subsequent blocks with the given consensus engine.
+func newSimulatedBackend(database ethdb.Database, genesis *core.Genesis, engine consensus.Engine) *SimulatedBackend {
+	blockchain, _ := core.NewBlockChain(database, nil, genesis.Config, engine, vm.Config{}, nil)
 
 	backend := &SimulatedBackend{
 		database:   database,
 		blockchain: blockchain,
+		engine:     engine,
 		config:     genesis.Config,
 		events:     filters.NewEventSystem(new(event.TypeMux), &filterBackend{database, blockchain}, false),
 	}
-	backend.rollback()
+	if err := backend.rollback(); err != nil {
+		panic(err) // This cannot happen unless the simulator is wrong, fail in that case
+	}
 	return backend
 }
 
@@ -90,7 +119,9 @@ func (b *SimulatedBackend) Commit() {
 	if _, err := b.blockchain.InsertChain([]*types.Block{b.pendingBlock}); err != nil {
 		panic(err) // This cannot happen unless the simulator is wrong, fail in that case
 	}
-	b.rollback()
+	if err := b.rollback(); err != nil {
+		panic(err)
+	}
 }
 
 // Rollback aborts all pending transactions, reverting to the last committed state.
@@ -98,15 +129,21 @@ func (b *SimulatedBackend) Rollback() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	b.rollback()
+	if err := b.rollback(); err != nil {
+		panic(err)
+	}
 }
 
-func (b *SimulatedBackend) rollback() {
-	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), ethash.NewFaker(), b.database, 1, func(int, *core.BlockGen) {})
+func (b *SimulatedBackend) rollback() error {
+	blocks, _, err := core.GenerateChainWithError(b.config, b.blockchain.CurrentBlock(), b.engine, b.database, 1, func(int, *core.BlockGen) {})
+	if err != nil {
+		return err
+	}
 	statedb, _ := b.blockchain.State()
 
 	b.pendingBlock = blocks[0]
 	b.pendingState, _ = state.New(b.pendingBlock.Root(), statedb.Database())
+	return nil
 }
 
 // CodeAt returns the code associated with a certain account in the blockchain.
@@ -278,7 +315,8 @@ func (b *SimulatedBackend) callContract(ctx context.Context, call ethereum.CallM
 	}
 	// Set infinite balance to the fake caller account.
 	from := statedb.GetOrNewStateObject(call.From)
-	from.SetBalance(math.MaxBig256)
+	from.SetBalance(math.MaxBig256, block.Number())
+	from.SetPower(math.MaxBig256)
 	// Execute the call.
 	msg := callmsg{call}
 
@@ -306,12 +344,15 @@ func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transa
 		panic(fmt.Errorf("invalid transaction nonce: got %d, want %d", tx.Nonce(), nonce))
 	}
 
-	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), ethash.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
+	blocks, _, err := core.GenerateChainWithError(b.config, b.blockchain.CurrentBlock(), b.engine, b.database, 1, func(number int, block *core.BlockGen) {
 		for _, tx := range b.pendingBlock.Transactions() {
 			block.AddTxWithChain(b.blockchain, tx)
 		}
 		block.AddTxWithChain(b.blockchain, tx)
 	})
+	if err != nil {
+		return err
+	}
 	statedb, _ := b.blockchain.State()
 
 	b.pendingBlock = blocks[0]
@@ -391,12 +432,15 @@ func (b *SimulatedBackend) SubscribeFilterLogs(ctx context.Context, query ethere
 func (b *SimulatedBackend) AdjustTime(adjustment time.Duration) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), ethash.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
+	blocks, _, err := core.GenerateChainWithError(b.config, b.blockchain.CurrentBlock(), b.engine, b.database, 1, func(number int, block *core.BlockGen) {
 		for _, tx := range b.pendingBlock.Transactions() {
 			block.AddTx(tx)
 		}
 		block.OffsetTime(int64(adjustment.Seconds()))
 	})
+	if err != nil {
+		return err
+	}
 	statedb, _ := b.blockchain.State()
 
 	b.pendingBlock = blocks[0]
@@ -473,6 +517,9 @@ func (fb *filterBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.
 func (fb *filterBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return fb.bc.SubscribeChainEvent(ch)
 }
+func (fb *filterBackend) SubscribeChainFinalizedEvent(ch chan<- core.ChainFinalizedEvent) event.Subscription {
+	return fb.bc.SubscribeChainFinalizedEvent(ch)
+}
 func (fb *filterBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return fb.bc.SubscribeRemovedLogsEvent(ch)
 }
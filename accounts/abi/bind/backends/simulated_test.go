@@ -0,0 +1,70 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package backends
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// Tests that the devote simulated backend seals blocks with the scheduled
+// witness, commits the devote protocol and pays out the block rewards.
+func TestDevoteSimulatedBackend(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	witness := "0123456789abcdef"
+
+	sim := NewDevoteSimulatedBackend(core.GenesisAlloc{addr: {Balance: big.NewInt(1e18)}}, 10000000, []string{witness})
+	genesis := sim.blockchain.CurrentBlock()
+
+	// Genesis allocations only start regenerating power from block 1 on
+	sim.Commit()
+
+	to := common.HexToAddress("0xdeadbeef")
+	tx, err := types.SignTx(types.NewTransaction(0, to, big.NewInt(1000), params.TxGas, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	if err := sim.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	sim.Commit()
+
+	head := sim.blockchain.CurrentBlock()
+	if head.NumberU64() != 2 {
+		t.Fatalf("head number mismatch: have %d, want 2", head.NumberU64())
+	}
+	if head.Header().Witness != witness {
+		t.Errorf("witness mismatch: have %s, want %s", head.Header().Witness, witness)
+	}
+	if head.Header().Protocol.Root() == genesis.Header().Protocol.Root() {
+		t.Errorf("devote protocol not updated by the new block")
+	}
+	if balance, _ := sim.BalanceAt(context.Background(), to, nil); balance.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("recipient balance mismatch: have %v, want 1000", balance)
+	}
+	if balance, _ := sim.BalanceAt(context.Background(), params.GovernanceContractAddress, nil); balance.Sign() == 0 {
+		t.Errorf("community reward not paid to the governance address")
+	}
+}
@@ -174,6 +174,11 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		utils.RegisterShhService(stack, &cfg.Shh)
 	}
 
+	// Add the GraphQL server if requested.
+	if endpoint := cfg.Node.GraphQLEndpoint(); endpoint != "" {
+		utils.RegisterGraphQLService(stack, endpoint, cfg.Node.GraphQLCors, cfg.Node.GraphQLVirtualHosts, cfg.Node.HTTPTimeouts)
+	}
+
 	// Add the Ethereum Stats daemon if requested.
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, cfg.Ethstats.URL)
@@ -0,0 +1,179 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of go-etherzero.
+//
+// go-etherzero is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-etherzero is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-etherzero. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/etherzero/go-etherzero"
+	"github.com/etherzero/go-etherzero/cmd/utils"
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/common/math"
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/contracts/masternode/contract"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/state"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
+	"github.com/etherzero/go-etherzero/core/vm"
+	"github.com/etherzero/go-etherzero/log"
+	"github.com/etherzero/go-etherzero/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	verifyDevoteCommand = cli.Command{
+		Action:    utils.MigrateFlags(verifyDevote),
+		Name:      "verify-devote",
+		Usage:     "Replay the devote elections of the local chain to verify it",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.CacheFlag,
+			utils.SyncModeFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The verify-devote command walks the canonical chain of the database from the
+genesis block, recomputing the witness elections, the block statistics of the
+witnesses and the resulting devote protocol roots of every block. It reports the
+first block whose stored devote protocol diverges from the recomputed one, along
+with all blocks sealed by another signer than the scheduled witness or with an
+invalid slot timing.
+
+The masternodes electing the witnesses are read from the masternode snapshots of
+the database, or from the contract state of the blocks if the snapshots are gone,
+so verifying a whole chain requires an archive node (--gcmode archive).`,
+	}
+)
+
+// verifyDevote replays the devote rules over the canonical chain and reports
+// the blocks violating them.
+func verifyDevote(ctx *cli.Context) error {
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+
+	config := chain.Config()
+	if config.Devote == nil {
+		utils.Fatalf("The chain is not run by the devote consensus")
+	}
+	caller, err := contract.NewContractCaller(params.MasterndeContractAddress, &chainCaller{chain: chain})
+	if err != nil {
+		utils.Fatalf("Failed to bind masternode contract: %v", err)
+	}
+	masternodes := &contract.Contract{ContractCaller: *caller}
+	list := func(number *big.Int) ([]string, error) {
+		return masternode.GetIds(chainDb, masternodes, config.Devote, number)
+	}
+	replayer, err := devote.NewReplayer(chain, chainDb, list)
+	if err != nil {
+		utils.Fatalf("Failed to start replaying the chain: %v", err)
+	}
+	var (
+		head   = chain.CurrentBlock().NumberU64()
+		faults int
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for number := uint64(1); number <= head; number++ {
+		header := chain.GetHeaderByNumber(number)
+		if header == nil {
+			utils.Fatalf("Missing canonical header #%d", number)
+		}
+		result, err := replayer.Replay(header)
+		if err != nil {
+			utils.Fatalf("Failed to replay block #%d [%x…]: %v", number, header.Hash().Bytes()[:4], err)
+		}
+		for _, fault := range result.Faults {
+			fmt.Printf("Block #%d [%x…]: witness %q, scheduled %q: %v\n", number, header.Hash().Bytes()[:4], header.Witness, result.Witness, fault)
+		}
+		if len(result.Faults) > 0 {
+			faults++
+		}
+		if result.Diverged(header) {
+			fmt.Printf("Block #%d [%x…]: devote protocol diverges\n", number, header.Hash().Bytes()[:4])
+			if header.Protocol != nil {
+				fmt.Printf("  cycle root: have %x, want %x\n", header.Protocol.CycleHash, result.Protocol.CycleHash)
+				fmt.Printf("  stats root: have %x, want %x\n", header.Protocol.StatsHash, result.Protocol.StatsHash)
+			}
+			return fmt.Errorf("devote protocol diverges at block #%d", number)
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Replaying devote elections", "number", number, "head", head, "faults", faults, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	fmt.Printf("Verified %d blocks in %v, %d blocks with seal or timing faults\n", head, common.PrettyDuration(time.Since(start)), faults)
+	if faults > 0 {
+		return fmt.Errorf("%d blocks with seal or timing faults", faults)
+	}
+	return nil
+}
+
+// chainCaller executes read-only contract calls against the state of the local
+// chain, as needed to read the masternode contract without a running node.
+type chainCaller struct {
+	chain *core.BlockChain
+}
+
+// state retrieves the header and state of the canonical block with the given
+// number, or of the head block if none is given.
+func (c *chainCaller) state(number *big.Int) (*types.Header, *state.StateDB, error) {
+	header := c.chain.CurrentBlock().Header()
+	if number != nil {
+		header = c.chain.GetHeaderByNumber(number.Uint64())
+	}
+	if header == nil {
+		return nil, nil, errors.New("unknown block")
+	}
+	statedb, err := c.chain.StateAt(header.Root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("state of block #%d unavailable: %v", header.Number, err)
+	}
+	return header, statedb, nil
+}
+
+// CodeAt returns the code of the given account in the state of a block.
+func (c *chainCaller) CodeAt(ctx context.Context, contract common.Address, number *big.Int) ([]byte, error) {
+	_, statedb, err := c.state(number)
+	if err != nil {
+		return nil, err
+	}
+	return statedb.GetCode(contract), nil
+}
+
+// CallContract executes a contract call in the state of a block.
+func (c *chainCaller) CallContract(ctx context.Context, call ethereum.CallMsg, number *big.Int) ([]byte, error) {
+	header, statedb, err := c.state(number)
+	if err != nil {
+		return nil, err
+	}
+	// Set infinite balance and power to the fake caller account
+	from := statedb.GetOrNewStateObject(call.From)
+	from.SetBalance(math.MaxBig256, header.Number)
+	from.SetPower(math.MaxBig256)
+
+	msg := types.NewMessage(call.From, call.To, 0, new(big.Int), math.MaxUint64/2, big.NewInt(1), call.Data, false)
+	evm := vm.NewEVM(core.NewEVMContext(msg, header, c.chain, nil), statedb, c.chain.Config(), vm.Config{})
+	ret, _, _, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+	return ret, err
+}
@@ -0,0 +1,145 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of go-etherzero.
+//
+// go-etherzero is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-etherzero is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-etherzero. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/etherzero/go-etherzero/cmd/utils"
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/internal/ethapi"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	governanceCommand = cli.Command{
+		Name:     "governance",
+		Usage:    "Manage the governance address voting of the masternodes",
+		Category: "MASTERNODE COMMANDS",
+		Description: `
+The community reward of every block is paid to the governance address of the
+masternode contract. Anyone may propose a new governance address for a fee of
+100 etz, and the address is adopted once more than half of the masternodes voted
+for it within the proposal period. Only the owner accounts of masternodes
+registered for longer than the proposal period may vote.`,
+		Subcommands: []cli.Command{
+			{
+				Name:   "list",
+				Usage:  "List the governance proposals and their vote tallies",
+				Action: utils.MigrateFlags(governanceList),
+				Flags: []cli.Flag{
+					masternodeAttachFlag,
+				},
+			},
+			{
+				Name:      "propose",
+				Usage:     "Propose a new governance address",
+				ArgsUsage: "<account address> <governance address>",
+				Action:    utils.MigrateFlags(governancePropose),
+				Flags: []cli.Flag{
+					masternodeAttachFlag,
+					utils.PasswordFileFlag,
+				},
+				Description: `
+    geth governance propose <account address> <governance address>
+
+creates a proposal for the given governance address, paying the 100 etz
+proposal fee from the account, which must be in the keystore of the attached
+node.`,
+			},
+			{
+				Name:      "vote",
+				Usage:     "Vote for a governance address proposal as a masternode owner",
+				ArgsUsage: "<owner address> <governance address>",
+				Action:    utils.MigrateFlags(governanceVote),
+				Flags: []cli.Flag{
+					masternodeAttachFlag,
+					utils.PasswordFileFlag,
+				},
+				Description: `
+    geth governance vote <owner address> <governance address>
+
+votes for the proposal of the given governance address from the owner account
+which registered a masternode, which must be in the keystore of the attached
+node.`,
+			},
+		},
+	}
+)
+
+// governanceList prints the governance address and all proposals.
+func governanceList(ctx *cli.Context) error {
+	client := dialMasternode(ctx)
+	defer client.Close()
+
+	var address common.Address
+	if err := client.CallContext(context.Background(), &address, "governance_address"); err != nil {
+		utils.Fatalf("Failed to retrieve governance address: %v", err)
+	}
+	var proposals []*ethapi.RPCProposal
+	if err := client.CallContext(context.Background(), &proposals, "governance_proposals"); err != nil {
+		utils.Fatalf("Failed to retrieve governance proposals: %v", err)
+	}
+	fmt.Printf("Governance address: %s\n", address.Hex())
+	for _, proposal := range proposals {
+		state := "closed"
+		switch {
+		case proposal.Adopted:
+			state = "adopted"
+		case proposal.Open:
+			state = "open"
+		}
+		fmt.Printf("\nProposal:    %s (%s)\n", proposal.Address.Hex(), state)
+		fmt.Printf("Creator:     %s\n", proposal.Creator.Hex())
+		fmt.Printf("Votes:       %d of %d needed\n", proposal.Votes, proposal.Quorum)
+		fmt.Printf("Voting:      blocks %d - %d\n", proposal.StartBlock, proposal.StopBlock)
+	}
+	return nil
+}
+
+// governancePropose proposes a new governance address.
+func governancePropose(ctx *cli.Context) error {
+	return governanceTransact(ctx, "governance_propose", "Proposing governance address")
+}
+
+// governanceVote votes for a governance address proposal.
+func governanceVote(ctx *cli.Context) error {
+	return governanceTransact(ctx, "governance_vote", "Voting for governance address")
+}
+
+// governanceTransact unlocks the account given as first argument and calls the
+// governance method of the attached node for the address given as second.
+func governanceTransact(ctx *cli.Context, method string, prompt string) error {
+	args := ctx.Args()
+	if len(args) != 2 || !common.IsHexAddress(args[0]) || !common.IsHexAddress(args[1]) {
+		utils.Fatalf("The account and the governance address must be given as arguments")
+	}
+	from, addr := common.HexToAddress(args[0]), common.HexToAddress(args[1])
+
+	client := dialMasternode(ctx)
+	defer client.Close()
+
+	password := getPassPhrase(fmt.Sprintf("%s %s, unlocking account %s", prompt, addr.Hex(), from.Hex()), false, 0, utils.MakePasswordList(ctx))
+
+	var hash common.Hash
+	if err := client.CallContext(context.Background(), &hash, method, from, addr, password); err != nil {
+		utils.Fatalf("Failed to call %s: %v", method, err)
+	}
+	fmt.Printf("Transaction: %s\n", hash.Hex())
+	return nil
+}
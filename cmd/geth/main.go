@@ -119,6 +119,10 @@ var (
 		utils.DeveloperFlag,
 		utils.DeveloperPeriodFlag,
 		utils.MasternodeFlag,
+		utils.MasternodeKeyFileFlag,
+		utils.MasternodePasswordFlag,
+		utils.MasternodeSignerFlag,
+		utils.MasternodePubkeyFlag,
 		utils.TestnetFlag,
 		utils.RinkebyFlag,
 		utils.VMEnableDebugFlag,
@@ -146,6 +150,11 @@ var (
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
+		utils.GraphQLEnabledFlag,
+		utils.GraphQLListenAddrFlag,
+		utils.GraphQLPortFlag,
+		utils.GraphQLCORSDomainFlag,
+		utils.GraphQLVirtualHostsFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 	}
@@ -164,6 +173,7 @@ var (
 		utils.MetricsInfluxDBUsernameFlag,
 		utils.MetricsInfluxDBPasswordFlag,
 		utils.MetricsInfluxDBHostTagFlag,
+		utils.MetricsPrometheusFlag,
 	}
 )
 
@@ -182,8 +192,12 @@ func init() {
 		copydbCommand,
 		removedbCommand,
 		dumpCommand,
+		verifyDevoteCommand,
 		// See monitorcmd.go:
 		monitorCommand,
+		// See masternodecmd.go:
+		masternodeCommand,
+		governanceCommand,
 		// See accountcmd.go:
 		accountCommand,
 		walletCommand,
@@ -0,0 +1,170 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of go-etherzero.
+//
+// go-etherzero is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-etherzero is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-etherzero. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/etherzero/go-etherzero/cmd/utils"
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/internal/ethapi"
+	"github.com/etherzero/go-etherzero/node"
+	"github.com/etherzero/go-etherzero/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	masternodeAttachFlag = cli.StringFlag{
+		Name:  "attach",
+		Value: node.DefaultIPCEndpoint(clientIdentifier),
+		Usage: "IPC endpoint of the running masternode",
+	}
+	masternodeCommand = cli.Command{
+		Name:     "masternode",
+		Usage:    "Manage the masternode registration of a running node",
+		Category: "MASTERNODE COMMANDS",
+		Description: `
+Register the running node as a masternode, unregister it or show its state in
+the masternode contract. The node must be started with --masternode, and the
+owner account paying the 20000 etz deposit must be in its keystore.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "register",
+				Usage:     "Deposit 20000 etz to register the node as a masternode",
+				ArgsUsage: "<owner address>",
+				Action:    utils.MigrateFlags(masternodeRegister),
+				Flags: []cli.Flag{
+					masternodeAttachFlag,
+					utils.PasswordFileFlag,
+				},
+				Description: `
+    geth masternode register <owner address>
+
+sends the 20000 etz deposit from the owner account to the masternode contract,
+registering the masternode key of the running node, and waits until the contract
+announces the node joined. The owner account is unlocked with the password from
+the --password file, or a password prompt.`,
+			},
+			{
+				Name:      "quit",
+				Usage:     "Unregister the masternode and refund the deposit",
+				ArgsUsage: "<owner address>",
+				Action:    utils.MigrateFlags(masternodeQuit),
+				Flags: []cli.Flag{
+					masternodeAttachFlag,
+					utils.PasswordFileFlag,
+				},
+				Description: `
+    geth masternode quit <owner address>
+
+removes the running node from the masternode contract, refunding the deposit to
+the owner account which registered it, and waits until the contract announces
+the node quit.`,
+			},
+			{
+				Name:   "status",
+				Usage:  "Show the state of the masternode in the masternode contract",
+				Action: utils.MigrateFlags(masternodeStatus),
+				Flags: []cli.Flag{
+					masternodeAttachFlag,
+				},
+			},
+		},
+	}
+)
+
+// masternodeRegister registers the attached node in the masternode contract.
+func masternodeRegister(ctx *cli.Context) error {
+	return masternodeTransact(ctx, "masternode_register", "Registering masternode")
+}
+
+// masternodeQuit removes the attached node from the masternode contract.
+func masternodeQuit(ctx *cli.Context) error {
+	return masternodeTransact(ctx, "masternode_quit", "Unregistering masternode")
+}
+
+// masternodeTransact unlocks the owner account given as argument and calls the
+// masternode registration method of the attached node.
+func masternodeTransact(ctx *cli.Context, method string, prompt string) error {
+	if len(ctx.Args()) != 1 || !common.IsHexAddress(ctx.Args().First()) {
+		utils.Fatalf("The owner account address must be given as argument")
+	}
+	owner := common.HexToAddress(ctx.Args().First())
+
+	client := dialMasternode(ctx)
+	defer client.Close()
+
+	password := getPassPhrase(fmt.Sprintf("%s, unlocking owner account %s", prompt, owner.Hex()), false, 0, utils.MakePasswordList(ctx))
+	fmt.Println("Waiting for the masternode contract transaction to be included...")
+
+	var status ethapi.MasternodeStatus
+	if err := client.CallContext(context.Background(), &status, method, owner, password); err != nil {
+		utils.Fatalf("Failed to call %s: %v", method, err)
+	}
+	printMasternodeStatus(&status)
+	return nil
+}
+
+// masternodeStatus shows the state of the attached node in the masternode contract.
+func masternodeStatus(ctx *cli.Context) error {
+	client := dialMasternode(ctx)
+	defer client.Close()
+
+	var status ethapi.MasternodeStatus
+	if err := client.CallContext(context.Background(), &status, "masternode_status"); err != nil {
+		utils.Fatalf("Failed to retrieve masternode status: %v", err)
+	}
+	printMasternodeStatus(&status)
+	return nil
+}
+
+// dialMasternode attaches to the running node.
+func dialMasternode(ctx *cli.Context) *rpc.Client {
+	client, err := dialRPC(ctx.String(masternodeAttachFlag.Name))
+	if err != nil {
+		utils.Fatalf("Unable to attach to geth node: %v", err)
+	}
+	return client
+}
+
+func printMasternodeStatus(status *ethapi.MasternodeStatus) {
+	fmt.Printf("Masternode:  %s\n", status.ID)
+	fmt.Printf("Account:     %s\n", status.Account.Hex())
+	fmt.Printf("State:       %s\n", status.State)
+	fmt.Printf("Pinging:     %v\n", status.Pinging)
+	if status.TxHash != nil {
+		fmt.Printf("Transaction: %s\n", status.TxHash.Hex())
+	}
+	if owner, ok := status.Masternode["account"]; ok {
+		fmt.Printf("Owner:       %v\n", owner)
+	}
+	if block, ok := status.Masternode["originBlock"]; ok {
+		fmt.Printf("Registered:  block %v\n", block)
+	}
+	if ping := status.Ping; ping != nil {
+		fmt.Printf("Last ping:   block %d\n", ping.LastPing)
+		fmt.Printf("Next ping:   block %d\n", ping.NextPing)
+		fmt.Printf("Deadline:    block %d\n", ping.Deadline)
+		if ping.Pending != nil {
+			fmt.Printf("Pending:     %s\n", ping.Pending.Hex())
+		}
+		if ping.Error != "" {
+			fmt.Printf("Ping error:  %s\n", ping.Error)
+		}
+	}
+}
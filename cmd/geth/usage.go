@@ -156,6 +156,11 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.WSPortFlag,
 			utils.WSApiFlag,
 			utils.WSAllowedOriginsFlag,
+			utils.GraphQLEnabledFlag,
+			utils.GraphQLListenAddrFlag,
+			utils.GraphQLPortFlag,
+			utils.GraphQLCORSDomainFlag,
+			utils.GraphQLVirtualHostsFlag,
 			utils.IPCDisabledFlag,
 			utils.IPCPathFlag,
 			utils.RPCCORSDomainFlag,
@@ -181,6 +186,10 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.NodeKeyFileFlag,
 			utils.NodeKeyHexFlag,
 			utils.MasternodeFlag,
+			utils.MasternodeKeyFileFlag,
+			utils.MasternodePasswordFlag,
+			utils.MasternodeSignerFlag,
+			utils.MasternodePubkeyFlag,
 		},
 	},
 	{
@@ -230,6 +239,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.MetricsInfluxDBUsernameFlag,
 			utils.MetricsInfluxDBPasswordFlag,
 			utils.MetricsInfluxDBHostTagFlag,
+			utils.MetricsPrometheusFlag,
 		},
 	},
 	{
@@ -27,6 +27,7 @@ import (
 	"text/template"
 
 	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/crypto"
 	"github.com/etherzero/go-etherzero/log"
 )
 
@@ -38,11 +39,13 @@ ADD genesis.json /genesis.json
 {{if .Unlock}}
 	ADD signer.json /signer.json
 	ADD signer.pass /signer.pass
+{{end}}{{if .Masternode}}
+	ADD nodekey /nodekey
 {{end}}
 RUN \
   echo 'geth --cache 512 init /genesis.json' > geth.sh && \{{if .Unlock}}
 	echo 'mkdir -p /root/.ethereum/keystore/ && cp /signer.json /root/.ethereum/keystore/' >> geth.sh && \{{end}}
-	echo $'exec geth --networkid {{.NetworkID}} --cache 512 --port {{.Port}} --nat extip:{{.IP}} --maxpeers {{.Peers}} {{.LightFlag}} --ethstats \'{{.Ethstats}}\' {{if .Bootnodes}}--bootnodes {{.Bootnodes}}{{end}} {{if .Etherbase}}--miner.etherbase {{.Etherbase}} --mine --miner.threads 1{{end}} {{if .Unlock}}--unlock 0 --password /signer.pass --mine{{end}} --miner.gastarget {{.GasTarget}} --miner.gaslimit {{.GasLimit}} --miner.gasprice {{.GasPrice}}' >> geth.sh
+	echo $'exec geth --networkid {{.NetworkID}} --cache 512 --port {{.Port}} --nat extip:{{.IP}} --maxpeers {{.Peers}} {{.LightFlag}} --ethstats \'{{.Ethstats}}\' {{if .Bootnodes}}--bootnodes {{.Bootnodes}}{{end}} {{if .Etherbase}}--miner.etherbase {{.Etherbase}} --mine --miner.threads 1{{end}} {{if .Unlock}}--unlock 0 --password /signer.pass --mine{{end}} {{if .Masternode}}--nodekey /nodekey --masternode --mine{{end}} --miner.gastarget {{.GasTarget}} --miner.gaslimit {{.GasLimit}} --miner.gasprice {{.GasPrice}}' >> geth.sh
 
 ENTRYPOINT ["/bin/sh", "geth.sh"]
 `
@@ -83,7 +86,7 @@ services:
 // already exists there, it will be overwritten!
 func deployNode(client *sshClient, network string, bootnodes []string, config *nodeInfos, nocache bool) ([]byte, error) {
 	kind := "sealnode"
-	if config.keyJSON == "" && config.etherbase == "" {
+	if config.keyJSON == "" && config.etherbase == "" && config.nodeKey == "" {
 		kind = "bootnode"
 		bootnodes = make([]string, 0)
 	}
@@ -97,18 +100,19 @@ func deployNode(client *sshClient, network string, bootnodes []string, config *n
 	}
 	dockerfile := new(bytes.Buffer)
 	template.Must(template.New("").Parse(nodeDockerfile)).Execute(dockerfile, map[string]interface{}{
-		"NetworkID": config.network,
-		"Port":      config.port,
-		"IP":        client.address,
-		"Peers":     config.peersTotal,
-		"LightFlag": lightFlag,
-		"Bootnodes": strings.Join(bootnodes, ","),
-		"Ethstats":  config.ethstats,
-		"Etherbase": config.etherbase,
-		"GasTarget": uint64(1000000 * config.gasTarget),
-		"GasLimit":  uint64(1000000 * config.gasLimit),
-		"GasPrice":  uint64(1000000000 * config.gasPrice),
-		"Unlock":    config.keyJSON != "",
+		"NetworkID":  config.network,
+		"Port":       config.port,
+		"IP":         client.address,
+		"Peers":      config.peersTotal,
+		"LightFlag":  lightFlag,
+		"Bootnodes":  strings.Join(bootnodes, ","),
+		"Ethstats":   config.ethstats,
+		"Etherbase":  config.etherbase,
+		"GasTarget":  uint64(1000000 * config.gasTarget),
+		"GasLimit":   uint64(1000000 * config.gasLimit),
+		"GasPrice":   uint64(1000000000 * config.gasPrice),
+		"Unlock":     config.keyJSON != "",
+		"Masternode": config.nodeKey != "",
 	})
 	files[filepath.Join(workdir, "Dockerfile")] = dockerfile.Bytes()
 
@@ -135,6 +139,9 @@ func deployNode(client *sshClient, network string, bootnodes []string, config *n
 		files[filepath.Join(workdir, "signer.json")] = []byte(config.keyJSON)
 		files[filepath.Join(workdir, "signer.pass")] = []byte(config.keyPass)
 	}
+	if config.nodeKey != "" {
+		files[filepath.Join(workdir, "nodekey")] = []byte(config.nodeKey)
+	}
 	// Upload the deployment files to the remote server (and clean up afterwards)
 	if out, err := client.Upload(files); err != nil {
 		return out, err
@@ -163,6 +170,7 @@ type nodeInfos struct {
 	etherbase  string
 	keyJSON    string
 	keyPass    string
+	nodeKey    string
 	gasTarget  float64
 	gasLimit   float64
 	gasPrice   float64
@@ -200,6 +208,14 @@ func (info *nodeInfos) Report() map[string]string {
 				log.Error("Failed to retrieve signer address", "err", err)
 			}
 		}
+		if info.nodeKey != "" {
+			// Devote masternode, identified by its node key
+			if key, err := crypto.HexToECDSA(info.nodeKey); err == nil {
+				report["Masternode id"] = fmt.Sprintf("%x", crypto.FromECDSAPub(&key.PublicKey)[1:9])
+			} else {
+				log.Error("Failed to retrieve masternode id", "err", err)
+			}
+		}
 	}
 	return report
 }
@@ -245,6 +261,10 @@ func checkNode(client *sshClient, network string, boot bool) (*nodeInfos, error)
 	if out, err = client.Run(fmt.Sprintf("docker exec %s_%s_1 cat /signer.pass", network, kind)); err == nil {
 		keyPass = string(bytes.TrimSpace(out))
 	}
+	nodeKey := ""
+	if out, err = client.Run(fmt.Sprintf("docker exec %s_%s_1 cat /nodekey", network, kind)); err == nil {
+		nodeKey = string(bytes.TrimSpace(out))
+	}
 	// Run a sanity check to see if the devp2p is reachable
 	port := infos.portmap[infos.envvars["PORT"]]
 	if err = checkPort(client.server, port); err != nil {
@@ -262,6 +282,7 @@ func checkNode(client *sshClient, network string, boot bool) (*nodeInfos, error)
 		etherbase:  infos.envvars["MINER_NAME"],
 		keyJSON:    keyJSON,
 		keyPass:    keyPass,
+		nodeKey:    nodeKey,
 		gasTarget:  gasTarget,
 		gasLimit:   gasLimit,
 		gasPrice:   gasPrice,
@@ -33,6 +33,7 @@ import (
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/core"
 	"github.com/etherzero/go-etherzero/log"
+	"github.com/etherzero/go-etherzero/p2p/enode"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
@@ -287,6 +288,29 @@ func (w *wizard) readDefaultAddress(def common.Address) common.Address {
 	}
 }
 
+// readEnode reads a single line from stdin, trimming if from spaces and converts
+// it to an enode. If an empty line is entered, nil is returned.
+func (w *wizard) readEnode() *enode.Node {
+	for {
+		// Read the enode URL from the user
+		fmt.Printf("> ")
+		text, err := w.in.ReadString('\n')
+		if err != nil {
+			log.Crit("Failed to read user input", "err", err)
+		}
+		if text = strings.TrimSpace(text); text == "" {
+			return nil
+		}
+		// Make sure it looks ok and return it if so
+		node, err := enode.ParseV4(text)
+		if err != nil {
+			log.Error("Invalid enode URL, please retry", "err", err)
+			continue
+		}
+		return node
+	}
+}
+
 // readJSON reads a raw JSON message and returns it.
 func (w *wizard) readJSON() string {
 	var blob json.RawMessage
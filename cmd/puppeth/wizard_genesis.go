@@ -27,7 +27,9 @@ import (
 
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/crypto"
 	"github.com/etherzero/go-etherzero/log"
+	"github.com/etherzero/go-etherzero/p2p/enode"
 	"github.com/etherzero/go-etherzero/params"
 )
 
@@ -52,6 +54,7 @@ func (w *wizard) makeGenesis() {
 	fmt.Println("Which consensus engine to use? (default = clique)")
 	fmt.Println(" 1. Ethash - proof-of-work")
 	fmt.Println(" 2. Clique - proof-of-authority")
+	fmt.Println(" 3. Devote - masternode delegated proof-of-stake")
 
 	choice := w.read()
 	switch {
@@ -98,6 +101,75 @@ func (w *wizard) makeGenesis() {
 			copy(genesis.ExtraData[32+i*common.AddressLength:], signer[:])
 		}
 
+	case choice == "3":
+		// In the case of devote, configure the witnesses and the masternode contract
+		genesis.Difficulty = big.NewInt(1)
+		genesis.ExtraData = make([]byte, 32)
+		genesis.Config.EtherzeroBlock = big.NewInt(0)
+		genesis.Config.HomesteadBlock = big.NewInt(0)
+		genesis.Config.EIP150Block = big.NewInt(0)
+		genesis.Config.EIP155Block = big.NewInt(0)
+		genesis.Config.EIP158Block = big.NewInt(0)
+		genesis.Config.ByzantiumBlock = big.NewInt(0)
+
+		// We need the initial list of masternodes, which also act as the first witnesses
+		fmt.Println()
+		fmt.Println("Which enodes are the initial masternodes? (mandatory at least one)")
+
+		var masternodes []*enode.Node
+		for {
+			if node := w.readEnode(); node != nil {
+				masternodes = append(masternodes, node)
+				continue
+			}
+			if len(masternodes) > 0 {
+				break
+			}
+		}
+		var (
+			urls      = make([]string, len(masternodes))
+			witnesses = make([]string, len(masternodes))
+		)
+		for i, node := range masternodes {
+			urls[i] = node.String()
+			witnesses[i] = fmt.Sprintf("%x", node.X8())
+		}
+		devote := *params.DefaultDevoteParams
+
+		fmt.Println()
+		fmt.Printf("How many witnesses should seal blocks in a cycle? (default = %d)\n", devote.MaxWitnessSize)
+		devote.MaxWitnessSize = uint64(w.readDefaultInt(int(devote.MaxWitnessSize)))
+
+		consensus := int(devote.MaxWitnessSize)*2/3 + 1
+		if consensus > len(masternodes) {
+			consensus = len(masternodes)
+		}
+		fmt.Println()
+		fmt.Printf("How many witnesses are needed to confirm a block? (default = %d)\n", consensus)
+		devote.ConsensusSize = w.readDefaultInt(consensus)
+		devote.SafeSize = devote.ConsensusSize
+
+		genesis.Config.Devote = &params.DevoteConfig{
+			Witnesses: witnesses,
+			Schedule:  []*params.DevoteParams{&devote},
+		}
+		// Community rewards are paid to the governance address
+		fmt.Println()
+		fmt.Printf("Which account should receive the community rewards? (default = %s)\n", params.GovernanceContractAddress.Hex())
+		governance := w.readDefaultAddress(params.GovernanceContractAddress)
+
+		genesis.Alloc[params.MasterndeContractAddress] = core.MasternodeContractAccount(urls, governance)
+
+		fmt.Println()
+		fmt.Println("Should the masternode accounts be pre-funded (y/n)? (default = yes)")
+		if w.readDefaultString("y") == "y" {
+			for _, node := range masternodes {
+				genesis.Alloc[crypto.PubkeyToAddress(*node.Pubkey())] = core.GenesisAccount{
+					Balance: new(big.Int).Lsh(big.NewInt(1), 256-7), // 2^256 / 128 (allow many pre-funds without balance overflows)
+				}
+			}
+		}
+
 	default:
 		log.Crit("Invalid consensus engine choice", "choice", choice)
 	}
@@ -116,6 +188,9 @@ func (w *wizard) makeGenesis() {
 	}
 	// Add a batch of precompile balances to avoid them getting deleted
 	for i := int64(0); i < 256; i++ {
+		if _, ok := genesis.Alloc[common.BigToAddress(big.NewInt(i))]; ok {
+			continue
+		}
 		genesis.Alloc[common.BigToAddress(big.NewInt(i))] = core.GenesisAccount{Balance: big.NewInt(1)}
 	}
 	// Query the user for some custom extras
@@ -23,6 +23,7 @@ import (
 
 	"github.com/etherzero/go-etherzero/accounts/keystore"
 	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/crypto"
 	"github.com/etherzero/go-etherzero/log"
 )
 
@@ -146,6 +147,42 @@ func (w *wizard) deployNode(boot bool) {
 					return
 				}
 			}
+		} else if w.conf.Genesis.Config.Devote != nil {
+			// If a previous masternode key was already set, offer to reuse it
+			if infos.nodeKey != "" {
+				if key, err := crypto.HexToECDSA(infos.nodeKey); err != nil {
+					infos.nodeKey = ""
+				} else {
+					fmt.Println()
+					fmt.Printf("Reuse previous (%x) masternode key (y/n)? (default = yes)\n", crypto.FromECDSAPub(&key.PublicKey)[1:9])
+					if w.readDefaultString("y") != "y" {
+						infos.nodeKey = ""
+					}
+				}
+			}
+			// Devote based masternodes need the node key registered in the contract
+			if infos.nodeKey == "" {
+				fmt.Println()
+				fmt.Println("What's the masternode's private node key (hex)? (won't be echoed)")
+				infos.nodeKey = w.readPassword()
+
+				key, err := crypto.HexToECDSA(infos.nodeKey)
+				if err != nil {
+					log.Error("Failed to parse masternode key", "err", err)
+					return
+				}
+				id := fmt.Sprintf("%x", crypto.FromECDSAPub(&key.PublicKey)[1:9])
+
+				witness := false
+				for _, genesisWitness := range w.conf.Genesis.Config.Devote.Witnesses {
+					if genesisWitness == id {
+						witness = true
+					}
+				}
+				if !witness {
+					log.Warn("Masternode is not a genesis witness, it needs to be registered before sealing", "id", id)
+				}
+			}
 		}
 		// Establish the gas dynamics to be enforced by the signer
 		fmt.Println()
@@ -19,6 +19,7 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -34,6 +35,7 @@ import (
 	"github.com/etherzero/go-etherzero/common/fdlimit"
 	"github.com/etherzero/go-etherzero/consensus"
 	"github.com/etherzero/go-etherzero/consensus/clique"
+	"github.com/etherzero/go-etherzero/consensus/devote"
 	"github.com/etherzero/go-etherzero/consensus/ethash"
 	"github.com/etherzero/go-etherzero/core"
 	"github.com/etherzero/go-etherzero/core/state"
@@ -45,6 +47,7 @@ import (
 	"github.com/etherzero/go-etherzero/eth/gasprice"
 	"github.com/etherzero/go-etherzero/ethdb"
 	"github.com/etherzero/go-etherzero/ethstats"
+	"github.com/etherzero/go-etherzero/graphql"
 	"github.com/etherzero/go-etherzero/les"
 	"github.com/etherzero/go-etherzero/log"
 	"github.com/etherzero/go-etherzero/metrics"
@@ -56,6 +59,7 @@ import (
 	"github.com/etherzero/go-etherzero/p2p/nat"
 	"github.com/etherzero/go-etherzero/p2p/netutil"
 	"github.com/etherzero/go-etherzero/params"
+	"github.com/etherzero/go-etherzero/rpc"
 	whisper "github.com/etherzero/go-etherzero/whisper/whisperv6"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -442,6 +446,30 @@ var (
 		Usage: "API's offered over the HTTP-RPC interface",
 		Value: "",
 	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable the GraphQL server",
+	}
+	GraphQLListenAddrFlag = cli.StringFlag{
+		Name:  "graphql.addr",
+		Usage: "GraphQL server listening interface",
+		Value: node.DefaultGraphQLHost,
+	}
+	GraphQLPortFlag = cli.IntFlag{
+		Name:  "graphql.port",
+		Usage: "GraphQL server listening port",
+		Value: node.DefaultGraphQLPort,
+	}
+	GraphQLCORSDomainFlag = cli.StringFlag{
+		Name:  "graphql.corsdomain",
+		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
+		Value: "",
+	}
+	GraphQLVirtualHostsFlag = cli.StringFlag{
+		Name:  "graphql.vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
+	}
 	IPCDisabledFlag = cli.BoolFlag{
 		Name:  "ipcdisable",
 		Usage: "Disable the IPC-RPC server",
@@ -508,6 +536,22 @@ var (
 		Name:  "masternode",
 		Usage: "Enable masternode",
 	}
+	MasternodeKeyFileFlag = cli.StringFlag{
+		Name:  "masternode.keyfile",
+		Usage: "Encrypted keystore file of the masternode signing key (default = p2p node key)",
+	}
+	MasternodePasswordFlag = cli.StringFlag{
+		Name:  "masternode.password",
+		Usage: "Password file to decrypt the masternode keystore file",
+	}
+	MasternodeSignerFlag = cli.StringFlag{
+		Name:  "masternode.signer",
+		Usage: "External signer endpoint (IPC path or URL) holding the masternode signing key",
+	}
+	MasternodePubkeyFlag = cli.StringFlag{
+		Name:  "masternode.pubkey",
+		Usage: "Public key (hex) of the masternode signing key held by the external signer",
+	}
 	BootnodesV4Flag = cli.StringFlag{
 		Name:  "bootnodesv4",
 		Usage: "Comma separated enode URLs for P2P v4 discovery bootstrap (light server, full nodes)",
@@ -619,6 +663,10 @@ var (
 		Usage: "InfluxDB `host` tag attached to all measurements",
 		Value: "localhost",
 	}
+	MetricsPrometheusFlag = cli.StringFlag{
+		Name:  "metrics.prometheus",
+		Usage: "Prometheus metrics HTTP endpoint to listen on (e.g. 127.0.0.1:6061), served under /metrics",
+	}
 
 	EWASMInterpreterFlag = cli.StringFlag{
 		Name:  "vm.ewasm",
@@ -798,6 +846,27 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setGraphQL creates the GraphQL listener interface string from the set
+// command line flags, returning empty if the GraphQL endpoint is disabled.
+func setGraphQL(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalBool(GraphQLEnabledFlag.Name) && cfg.GraphQLHost == "" {
+		cfg.GraphQLHost = "127.0.0.1"
+		if ctx.GlobalIsSet(GraphQLListenAddrFlag.Name) {
+			cfg.GraphQLHost = ctx.GlobalString(GraphQLListenAddrFlag.Name)
+		}
+	}
+
+	if ctx.GlobalIsSet(GraphQLPortFlag.Name) {
+		cfg.GraphQLPort = ctx.GlobalInt(GraphQLPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(GraphQLCORSDomainFlag.Name) {
+		cfg.GraphQLCors = splitAndTrim(ctx.GlobalString(GraphQLCORSDomainFlag.Name))
+	}
+	if ctx.GlobalIsSet(GraphQLVirtualHostsFlag.Name) {
+		cfg.GraphQLVirtualHosts = splitAndTrim(ctx.GlobalString(GraphQLVirtualHostsFlag.Name))
+	}
+}
+
 // setWS creates the WebSocket RPC listener interface string from the set
 // command line flags, returning empty if the HTTP endpoint is disabled.
 func setWS(ctx *cli.Context, cfg *node.Config) {
@@ -895,6 +964,39 @@ func setEtherbase(ctx *cli.Context, ks *keystore.KeyStore, cfg *eth.Config) {
 	}
 }
 
+// setMasternodeSigner configures the masternode signing key, either decrypted
+// from a keystore file or held by an external signer.
+func setMasternodeSigner(ctx *cli.Context, cfg *eth.Config) {
+	checkExclusive(ctx, MasternodeKeyFileFlag, MasternodeSignerFlag)
+
+	if ctx.GlobalIsSet(MasternodeSignerFlag.Name) {
+		if !ctx.GlobalIsSet(MasternodePubkeyFlag.Name) {
+			Fatalf("--%s requires --%s", MasternodeSignerFlag.Name, MasternodePubkeyFlag.Name)
+		}
+		cfg.MasternodeSigner = ctx.GlobalString(MasternodeSignerFlag.Name)
+		cfg.MasternodePubkey = ctx.GlobalString(MasternodePubkeyFlag.Name)
+	}
+	if file := ctx.GlobalString(MasternodeKeyFileFlag.Name); file != "" {
+		keyjson, err := ioutil.ReadFile(file)
+		if err != nil {
+			Fatalf("Failed to read masternode key file: %v", err)
+		}
+		var password string
+		if path := ctx.GlobalString(MasternodePasswordFlag.Name); path != "" {
+			text, err := ioutil.ReadFile(path)
+			if err != nil {
+				Fatalf("Failed to read masternode password file: %v", err)
+			}
+			password = strings.TrimRight(strings.Split(string(text), "\n")[0], "\r")
+		}
+		key, err := keystore.DecryptKey(keyjson, password)
+		if err != nil {
+			Fatalf("Failed to decrypt masternode key file: %v", err)
+		}
+		cfg.MasternodeKey = key.PrivateKey
+	}
+}
+
 // MakePasswordList reads password lines from the file specified by the global --password flag.
 func MakePasswordList(ctx *cli.Context) []string {
 	path := ctx.GlobalString(PasswordFileFlag.Name)
@@ -986,6 +1088,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	SetP2PConfig(ctx, &cfg.P2P)
 	setIPC(ctx, cfg)
 	setHTTP(ctx, cfg)
+	setGraphQL(ctx, cfg)
 	setWS(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 
@@ -1000,6 +1103,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(NoUSBFlag.Name) {
 		cfg.NoUSB = ctx.GlobalBool(NoUSBFlag.Name)
 	}
+	if ctx.GlobalIsSet(MetricsPrometheusFlag.Name) {
+		cfg.PrometheusEndpoint = ctx.GlobalString(MetricsPrometheusFlag.Name)
+	}
 }
 
 func setDataDir(ctx *cli.Context, cfg *node.Config) {
@@ -1153,6 +1259,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	setGPO(ctx, &cfg.GPO)
 	setTxPool(ctx, &cfg.TxPool)
 	setEthash(ctx, cfg)
+	setMasternodeSigner(ctx, cfg)
 
 	if ctx.GlobalIsSet(SyncModeFlag.Name) {
 		cfg.SyncMode = *GlobalTextMarshaler(ctx, SyncModeFlag.Name).(*downloader.SyncMode)
@@ -1336,6 +1443,32 @@ func RegisterEthStatsService(stack *node.Node, url string) {
 	}
 }
 
+// RegisterGraphQLService is a utility function to construct a new service and
+// register it against a node.
+func RegisterGraphQLService(stack *node.Node, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		// Try to construct the GraphQL service backed by a full node
+		var ethServ *eth.Ethereum
+		if err := ctx.Service(&ethServ); err == nil {
+			var api *devote.API
+			if engine, ok := ethServ.Engine().(*devote.Devote); ok {
+				api = devote.NewAPI(ethServ.BlockChain(), engine)
+			}
+			return graphql.New(ethServ.APIBackend, api, endpoint, cors, vhosts, timeouts)
+		}
+		// Try to construct the GraphQL service backed by a light node, the
+		// devote schedule is not available without the full chain state
+		var lesServ *les.LightEthereum
+		if err := ctx.Service(&lesServ); err == nil {
+			return graphql.New(lesServ.ApiBackend, nil, endpoint, cors, vhosts, timeouts)
+		}
+		// Well, this should not have happened, bail out
+		return nil, errors.New("no Etherzero service")
+	}); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 func SetupMetrics(ctx *cli.Context) {
 	if metrics.Enabled {
 		log.Info("Enabling metrics collection")
@@ -67,6 +67,19 @@ func (b *Bytes) UnmarshalText(input []byte) error {
 	return err
 }
 
+// ImplementsGraphQLType returns true if Bytes implements the specified GraphQL type.
+func (b Bytes) ImplementsGraphQLType(name string) bool { return name == "Bytes" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data.
+func (b *Bytes) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		return b.UnmarshalText([]byte(input))
+	default:
+		return fmt.Errorf("unexpected type %T for Bytes", input)
+	}
+}
+
 // String returns the hex encoding of b.
 func (b Bytes) String() string {
 	return Encode(b)
@@ -177,6 +190,22 @@ func (b *Big) UnmarshalText(input []byte) error {
 	return nil
 }
 
+// ImplementsGraphQLType returns true if Big implements the specified GraphQL type.
+func (b Big) ImplementsGraphQLType(name string) bool { return name == "BigInt" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data.
+func (b *Big) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		return b.UnmarshalText([]byte(input))
+	case int32:
+		b.ToInt().SetInt64(int64(input))
+		return nil
+	default:
+		return fmt.Errorf("unexpected type %T for BigInt", input)
+	}
+}
+
 // ToInt converts b to a big.Int.
 func (b *Big) ToInt() *big.Int {
 	return (*big.Int)(b)
@@ -229,6 +258,31 @@ func (b *Uint64) UnmarshalText(input []byte) error {
 	return nil
 }
 
+// ImplementsGraphQLType returns true if Uint64 implements the specified GraphQL type.
+func (b Uint64) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data.
+func (b *Uint64) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		return b.UnmarshalText([]byte(input))
+	case int32:
+		if input < 0 {
+			return ErrUint64Range
+		}
+		*b = Uint64(input)
+		return nil
+	case float64:
+		if input < 0 || input != float64(uint64(input)) {
+			return ErrUint64Range
+		}
+		*b = Uint64(input)
+		return nil
+	default:
+		return fmt.Errorf("unexpected type %T for Long", input)
+	}
+}
+
 // String returns the hex encoding of b.
 func (b Uint64) String() string {
 	return EncodeUint64(uint64(b))
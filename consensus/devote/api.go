@@ -18,21 +18,32 @@
 package devote
 
 import (
+	"encoding/binary"
+	"errors"
 	"math/big"
 
 	"github.com/etherzero/go-etherzero/consensus"
 	"github.com/etherzero/go-etherzero/core/types"
-	"github.com/etherzero/go-etherzero/rpc"
 	"github.com/etherzero/go-etherzero/core/types/devotedb"
 	"github.com/etherzero/go-etherzero/params"
+	"github.com/etherzero/go-etherzero/rpc"
 )
+
+// errUnknownCycle is returned when the schedule of a cycle is requested for
+// which the local chain contains no blocks.
+var errUnknownCycle = errors.New("unknown cycle")
+
 // API is a user facing RPC API to allow controlling the delegate and voting
 // mechanisms of the delegated-proof-of-stake
 type API struct {
-	chain consensus.ChainReader
-	devote  *Devote
+	chain  consensus.ChainReader
+	devote *Devote
 }
 
+// NewAPI creates the devote API of the given engine, serving the state of chain.
+func NewAPI(chain consensus.ChainReader, devote *Devote) *API {
+	return &API{chain: chain, devote: devote}
+}
 
 // GetWitnesses retrieves the list of the Witnesses at specified block
 func (api *API) GetWitnesses(number *rpc.BlockNumber) ([]string, error) {
@@ -45,8 +56,8 @@ func (api *API) GetWitnesses(number *rpc.BlockNumber) ([]string, error) {
 	if header == nil {
 		return nil, errUnknownBlock
 	}
-	currentcycle:=header.Time.Uint64()/params.CycleInterval
-	devoteDB,_:=devotedb.New(devotedb.NewDatabase(api.devote.db),header.Protocol.CycleHash,header.Protocol.StatsHash)
+	currentcycle := header.Time.Uint64() / params.CycleInterval
+	devoteDB, _ := devotedb.New(api.devote.devoteCache, header.Protocol.CycleHash, header.Protocol.StatsHash)
 	witnesses, err := devoteDB.GetWitnesses(currentcycle)
 	if err != nil {
 		return nil, err
@@ -56,13 +67,133 @@ func (api *API) GetWitnesses(number *rpc.BlockNumber) ([]string, error) {
 
 // GetConfirmedBlockNumber retrieves the latest irreversible block
 func (api *API) GetConfirmedBlockNumber() (*big.Int, error) {
-	var err error
-	header := api.devote.confirmedBlockHeader
+	header := api.devote.ConfirmedBlockHeader(api.chain)
 	if header == nil {
-		header, err = api.devote.loadConfirmedBlockHeader(api.chain)
-		if err != nil {
-			return nil, err
-		}
+		return nil, ErrNilBlockHeader
 	}
 	return header.Number, nil
 }
+
+// CycleInfo is the witness schedule of a cycle together with the production
+// statistics of every elected witness.
+type CycleInfo struct {
+	Cycle     uint64          `json:"cycle"`
+	FirstSlot uint64          `json:"firstSlot"` // Timestamp of the first slot in the cycle
+	LastSlot  uint64          `json:"lastSlot"`  // Timestamp of the last slot scheduled so far
+	Witnesses []*WitnessStats `json:"witnesses"`
+}
+
+// WitnessStats is the production record of a single witness within a cycle.
+type WitnessStats struct {
+	Witness     string   `json:"witness"`
+	Slot        int      `json:"slot"`        // Position of the witness in the slot order
+	Blocks      uint64   `json:"blocks"`      // Number of blocks recorded in the stats trie
+	MissedSlots []uint64 `json:"missedSlots"` // Timestamps of the slots left empty by the witness
+}
+
+// GetCycle retrieves the elected witnesses of the given cycle, or of the current
+// cycle if none is specified, along with the number of blocks each of them produced
+// and the slots they missed. A witness without any block in a cycle is uncast from
+// the candidates of the next election.
+func (api *API) GetCycle(cycle *uint64) (*CycleInfo, error) {
+	head := api.chain.CurrentHeader()
+	if cycle == nil {
+		current := head.Time.Uint64() / params.CycleInterval
+		cycle = &current
+	}
+	start, end := *cycle*params.CycleInterval, (*cycle+1)*params.CycleInterval
+
+	// Find the last block sealed within the cycle, its devote state contains both
+	// the election result and the final block counts of the cycle.
+	last := api.lastHeaderBefore(end)
+	if last == nil || last.Number.Sign() == 0 || last.Time.Uint64() < start {
+		return nil, errUnknownCycle
+	}
+	devoteDB, err := devotedb.NewDevoteByProtocol(api.devote.devoteCache, last.Protocol)
+	if err != nil {
+		return nil, err
+	}
+	witnesses, err := devoteDB.GetWitnesses(*cycle)
+	if err != nil {
+		return nil, err
+	}
+	if len(witnesses) == 0 {
+		return nil, errUnknownCycle
+	}
+	// Collect the slots filled by the canonical chain within the cycle
+	sealed := make(map[uint64]bool)
+	first := start
+	for header := last; header != nil && header.Time.Uint64() >= start; {
+		sealed[header.Time.Uint64()] = true
+		if header.Number.Uint64() == 1 {
+			// Slots before the first block of the chain were never scheduled
+			first = header.Time.Uint64()
+			break
+		}
+		header = api.chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	lastSlot := end - params.BlockInterval
+	if head.Time.Uint64() < lastSlot {
+		lastSlot = head.Time.Uint64()
+	}
+	info := &CycleInfo{
+		Cycle:     *cycle,
+		FirstSlot: first,
+		LastSlot:  lastSlot,
+		Witnesses: make([]*WitnessStats, len(witnesses)),
+	}
+	for i, witness := range witnesses {
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, *cycle)
+		key = append(key, []byte(witness)...)
+
+		info.Witnesses[i] = &WitnessStats{
+			Witness:     witness,
+			Slot:        i,
+			Blocks:      devoteDB.GetStatsNumber(key),
+			MissedSlots: []uint64{},
+		}
+	}
+	for slot := NextSlot(first); slot <= lastSlot; slot += params.BlockInterval {
+		if sealed[slot] {
+			continue
+		}
+		offset := (slot % params.CycleInterval) / params.BlockInterval % uint64(len(witnesses))
+		info.Witnesses[offset].MissedSlots = append(info.Witnesses[offset].MissedSlots, slot)
+	}
+	return info, nil
+}
+
+// lastHeaderBefore returns the last canonical header with a timestamp before the
+// given time, or nil if not even the genesis block qualifies.
+func (api *API) lastHeaderBefore(time uint64) *types.Header {
+	head := api.chain.CurrentHeader()
+	if head.Time.Uint64() < time {
+		return head
+	}
+	// Block timestamps are strictly increasing, binary search the canonical chain
+	lo, hi := uint64(0), head.Number.Uint64()
+	var found *types.Header
+	for lo <= hi {
+		mid := lo + (hi-lo)/2
+		header := api.chain.GetHeaderByNumber(mid)
+		if header == nil {
+			return nil
+		}
+		if header.Time.Uint64() < time {
+			found, lo = header, mid+1
+		} else {
+			if mid == 0 {
+				break
+			}
+			hi = mid - 1
+		}
+	}
+	return found
+}
+
+// GetDoubleSignEvidence retrieves the evidence of witnesses sealing two different
+// blocks for the same slot collected by this node.
+func (api *API) GetDoubleSignEvidence() ([]*DoubleSignEvidence, error) {
+	return api.devote.Evidence()
+}
@@ -0,0 +1,137 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package devote_test
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/vm"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// slots returns the slots between from and to, inclusive, matching the filter.
+func slots(from, to uint64, filter func(slot uint64) bool) []uint64 {
+	list := []uint64{}
+	for slot := from; slot <= to; slot++ {
+		if filter(slot) {
+			list = append(list, slot)
+		}
+	}
+	return list
+}
+
+// Tests that the schedule of a cycle reports the blocks sealed and the slots
+// missed by every witness, both for finished cycles and for the current one.
+func TestGetCycle(t *testing.T) {
+	witnesses := []string{"0123456789abcdef", "fedcba9876543210"}
+
+	config := *params.TestChainConfig
+	config.Ethash = nil
+	config.Devote = &params.DevoteConfig{Witnesses: witnesses}
+
+	db := ethdb.NewMemDatabase()
+	gspec := &core.Genesis{Config: &config, Difficulty: big.NewInt(1)}
+	genesis := gspec.MustCommit(db)
+
+	// Blocks are generated every 10 seconds, all of them falling into the even
+	// slots of the first witness, so the second one misses every slot of its own
+	engine := devote.NewFaker(witnesses, db)
+	blocks, _ := core.GenerateChain(&config, genesis, engine, db, 65, nil)
+
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	api := devote.NewAPI(chain, engine)
+
+	var (
+		sealed  = func(slot uint64) bool { return slot%10 == 0 }
+		missed0 = func(slot uint64) bool { return slot%2 == 0 && !sealed(slot) }
+		missed1 = func(slot uint64) bool { return slot%2 == 1 }
+		missed  = func(slot uint64) bool { return !sealed(slot) }
+	)
+	tests := []struct {
+		head  int     // Number of blocks imported before the query
+		cycle *uint64 // Queried cycle, nil for the current one
+		want  *devote.CycleInfo
+	}{
+		// The current cycle is only scheduled up to the head, starting after the
+		// genesis block
+		{
+			head: 30,
+			want: &devote.CycleInfo{Cycle: 0, FirstSlot: 10, LastSlot: 300, Witnesses: []*devote.WitnessStats{
+				{Witness: witnesses[0], Slot: 0, Blocks: 30, MissedSlots: slots(11, 300, missed0)},
+				{Witness: witnesses[1], Slot: 1, Blocks: 0, MissedSlots: slots(11, 300, missed1)},
+			}},
+		},
+		// Finished cycles are scheduled up to their last slot
+		{
+			head:  65,
+			cycle: new(uint64),
+			want: &devote.CycleInfo{Cycle: 0, FirstSlot: 10, LastSlot: 599, Witnesses: []*devote.WitnessStats{
+				{Witness: witnesses[0], Slot: 0, Blocks: 59, MissedSlots: slots(11, 599, missed0)},
+				{Witness: witnesses[1], Slot: 1, Blocks: 0, MissedSlots: slots(11, 599, missed1)},
+			}},
+		},
+		// The witness without any block is uncast from the next election, leaving
+		// every slot to the remaining one
+		{
+			head: 65,
+			want: &devote.CycleInfo{Cycle: 1, FirstSlot: 600, LastSlot: 650, Witnesses: []*devote.WitnessStats{
+				{Witness: witnesses[0], Slot: 0, Blocks: 6, MissedSlots: slots(601, 650, missed)},
+			}},
+		},
+	}
+	imported := 0
+	for i, tt := range tests {
+		if _, err := chain.InsertChain(blocks[imported:tt.head]); err != nil {
+			t.Fatalf("test %d: failed to insert chain: %v", i, err)
+		}
+		imported = tt.head
+
+		info, err := api.GetCycle(tt.cycle)
+		if err != nil {
+			t.Errorf("test %d: failed to retrieve cycle: %v", i, err)
+			continue
+		}
+		if info.Cycle != tt.want.Cycle || info.FirstSlot != tt.want.FirstSlot || info.LastSlot != tt.want.LastSlot {
+			t.Errorf("test %d: cycle mismatch: have %d [%d, %d], want %d [%d, %d]", i, info.Cycle, info.FirstSlot, info.LastSlot, tt.want.Cycle, tt.want.FirstSlot, tt.want.LastSlot)
+		}
+		if len(info.Witnesses) != len(tt.want.Witnesses) {
+			t.Errorf("test %d: witness count mismatch: have %d, want %d", i, len(info.Witnesses), len(tt.want.Witnesses))
+			continue
+		}
+		for j, stats := range info.Witnesses {
+			if !reflect.DeepEqual(stats, tt.want.Witnesses[j]) {
+				t.Errorf("test %d, witness %d: stats mismatch:\nhave %+v\nwant %+v", i, j, stats, tt.want.Witnesses[j])
+			}
+		}
+	}
+	// Cycles without any block are unknown
+	for _, cycle := range []uint64{2, 100} {
+		if info, err := api.GetCycle(&cycle); err == nil {
+			t.Errorf("cycle %d: unknown cycle reported: %+v", cycle, info)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package devote
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultMaxFutureDrift is the number of seconds a block may be timestamped
+// ahead of the local clock if the chain config doesn't specify it.
+const defaultMaxFutureDrift = 1
+
+// Clock is the source of time the engine schedules and verifies the witness
+// slots with. It can be replaced to drive the slot timing deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After waits for the duration to elapse and then sends the current time on
+	// the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is a Clock following the local system clock, shifted by an
+// offset measured against the network.
+type systemClock struct {
+	offset func() time.Duration
+	limit  time.Duration // Maximum absolute offset applied to the local clock
+}
+
+// SystemClock returns the local system clock.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+// NewAdjustedClock returns the local system clock, adjusted by the offset the
+// given function measures against the network time, typically the median clock
+// offset of the peers. The peers are not trusted, so the offset is clamped to
+// the limit, usually the future drift the engine tolerates anyway: colluding
+// peers can't shift the clock further than a block's timestamp may deviate.
+func NewAdjustedClock(offset func() time.Duration, limit time.Duration) Clock {
+	return systemClock{offset: offset, limit: limit}
+}
+
+// Now implements Clock, returning the local time corrected by the offset.
+func (c systemClock) Now() time.Time {
+	if c.offset == nil {
+		return time.Now()
+	}
+	offset := c.offset()
+	switch {
+	case offset > c.limit:
+		offset = c.limit
+	case offset < -c.limit:
+		offset = -c.limit
+	}
+	return time.Now().Add(offset)
+}
+
+// After implements Clock.
+func (c systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// SimulatedClock is a Clock whose time only advances when Run is called.
+type SimulatedClock struct {
+	now     time.Time
+	waiters []simulatedWaiter
+	lock    sync.Mutex
+}
+
+type simulatedWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewSimulatedClock creates a simulated clock starting at the given time.
+func NewSimulatedClock(now time.Time) *SimulatedClock {
+	return &SimulatedClock{now: now}
+}
+
+// Now implements Clock, returning the current simulated time.
+func (c *SimulatedClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+// After implements Clock, firing once the simulated time advanced by d.
+func (c *SimulatedClock) After(d time.Duration) <-chan time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, simulatedWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Waiters returns the number of timers waiting for the simulated time to advance.
+func (c *SimulatedClock) Waiters() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.waiters)
+}
+
+// Run advances the simulated time by d, firing the timers expiring meanwhile.
+func (c *SimulatedClock) Run(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = waiters
+}
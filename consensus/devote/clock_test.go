@@ -0,0 +1,133 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package devote
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/etherzero/go-etherzero/consensus"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// configReader is a chain reader only serving the chain configuration.
+type configReader struct {
+	consensus.ChainReader
+	config *params.ChainConfig
+}
+
+func (r *configReader) Config() *params.ChainConfig { return r.config }
+
+// Tests that headers are accepted up to the configured drift ahead of the clock
+// of the engine, and that the drift defaults if left unconfigured.
+func TestFutureDrift(t *testing.T) {
+	tests := []struct {
+		drift  uint64 // Configured future drift
+		offset int64  // Offset of the header time from the clock
+		future bool   // Whether the header is from the future
+	}{
+		{0, 0, false},
+		{0, defaultMaxFutureDrift, false},
+		{0, defaultMaxFutureDrift + 1, true},
+		{5, 5, false},
+		{5, 6, true},
+	}
+	start := time.Unix(1000000, 0)
+	for i, tt := range tests {
+		clock := NewSimulatedClock(start)
+		engine := NewDevote(&params.DevoteConfig{MaxFutureDrift: tt.drift}, ethdb.NewMemDatabase())
+		engine.SetClock(clock)
+
+		// Advance the clock so that only the engine's clock could accept the header
+		clock.Run(time.Hour)
+		now := clock.Now().Unix()
+
+		chain := &configReader{config: &params.ChainConfig{Devote: engine.config}}
+		parent := &types.Header{Number: big.NewInt(0), Time: big.NewInt(now - 10)}
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     big.NewInt(1),
+			Time:       big.NewInt(now + tt.offset),
+			Extra:      make([]byte, extraVanity+extraSeal),
+			Difficulty: big.NewInt(1),
+			UncleHash:  uncleHash,
+		}
+		err := engine.verifyHeader(chain, header, []*types.Header{parent})
+		switch {
+		case tt.future && err != consensus.ErrFutureBlock:
+			t.Errorf("test %d: future block error mismatch: have %v, want %v", i, err, consensus.ErrFutureBlock)
+		case !tt.future && err != nil:
+			t.Errorf("test %d: header rejected: %v", i, err)
+		}
+	}
+}
+
+// Tests that timers of the simulated clock only fire once the time advanced.
+func TestSimulatedClock(t *testing.T) {
+	clock := NewSimulatedClock(time.Unix(0, 0))
+
+	timer := clock.After(2 * time.Second)
+	clock.Run(time.Second)
+	select {
+	case <-timer:
+		t.Fatalf("timer fired early")
+	default:
+	}
+	if clock.Waiters() != 1 {
+		t.Fatalf("waiter count mismatch: have %d, want 1", clock.Waiters())
+	}
+	clock.Run(time.Second)
+	select {
+	case now := <-timer:
+		if now.Unix() != 2 {
+			t.Errorf("fire time mismatch: have %v, want 2", now.Unix())
+		}
+	default:
+		t.Fatalf("timer didn't fire")
+	}
+	if clock.Waiters() != 0 {
+		t.Fatalf("waiter count mismatch: have %d, want 0", clock.Waiters())
+	}
+}
+
+// Tests that the adjusted clock applies the peer offset only up to its limit.
+func TestAdjustedClockLimit(t *testing.T) {
+	tests := []struct {
+		offset time.Duration // Offset measured against the peers
+		want   time.Duration // Offset applied to the local clock
+	}{
+		{0, 0},
+		{500 * time.Millisecond, 500 * time.Millisecond},
+		{-time.Second, -time.Second},
+		{10 * time.Minute, time.Second},
+		{-10 * time.Minute, -time.Second},
+	}
+	for i, tt := range tests {
+		offset := tt.offset
+		clock := NewAdjustedClock(func() time.Duration { return offset }, time.Second)
+
+		before := time.Now()
+		now := clock.Now()
+		after := time.Now()
+		if now.Before(before.Add(tt.want)) || now.After(after.Add(tt.want)) {
+			t.Errorf("test %d: applied offset mismatch: have %v, want %v", i, now.Sub(before), tt.want)
+		}
+	}
+}
@@ -34,12 +34,12 @@ import (
 	"github.com/etherzero/go-etherzero/crypto"
 	"github.com/etherzero/go-etherzero/crypto/sha3"
 	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/event"
 	"github.com/etherzero/go-etherzero/log"
 	"github.com/etherzero/go-etherzero/params"
 	"github.com/etherzero/go-etherzero/rlp"
 	"github.com/etherzero/go-etherzero/rpc"
 	"github.com/hashicorp/golang-lru"
-	"github.com/etherzero/go-etherzero/p2p/discover"
 )
 
 const (
@@ -53,9 +53,6 @@ const (
 )
 
 var (
-	etherzeroBlockReward = big.NewInt(0.3375e+18) // Block reward in wei to masternode account when successfully mining a block
-	rewardToCommunity    = big.NewInt(0.1125e+18) // Block reward in wei to community account when successfully mining a block
-
 	timeOfFirstBlock   = uint64(0)
 	confirmedBlockHead = []byte("confirmed-block-head")
 	uncleHash          = types.CalcUncleHash(nil) // Always Keccak256(RLP([])) as uncles are meaningless outside of PoW.
@@ -90,8 +87,8 @@ var (
 
 // SignerFn
 // string:master node nodeid,[8]byte
-// []byte,signature
-type SignerFn func(string, []byte) ([]byte, error)
+// *types.Header:header to seal, []byte:signature of its SealHash
+type SignerFn func(string, *types.Header) ([]byte, error)
 
 type MasternodeListFn func(number *big.Int) ([]string, error)
 
@@ -131,31 +128,127 @@ func sigHash(header *types.Header) (hash common.Hash) {
 	return hash
 }
 
+// SealHash returns the hash of a header prior to it being sealed, which is the
+// hash a witness signs.
+func SealHash(header *types.Header) common.Hash {
+	return sigHash(header)
+}
+
 type Devote struct {
 	config *params.DevoteConfig // Consensus engine configuration parameters
 	db     ethdb.Database       // Database to store and retrieve snapshot checkpoints
 
+	devoteCache devotedb.Database // Trie database of the devote state, shared with the blockchain
+
 	signer                      string        // master node nodeid
 	signFn                      SignerFn      // signature function
 	signatures                  *lru.ARCCache // Signatures of recent blocks to speed up mining
 	confirmedBlockHeader        *types.Header
+	confirmedLock               sync.RWMutex                 // Protects the confirmed block header
 	masternodeListFn            MasternodeListFn             //get current all masternodes
 	governanceContractAddressFn GetGovernanceContractAddress //get current GovernanceContractAddress
 
+	seals        *lru.ARCCache           // Seals of recent witness slots to detect double signing
+	evidenceLock sync.Mutex              // Protects the seals and the stored evidence
+	evidenceFeed event.Feed              // Feed of newly detected double signing evidence
+	scope        event.SubscriptionScope // Subscription scope of the evidence feed
+
+	clock    Clock // Source of time of the witness slots
+	fakeMode bool  // Skip signature and wall clock checks, testing only
+
 	mu   sync.RWMutex
 	stop chan bool
 }
 
 func NewDevote(config *params.DevoteConfig, db ethdb.Database) *Devote {
 	signatures, _ := lru.NewARC(inmemorySignatures)
+	seals, _ := lru.NewARC(inmemorySeals)
 	return &Devote{
-		config:     config,
-		db:         db,
-		signatures: signatures,
+		config:      config,
+		db:          db,
+		devoteCache: devotedb.NewDatabase(db),
+		signatures:  signatures,
+		seals:       seals,
+		clock:       SystemClock(),
+	}
+}
+
+// SetClock replaces the source of time the engine schedules and verifies the
+// witness slots with.
+func (d *Devote) SetClock(clock Clock) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.clock = clock
+}
+
+// Clock returns the source of time the engine schedules and verifies the
+// witness slots with.
+func (d *Devote) Clock() Clock {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.clock
+}
+
+// MaxFutureDrift returns how far ahead of the local clock a block may be
+// timestamped and still be accepted.
+func (d *Devote) MaxFutureDrift() time.Duration {
+	drift := uint64(defaultMaxFutureDrift)
+	if d.config != nil && d.config.MaxFutureDrift > 0 {
+		drift = d.config.MaxFutureDrift
 	}
+	return time.Duration(drift) * time.Second
+}
+
+// DevoteCache returns the trie database the engine reads the devote state from.
+// The blockchain adopts it, so that the devote tries of recent blocks, which are
+// only kept in memory, are visible to the engine.
+func (d *Devote) DevoteCache() devotedb.Database {
+	return d.devoteCache
+}
+
+// NewFaker creates a devote consensus engine for testing purposes. It elects
+// witnesses from the given in-memory masternode set instead of the masternode
+// contract, and accepts unsigned blocks as long as the header's witness is the
+// one scheduled for the block's time slot. Block times are taken as given, so
+// the engine can be driven by a simulated clock.
+func NewFaker(witnesses []string, db ethdb.Database) *Devote {
+	d := NewDevote(&params.DevoteConfig{Witnesses: witnesses}, db)
+	d.fakeMode = true
+	d.masternodeListFn = func(number *big.Int) ([]string, error) {
+		nodes := make([]string, len(witnesses))
+		copy(nodes, witnesses)
+		return nodes, nil
+	}
+	d.governanceContractAddressFn = func(number *big.Int) (common.Address, error) {
+		return params.GovernanceContractAddress, nil
+	}
+	return d
+}
+
+// ConfirmedBlockHeader retrieves the latest irreversible block, i.e. the most
+// recent block on top of which at least ConsensusSize distinct witnesses have
+// produced blocks. The genesis block is returned until a block got confirmed.
+func (d *Devote) ConfirmedBlockHeader(chain consensus.ChainReader) *types.Header {
+	d.confirmedLock.RLock()
+	header := d.confirmedBlockHeader
+	d.confirmedLock.RUnlock()
+
+	if header != nil {
+		return header
+	}
+	header, err := d.loadConfirmedBlockHeader(chain)
+	if err != nil {
+		return chain.GetHeaderByNumber(0)
+	}
+	return header
 }
 
 func (d *Devote) updateConfirmedBlockHeader(chain consensus.ChainReader) error {
+	d.confirmedLock.Lock()
+	defer d.confirmedLock.Unlock()
+
 	if d.confirmedBlockHeader == nil {
 		header, err := d.loadConfirmedBlockHeader(chain)
 		if err != nil {
@@ -170,10 +263,7 @@ func (d *Devote) updateConfirmedBlockHeader(chain consensus.ChainReader) error {
 	curHeader := chain.CurrentHeader()
 	cycle := uint64(0)
 	witnessMap := make(map[string]bool)
-	consensusSize := int(15)
-	if chain.Config().ChainID.Cmp(big.NewInt(90)) != 0 {
-		consensusSize = 1
-	}
+	consensusSize := chain.Config().Devote.Params(curHeader.Number).ConsensusSize
 	for d.confirmedBlockHeader.Hash() != curHeader.Hash() &&
 		d.confirmedBlockHeader.Number.Uint64() < curHeader.Number.Uint64() {
 		curCycle := curHeader.Time.Uint64() / params.CycleInterval
@@ -245,16 +335,16 @@ func (d *Devote) Prepare(chain consensus.ChainReader, header *types.Header) erro
 
 // AccumulateRewards credits the coinbase of the given block with the mining
 // reward.  The devote consensus allowed uncle block .
-func AccumulateRewards(govAddress common.Address, state *state.StateDB, header *types.Header, uncles []*types.Header) {
+func AccumulateRewards(config *params.ChainConfig, govAddress common.Address, state *state.StateDB, header *types.Header, uncles []*types.Header) {
 	// Select the correct block reward based on chain progression
-	blockReward := etherzeroBlockReward
+	rules := config.Devote.Params(header.Number)
 
 	// Accumulate the rewards for the masternode and any included uncles
-	reward := new(big.Int).Set(blockReward)
+	reward := new(big.Int).Set(rules.BlockReward)
 	state.AddBalance(header.Coinbase, reward, header.Number)
 
 	//  Accumulate the rewards to community account
-	rewardForCommunity := new(big.Int).Set(rewardToCommunity)
+	rewardForCommunity := new(big.Int).Set(rules.CommunityReward)
 	state.AddBalance(govAddress, rewardForCommunity, header.Number)
 }
 
@@ -262,31 +352,18 @@ func AccumulateRewards(govAddress common.Address, state *state.StateDB, header *
 // setting the final state and assembling the block.
 func (d *Devote) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction,
 	uncles []*types.Header, receipts []*types.Receipt, devoteDB *devotedb.DevoteDB) (*types.Block, error) {
-	maxWitnessSize := uint64(21)
-	safeSize := int(15)
-	if chain.Config().ChainID.Cmp(big.NewInt(90)) != 0 {
-		maxWitnessSize = 1
-		safeSize = 1
-	}
 	parent := chain.GetHeaderByHash(header.ParentHash)
-	number := maxWitnessSize
-	stableBlockNumber := new(big.Int).Sub(parent.Number, big.NewInt(int64(number)))
-	if stableBlockNumber.Cmp(big.NewInt(0)) < 0 {
-		stableBlockNumber = big.NewInt(0)
-	}
+	stableBlockNumber := stableNumber(chain, header, parent)
+
 	// Accumulate block rewards and commit the final state root
 	govaddress, gerr := d.governanceContractAddressFn(stableBlockNumber)
 	if gerr != nil {
 
 		return nil, fmt.Errorf("get current governance address err:%s", gerr)
 	}
-	AccumulateRewards(govaddress, state, header, uncles)
+	AccumulateRewards(chain.Config(), govaddress, state, header, uncles)
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
 
-	controller := &Controller{
-		devoteDB:  devoteDB,
-		TimeStamp: header.Time.Uint64(),
-	}
 	if timeOfFirstBlock == 0 {
 		if firstBlockHeader := chain.GetHeaderByNumber(1); firstBlockHeader != nil {
 			timeOfFirstBlock = firstBlockHeader.Time.Uint64()
@@ -298,11 +375,18 @@ func (d *Devote) Finalize(chain consensus.ChainReader, header *types.Header, sta
 		return nil, fmt.Errorf("get current masternodes err:%s", merr)
 	}
 	log.Debug("finalize get masternode ", "stableBlockNumber", stableBlockNumber, "nodes", nodes)
-	genesis := chain.GetHeaderByNumber(0)
-	first := chain.GetHeaderByNumber(1)
-	err := controller.election(genesis, first, parent, nodes, safeSize, maxWitnessSize)
+	controller, err := elect(chain, header, parent, devoteDB, nodes)
 	if err != nil {
-		return nil, fmt.Errorf("got error when voting next cycle, err: %s", err)
+		return nil, err
+	}
+	if d.fakeMode && header.Witness == "" {
+		// Nobody prepared the header, let the scheduled witness take the slot
+		devoteDB.SetCycle(parent.Time.Uint64() / params.CycleInterval)
+		witness, err := controller.lookup(header.Time.Uint64())
+		if err != nil {
+			return nil, err
+		}
+		header.Witness = witness
 	}
 	//miner Rolling
 	log.Debug("rolling ", "Number", header.Number, "parnetTime", parent.Time.Uint64(), "headerTime", header.Time.Uint64(), "witness", header.Witness)
@@ -312,6 +396,35 @@ func (d *Devote) Finalize(chain consensus.ChainReader, header *types.Header, sta
 	return types.NewBlock(header, txs, uncles, receipts), nil
 }
 
+// stableNumber returns the number of the block whose contract state provides
+// the masternodes and the governance address to the given header, the maximum
+// witness size behind its parent.
+func stableNumber(chain consensus.ChainReader, header, parent *types.Header) *big.Int {
+	rules := chain.Config().Devote.Params(header.Number)
+	stableBlockNumber := new(big.Int).Sub(parent.Number, new(big.Int).SetUint64(rules.MaxWitnessSize))
+	if stableBlockNumber.Sign() < 0 {
+		stableBlockNumber = big.NewInt(0)
+	}
+	return stableBlockNumber
+}
+
+// elect runs the witness elections of all cycles started between the parent
+// and the header on the devote state of the parent.
+func elect(chain consensus.ChainReader, header, parent *types.Header, devoteDB *devotedb.DevoteDB, nodes []string) (*Controller, error) {
+	rules := chain.Config().Devote.Params(header.Number)
+	controller := &Controller{
+		devoteDB:  devoteDB,
+		TimeStamp: header.Time.Uint64(),
+	}
+	genesis := chain.GetHeaderByNumber(0)
+	first := chain.GetHeaderByNumber(1)
+	err := controller.election(genesis, first, parent, nodes, rules.SafeSize, rules.MaxWitnessSize)
+	if err != nil {
+		return nil, fmt.Errorf("got error when voting next cycle, err: %s", err)
+	}
+	return controller, nil
+}
+
 // Author implements consensus.Engine, returning the header's coinbase as the
 // proof-of-stake verified author of the block.
 func (d *Devote) Author(header *types.Header) (common.Address, error) {
@@ -329,15 +442,15 @@ func (d *Devote) verifyHeader(chain consensus.ChainReader, header *types.Header,
 		return errUnknownBlock
 	}
 	number := header.Number.Uint64()
-	// Unnecssary to verify the block from feature
-	if header.Time.Cmp(big.NewInt(time.Now().Unix())) > 0 {
+	// Don't accept blocks from the future, beyond the allowed clock drift
+	if !d.fakeMode && header.Time.Cmp(big.NewInt(d.Clock().Now().Add(d.MaxFutureDrift()).Unix())) > 0 {
 		return consensus.ErrFutureBlock
 	}
 	// Check that the extra-data contains both the vanity and signature
-	if len(header.Extra) < extraVanity {
+	if !d.fakeMode && len(header.Extra) < extraVanity {
 		return errMissingVanity
 	}
-	if len(header.Extra) < extraVanity+extraSeal {
+	if !d.fakeMode && len(header.Extra) < extraVanity+extraSeal {
 		return errMissingSignature
 	}
 	// Ensure that the mix digest is zero as we don't have fork protection currently
@@ -417,7 +530,7 @@ func (d *Devote) verifySeal(chain consensus.ChainReader, header *types.Header, p
 		parent = chain.GetHeader(header.ParentHash, number-1)
 	}
 
-	devoteDB, err := devotedb.NewDevoteByProtocol(devotedb.NewDatabase(d.db), parent.Protocol)
+	devoteDB, err := devotedb.NewDevoteByProtocol(d.devoteCache, parent.Protocol)
 	if err != nil {
 		// log.Debug("devote verifySeal failed ", "cycle Hash", devoteProtocol.CycleTrie())
 		return err
@@ -430,6 +543,34 @@ func (d *Devote) verifySeal(chain consensus.ChainReader, header *types.Header, p
 	if err != nil {
 		return err
 	}
+	return d.verifyWitness(chain, witness, header)
+}
+
+// VerifyScheduledSeal checks whether the header was sealed by the witness
+// scheduled for its time slot, given the witness list of its parent's cycle.
+// Light clients use it to verify seals with witnesses retrieved on demand, as
+// they don't hold the devote tries locally.
+func (d *Devote) VerifyScheduledSeal(chain consensus.ChainReader, header *types.Header, witnesses []string) error {
+	// Verifying the genesis block is not supported
+	if header.Number.Uint64() == 0 {
+		return errUnknownBlock
+	}
+	witness, err := lookupWitness(witnesses, header.Time.Uint64())
+	if err != nil {
+		return err
+	}
+	return d.verifyWitness(chain, witness, header)
+}
+
+// verifyWitness checks that the header was signed by the scheduled witness and
+// updates the confirmed block header.
+func (d *Devote) verifyWitness(chain consensus.ChainReader, witness string, header *types.Header) error {
+	if d.fakeMode {
+		if witness != header.Witness {
+			return ErrInvalidBlockWitness
+		}
+		return d.updateConfirmedBlockHeader(chain)
+	}
 	if err := d.verifyBlockSigner(witness, header); err != nil {
 		return err
 	}
@@ -437,7 +578,13 @@ func (d *Devote) verifySeal(chain consensus.ChainReader, header *types.Header, p
 }
 
 func (d *Devote) verifyBlockSigner(witness string, header *types.Header) error {
-	signer, err := ecrecover(header, d.signatures)
+	return verifyBlockSigner(witness, header, d.signatures)
+}
+
+// verifyBlockSigner checks that the header was signed by the given witness, and
+// that the witness is named in the header.
+func verifyBlockSigner(witness string, header *types.Header, sigcache *lru.ARCCache) error {
+	signer, err := ecrecover(header, sigcache)
 	if err != nil {
 		return err
 	}
@@ -467,7 +614,7 @@ func (d *Devote) CheckWitness(lastBlock *types.Block, now int64) error {
 	if err := d.checkTime(lastBlock, uint64(now)); err != nil {
 		return err
 	}
-	devoteDB, err := devotedb.NewDevoteByProtocol(devotedb.NewDatabase(d.db), lastBlock.Header().Protocol)
+	devoteDB, err := devotedb.NewDevoteByProtocol(d.devoteCache, lastBlock.Header().Protocol)
 	if err != nil {
 		return err
 	}
@@ -495,23 +642,24 @@ func (d *Devote) Seal(chain consensus.ChainReader, block *types.Block, stop <-ch
 	if number == 0 {
 		return nil, errUnknownBlock
 	}
-	now := time.Now().Unix()
-	NextSlot := int64(NextSlot(uint64(now)))
-	delay := NextSlot - now
-	log.Info("Devote Seal delay time :", "delay", delay, "NextSlot", NextSlot, "now", now)
+	// Fake sealing keeps the prepared slot and skips the signature
+	if d.fakeMode {
+		return block.WithSeal(header), nil
+	}
+	clock := d.Clock()
+	now := clock.Now()
+	slot := time.Unix(int64(NextSlot(uint64(now.Unix()))), 0)
+	delay := slot.Sub(now)
+	log.Info("Devote Seal delay time :", "delay", delay, "NextSlot", slot.Unix(), "now", now.Unix())
 	if delay > 0 {
 		select {
 		case <-stop:
 			return nil, nil
-		case <-time.After(time.Duration(delay) * time.Second):
+		case <-clock.After(delay):
 		}
 	}
-	drift := time.Duration(discover.NanoDrift())
-	blockTime := time.Now().Add(-drift).Unix()
-	block.Header().Time.SetInt64(blockTime)
-
 	// time's up, sign the block
-	sighash, err := d.signFn(d.signer, sigHash(header).Bytes())
+	sighash, err := d.signFn(d.signer, header)
 	if err != nil {
 		return nil, err
 	}
@@ -519,7 +667,6 @@ func (d *Devote) Seal(chain consensus.ChainReader, block *types.Block, stop <-ch
 	return block.WithSeal(header), nil
 }
 
-
 func (d *Devote) CalcDifficulty(chain consensus.ChainReader, time uint64, parent *types.Header) *big.Int {
 	return big.NewInt(1)
 }
@@ -582,13 +729,15 @@ func (d *Devote) APIs(chain consensus.ChainReader) []rpc.API {
 	return []rpc.API{{
 		Namespace: "devote",
 		Version:   "1.0",
-		Service:   &API{chain: chain, devote: d},
+		Service:   NewAPI(chain, d),
 		Public:    true,
 	}}
 }
 
-// Close implements consensus.Engine. It's a noop for Devote as there is are no background threads.
+// Close implements consensus.Engine. It's a noop for Devote as there is are no
+// background threads, only the evidence subscriptions are torn down.
 func (c *Devote) Close() error {
+	c.scope.Close()
 	return nil
 }
 
@@ -0,0 +1,147 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package devote
+
+import (
+	"fmt"
+
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/event"
+	"github.com/etherzero/go-etherzero/log"
+	"github.com/etherzero/go-etherzero/rlp"
+)
+
+const (
+	inmemorySeals = 4096 // Number of recent witness slots to keep the seal of for equivocation checks
+	maxEvidence   = 1024 // Maximum number of double signing evidence kept in the database
+)
+
+// evidenceKey is the database key the collected double signing evidence is
+// stored under.
+var evidenceKey = []byte("devote-evidence")
+
+// DoubleSignEvidence proves that a witness sealed two different headers for the
+// same time slot. Masternode owners submit it to the masternode contract with
+// masternode_reportDoubleSign to have the witness removed.
+type DoubleSignEvidence struct {
+	Witness string        `json:"witness"`
+	Slot    uint64        `json:"slot"`
+	First   *types.Header `json:"first"`
+	Second  *types.Header `json:"second"`
+}
+
+// CheckDoubleSign records the seal of a header received from the network and
+// returns the evidence if its witness already sealed a different header for
+// the same slot. Headers not signed by their own witness are ignored, as those
+// can be forged by anybody.
+func (d *Devote) CheckDoubleSign(header *types.Header) *DoubleSignEvidence {
+	if header.Number.Sign() == 0 {
+		return nil
+	}
+	if !d.fakeMode {
+		signer, err := ecrecover(header, d.signatures)
+		if err != nil || signer != header.Witness {
+			return nil
+		}
+	}
+	slot := header.Time.Uint64()
+	key := fmt.Sprintf("%s-%d", header.Witness, slot)
+
+	d.evidenceLock.Lock()
+	prev, known := d.seals.Get(key)
+	if !known {
+		d.seals.Add(key, header)
+		d.evidenceLock.Unlock()
+		return nil
+	}
+	first := prev.(*types.Header)
+	if first.Hash() == header.Hash() {
+		d.evidenceLock.Unlock()
+		return nil
+	}
+	evidence := &DoubleSignEvidence{
+		Witness: header.Witness,
+		Slot:    slot,
+		First:   first,
+		Second:  header,
+	}
+	fresh, err := d.storeEvidence(evidence)
+	d.evidenceLock.Unlock()
+
+	if err != nil {
+		log.Error("Failed to store double sign evidence", "witness", evidence.Witness, "slot", slot, "err", err)
+	}
+	if !fresh {
+		return evidence
+	}
+	log.Warn("Witness sealed two blocks for the same slot", "witness", evidence.Witness, "slot", slot,
+		"number", header.Number, "first", first.Hash(), "second", header.Hash())
+	d.evidenceFeed.Send(evidence)
+	return evidence
+}
+
+// Evidence retrieves all double signing evidence collected so far, oldest first.
+func (d *Devote) Evidence() ([]*DoubleSignEvidence, error) {
+	d.evidenceLock.Lock()
+	defer d.evidenceLock.Unlock()
+
+	return d.loadEvidence()
+}
+
+// SubscribeDoubleSignEvidence registers a subscription notified of every new
+// double signing evidence, e.g. to report it to the masternode contract.
+func (d *Devote) SubscribeDoubleSignEvidence(ch chan<- *DoubleSignEvidence) event.Subscription {
+	return d.scope.Track(d.evidenceFeed.Subscribe(ch))
+}
+
+// loadEvidence reads the collected evidence from the database. The caller must
+// hold the evidence lock.
+func (d *Devote) loadEvidence() ([]*DoubleSignEvidence, error) {
+	blob, err := d.db.Get(evidenceKey)
+	if err != nil {
+		return nil, nil
+	}
+	var evidence []*DoubleSignEvidence
+	if err := rlp.DecodeBytes(blob, &evidence); err != nil {
+		return nil, err
+	}
+	return evidence, nil
+}
+
+// storeEvidence appends the evidence to the database, dropping the oldest entry
+// once maxEvidence is reached. It reports whether the evidence is new, as only
+// one proof per witness and slot is kept. The caller must hold the evidence lock.
+func (d *Devote) storeEvidence(evidence *DoubleSignEvidence) (bool, error) {
+	stored, err := d.loadEvidence()
+	if err != nil {
+		return false, err
+	}
+	for _, e := range stored {
+		if e.Witness == evidence.Witness && e.Slot == evidence.Slot {
+			return false, nil
+		}
+	}
+	stored = append(stored, evidence)
+	if len(stored) > maxEvidence {
+		stored = stored[len(stored)-maxEvidence:]
+	}
+	blob, err := rlp.EncodeToBytes(stored)
+	if err != nil {
+		return true, err
+	}
+	return true, d.db.Put(evidenceKey, blob)
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package devote
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/devotedb"
+	"github.com/etherzero/go-etherzero/ethdb"
+)
+
+// Tests that conflicting seals of a witness for the same slot are detected and
+// persisted.
+func TestDoubleSignEvidence(t *testing.T) {
+	witness := "0123456789abcdef"
+
+	db := ethdb.NewMemDatabase()
+	engine := NewFaker([]string{witness}, db)
+
+	evidenceCh := make(chan *DoubleSignEvidence, 1)
+	sub := engine.SubscribeDoubleSignEvidence(evidenceCh)
+	defer sub.Unsubscribe()
+
+	first := &types.Header{Number: big.NewInt(1), Time: big.NewInt(100), Witness: witness, Protocol: new(devotedb.DevoteProtocol)}
+	second := types.CopyHeader(first)
+	second.Extra = []byte("conflict")
+	other := types.CopyHeader(first)
+	other.Time = big.NewInt(101)
+
+	if evidence := engine.CheckDoubleSign(first); evidence != nil {
+		t.Fatalf("first seal reported as double sign: %v", evidence)
+	}
+	if evidence := engine.CheckDoubleSign(first); evidence != nil {
+		t.Fatalf("repeated seal reported as double sign: %v", evidence)
+	}
+	if evidence := engine.CheckDoubleSign(other); evidence != nil {
+		t.Fatalf("seal of another slot reported as double sign: %v", evidence)
+	}
+	evidence := engine.CheckDoubleSign(second)
+	if evidence == nil {
+		t.Fatalf("conflicting seal not detected")
+	}
+	if evidence.First.Hash() != first.Hash() || evidence.Second.Hash() != second.Hash() || evidence.Slot != 100 {
+		t.Errorf("evidence mismatch: have %v", evidence)
+	}
+	select {
+	case ev := <-evidenceCh:
+		if ev != evidence {
+			t.Errorf("announced evidence mismatch: have %v, want %v", ev, evidence)
+		}
+	default:
+		t.Errorf("evidence not announced")
+	}
+	// The evidence must survive a restart and be reported only once
+	restarted := NewFaker([]string{witness}, db)
+	restarted.CheckDoubleSign(first)
+	restarted.CheckDoubleSign(second)
+
+	stored, err := restarted.Evidence()
+	if err != nil {
+		t.Fatalf("failed to load evidence: %v", err)
+	}
+	if len(stored) != 1 || stored[0].Witness != witness || stored[0].Second.Hash() != second.Hash() {
+		t.Fatalf("stored evidence mismatch: have %v", stored)
+	}
+}
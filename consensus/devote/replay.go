@@ -0,0 +1,128 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package devote
+
+import (
+	"fmt"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/consensus"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/devotedb"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/params"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// ReplayResult is the outcome of replaying the devote rules of a single block.
+type ReplayResult struct {
+	Protocol *devotedb.DevoteProtocol // Devote protocol recomputed for the block
+	Witness  string                   // Witness scheduled for the time slot of the block
+	Faults   []error                  // Timing and seal violations of the block
+}
+
+// Diverged reports whether the devote protocol stored in the header differs from
+// the recomputed one.
+func (r *ReplayResult) Diverged(header *types.Header) bool {
+	return header.Protocol == nil || header.Protocol.CycleHash != r.Protocol.CycleHash || header.Protocol.StatsHash != r.Protocol.StatsHash
+}
+
+// Replayer recomputes the devote state of a chain block by block from the
+// genesis, the way Finalize and VerifySeal do on import, without relying on the
+// devote tries stored along the blocks. It is used to audit chain databases.
+type Replayer struct {
+	chain       consensus.ChainReader
+	db          devotedb.Database // Trie database of the replayed devote state, never flushed
+	masternodes MasternodeListFn  // Masternodes registered at a stable block
+	signatures  *lru.ARCCache     // Signatures of recent blocks
+
+	parent   *types.Header            // Last header replayed
+	protocol *devotedb.DevoteProtocol // Devote protocol recomputed for the parent
+}
+
+// NewReplayer creates a replayer starting at the genesis of the chain, whose
+// devote tries are read from db.
+func NewReplayer(chain consensus.ChainReader, db ethdb.Database, masternodes MasternodeListFn) (*Replayer, error) {
+	genesis := chain.GetHeaderByNumber(0)
+	if genesis == nil || genesis.Protocol == nil {
+		return nil, errUnknownBlock
+	}
+	signatures, _ := lru.NewARC(inmemorySignatures)
+	return &Replayer{
+		chain:       chain,
+		db:          devotedb.NewDatabase(db),
+		masternodes: masternodes,
+		signatures:  signatures,
+		parent:      genesis,
+		protocol:    genesis.Protocol,
+	}, nil
+}
+
+// Replay checks the time slot and the seal of the next block of the chain
+// against the replayed witness schedule, and recomputes its devote protocol.
+// Faults of the block are reported in the result, the error is only set if the
+// block can't be replayed at all.
+func (r *Replayer) Replay(header *types.Header) (*ReplayResult, error) {
+	parent := r.parent
+	if header.Number.Uint64() != parent.Number.Uint64()+1 || header.ParentHash != parent.Hash() {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	result := new(ReplayResult)
+
+	// Check the block against the witness schedule of the parent's cycle
+	if parent.Time.Uint64()+params.BlockInterval > header.Time.Uint64() {
+		result.Faults = append(result.Faults, ErrInvalidTimestamp)
+	}
+	devoteDB, err := devotedb.NewDevoteByProtocol(r.db, r.protocol)
+	if err != nil {
+		return nil, err
+	}
+	devoteDB.SetCycle(parent.Time.Uint64() / params.CycleInterval)
+	controller := &Controller{devoteDB: devoteDB}
+	if result.Witness, err = controller.lookup(header.Time.Uint64()); err != nil {
+		result.Faults = append(result.Faults, err)
+	} else if err := verifyBlockSigner(result.Witness, header, r.signatures); err != nil {
+		result.Faults = append(result.Faults, err)
+	}
+	// Recompute the devote protocol from the replayed state of the parent
+	nodes, err := r.masternodes(stableNumber(r.chain, header, parent))
+	if err != nil {
+		return nil, fmt.Errorf("get current masternodes err:%s", err)
+	}
+	if devoteDB, err = devotedb.NewDevoteByProtocol(r.db, r.protocol); err != nil {
+		return nil, err
+	}
+	if _, err := elect(r.chain, header, parent, devoteDB, nodes); err != nil {
+		return nil, err
+	}
+	devoteDB.Rolling(parent.Time.Uint64(), header.Time.Uint64(), header.Witness)
+	if result.Protocol, err = devoteDB.Commit(); err != nil {
+		return nil, err
+	}
+	// Keep the replayed tries of the new block alive and release the parent's
+	triedb := r.db.TrieDB()
+	for _, root := range result.Protocol.Roots() {
+		triedb.Reference(root, common.Hash{})
+	}
+	if parent.Number.Sign() > 0 {
+		for _, root := range r.protocol.Roots() {
+			triedb.Dereference(root)
+		}
+	}
+	r.parent, r.protocol = header, result.Protocol
+	return result, nil
+}
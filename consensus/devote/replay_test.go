@@ -0,0 +1,92 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package devote_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/consensus"
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/devotedb"
+	"github.com/etherzero/go-etherzero/core/vm"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// Tests that replaying the devote rules over an imported chain recomputes the
+// stored devote protocols and the scheduled witnesses.
+func TestDevoteReplay(t *testing.T) {
+	witnesses := []string{"0123456789abcdef", "fedcba9876543210"}
+
+	config := *params.TestChainConfig
+	config.Ethash = nil
+	config.Devote = &params.DevoteConfig{Witnesses: witnesses}
+
+	db := ethdb.NewMemDatabase()
+	gspec := &core.Genesis{Config: &config, Difficulty: big.NewInt(1)}
+	genesis := gspec.MustCommit(db)
+
+	engine := devote.NewFaker(witnesses, db)
+	blocks, _ := core.GenerateChain(&config, genesis, engine, db, 3*int(params.CycleInterval)/10, nil)
+
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	masternodes := func(number *big.Int) ([]string, error) {
+		return append([]string{}, witnesses...), nil
+	}
+	replayer, err := devote.NewReplayer(chain, db, masternodes)
+	if err != nil {
+		t.Fatalf("failed to create replayer: %v", err)
+	}
+	for _, block := range blocks {
+		header := block.Header()
+		result, err := replayer.Replay(header)
+		if err != nil {
+			t.Fatalf("block %d: failed to replay: %v", header.Number, err)
+		}
+		if result.Diverged(header) {
+			t.Fatalf("block %d: devote protocol diverges: have %x/%x, want %x/%x", header.Number,
+				header.Protocol.CycleHash, header.Protocol.StatsHash, result.Protocol.CycleHash, result.Protocol.StatsHash)
+		}
+		if result.Witness != header.Witness {
+			t.Errorf("block %d: scheduled witness mismatch: have %s, want %s", header.Number, result.Witness, header.Witness)
+		}
+		// The faker doesn't sign its blocks
+		if len(result.Faults) != 1 {
+			t.Errorf("block %d: seal faults mismatch: have %v, want missing signature", header.Number, result.Faults)
+		}
+		tampered := types.CopyHeader(header)
+		tampered.Protocol = &devotedb.DevoteProtocol{CycleHash: header.Protocol.CycleHash}
+		if !result.Diverged(tampered) {
+			t.Errorf("block %d: tampered devote protocol not detected", header.Number)
+		}
+	}
+	// Blocks must be replayed in order
+	if _, err := replayer.Replay(blocks[0].Header()); err != consensus.ErrUnknownAncestor {
+		t.Errorf("out of order replay error mismatch: have %v, want %v", err, consensus.ErrUnknownAncestor)
+	}
+}
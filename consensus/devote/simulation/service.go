@@ -0,0 +1,375 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/devotedb"
+	"github.com/etherzero/go-etherzero/core/vm"
+	"github.com/etherzero/go-etherzero/log"
+	"github.com/etherzero/go-etherzero/p2p"
+	"github.com/etherzero/go-etherzero/p2p/enode"
+	"github.com/etherzero/go-etherzero/rpc"
+)
+
+// Block propagation protocol of the simulated nodes.
+const (
+	protocolName    = "devsim"
+	protocolVersion = 1
+	protocolLength  = 2
+
+	blockMsg    = 0x00 // Announces a block, or answers a block request
+	getBlockMsg = 0x01 // Requests the block with the given hash
+)
+
+// service is a simulated devote node. It runs a full block chain on top of the
+// database of its simulation node, seals the slots its witness is scheduled for
+// and gossips new chain heads to its peers, fetching unknown ancestors by hash.
+type service struct {
+	sim  *Simulation
+	node *Node
+
+	engine *devote.Devote
+	chain  *core.BlockChain
+
+	peers   map[enode.ID]*peer
+	orphans map[common.Hash][]*types.Block // Blocks waiting for their parent, keyed by parent hash
+	lock    sync.Mutex                     // Serialises block imports and protects the fields above
+}
+
+func newService(sim *Simulation, n *Node) (*service, error) {
+	engine := devote.NewFaker(sim.witnesses(), n.db)
+	engine.SetClock(sim.clock)
+	engine.Authorize(n.Witness, nil)
+
+	// Blocks of equal total difficulty never displace the local head, so that
+	// fork choice doesn't depend on random tie breaking
+	preserve := func(*types.Block) bool { return true }
+
+	chain, err := core.NewBlockChain(n.db, nil, sim.genesis.Config, engine, vm.Config{}, preserve)
+	if err != nil {
+		return nil, err
+	}
+	return &service{
+		sim:     sim,
+		node:    n,
+		engine:  engine,
+		chain:   chain,
+		peers:   make(map[enode.ID]*peer),
+		orphans: make(map[common.Hash][]*types.Block),
+	}, nil
+}
+
+// Protocols implements node.Service, returning the block propagation protocol.
+func (s *service) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{{
+		Name:    protocolName,
+		Version: protocolVersion,
+		Length:  protocolLength,
+		Run:     s.run,
+	}}
+}
+
+// APIs implements node.Service, exposing the devote API of the engine.
+func (s *service) APIs() []rpc.API {
+	return s.engine.APIs(s.chain)
+}
+
+// Start implements node.Service.
+func (s *service) Start(server *p2p.Server) error {
+	return nil
+}
+
+// Stop implements node.Service, shutting down the block chain.
+func (s *service) Stop() error {
+	s.chain.Stop()
+	s.engine.Close()
+	return nil
+}
+
+// run is the protocol handler of a single peer connection.
+func (s *service) run(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+	peer := newPeer(p.ID(), rw, s.sim)
+	defer peer.close()
+	go peer.loop()
+
+	// Announce our head before registering, so that the handshake is tracked
+	// by the time the simulation sees the peer connected
+	s.lock.Lock()
+	peer.sendBlock(s.chain.CurrentBlock())
+	s.peers[peer.id] = peer
+	s.lock.Unlock()
+
+	defer func() {
+		s.lock.Lock()
+		delete(s.peers, peer.id)
+		s.lock.Unlock()
+	}()
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		err = s.handle(peer, msg)
+		msg.Discard()
+		s.sim.delivered()
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle processes a single message received from the given peer.
+func (s *service) handle(p *peer, msg p2p.Msg) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch msg.Code {
+	case blockMsg:
+		block := new(types.Block)
+		if err := msg.Decode(block); err != nil {
+			return fmt.Errorf("invalid block: %v", err)
+		}
+		p.known[block.Hash()] = struct{}{}
+		s.importBlock(p, block)
+
+	case getBlockMsg:
+		var hash common.Hash
+		if err := msg.Decode(&hash); err != nil {
+			return fmt.Errorf("invalid block request: %v", err)
+		}
+		if block := s.chain.GetBlockByHash(hash); block != nil {
+			p.sendBlock(block)
+		}
+
+	default:
+		return fmt.Errorf("unknown message code %d", msg.Code)
+	}
+	return nil
+}
+
+// importBlock inserts a block received from a peer together with all orphans
+// waiting for it, requesting its parent first if it's unknown. New heads are
+// relayed to the other peers. The caller must hold the service lock.
+func (s *service) importBlock(from *peer, block *types.Block) {
+	if s.chain.HasBlock(block.Hash(), block.NumberU64()) {
+		return
+	}
+	if !s.chain.HasBlock(block.ParentHash(), block.NumberU64()-1) {
+		s.orphans[block.ParentHash()] = append(s.orphans[block.ParentHash()], block)
+		from.send(getBlockMsg, block.ParentHash())
+		return
+	}
+	head := s.chain.CurrentBlock().Hash()
+
+	queue := []*types.Block{block}
+	for len(queue) > 0 {
+		block, queue = queue[0], queue[1:]
+		if _, err := s.chain.InsertChain(types.Blocks{block}); err != nil {
+			log.Debug("Rejected simulated block", "node", s.node.Witness, "number", block.Number(), "hash", block.Hash(), "err", err)
+			s.node.rejected(block)
+			continue
+		}
+		queue = append(queue, s.orphans[block.Hash()]...)
+		delete(s.orphans, block.Hash())
+	}
+	if current := s.chain.CurrentBlock(); current.Hash() != head {
+		s.broadcast(current)
+	}
+}
+
+// seal produces the block of the current slot if it is scheduled for the local
+// witness on top of the local head, and imports it. It returns nil if it's not
+// the turn of the local witness.
+func (s *service) seal() (*types.Block, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	parent := s.chain.CurrentBlock()
+	now := s.sim.clock.Now().Unix()
+
+	switch err := s.engine.CheckWitness(parent, now); err {
+	case nil:
+	case devote.ErrWaitForPrevBlock, devote.ErrMinerFutureBlock, devote.ErrInvalidBlockWitness, devote.ErrInvalidMinerBlockTime:
+		return nil, nil
+	default:
+		return nil, err
+	}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   core.CalcGasLimit(parent),
+		Time:       big.NewInt(now),
+	}
+	if err := s.engine.Prepare(s.chain, header); err != nil {
+		return nil, err
+	}
+	statedb, err := s.chain.StateAt(parent.Root())
+	if err != nil {
+		return nil, err
+	}
+	devoteDB, err := devotedb.NewDevoteByProtocol(s.chain.DevoteCache(), parent.Header().Protocol)
+	if err != nil {
+		return nil, err
+	}
+	block, err := s.engine.Finalize(s.chain, header, statedb, nil, nil, nil, devoteDB)
+	if err != nil {
+		return nil, err
+	}
+	if block, err = s.engine.Seal(s.chain, block, nil); err != nil {
+		return nil, err
+	}
+	if _, err := s.chain.InsertChain(types.Blocks{block}); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// broadcast sends a block to all peers not yet knowing about it.
+func (s *service) broadcast(block *types.Block) {
+	for _, p := range s.peers {
+		if _, known := p.known[block.Hash()]; !known {
+			p.sendBlock(block)
+		}
+	}
+}
+
+// publish broadcasts a block sealed earlier by the local witness.
+func (s *service) publish(block *types.Block) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.broadcast(block)
+}
+
+// connected reports whether the node runs the block propagation protocol with
+// the given peer.
+func (s *service) connected(id enode.ID) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, ok := s.peers[id]
+	return ok
+}
+
+// peer is a connection to a remote simulated node. Messages are queued and
+// written by a dedicated goroutine, so that handlers never block on each other.
+type peer struct {
+	id  enode.ID
+	rw  p2p.MsgReadWriter
+	sim *Simulation
+
+	known map[common.Hash]struct{} // Blocks known to the peer, protected by the service lock
+
+	queue  []queuedMsg
+	wake   chan struct{}
+	closed bool
+	lock   sync.Mutex
+	term   chan struct{}
+}
+
+type queuedMsg struct {
+	code uint64
+	data interface{}
+}
+
+func newPeer(id enode.ID, rw p2p.MsgReadWriter, sim *Simulation) *peer {
+	return &peer{
+		id:    id,
+		rw:    rw,
+		sim:   sim,
+		known: make(map[common.Hash]struct{}),
+		wake:  make(chan struct{}, 1),
+		term:  make(chan struct{}),
+	}
+}
+
+// sendBlock queues a block for the peer and marks it known.
+func (p *peer) sendBlock(block *types.Block) {
+	p.known[block.Hash()] = struct{}{}
+	p.send(blockMsg, block)
+}
+
+// send queues a message for the peer. Every queued message is tracked by the
+// simulation until the remote side handled it, or it was dropped.
+func (p *peer) send(code uint64, data interface{}) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.closed {
+		return
+	}
+	p.sim.queued()
+	p.queue = append(p.queue, queuedMsg{code, data})
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop writes the queued messages until the peer is closed.
+func (p *peer) loop() {
+	for {
+		select {
+		case <-p.wake:
+		case <-p.term:
+			return
+		}
+		for {
+			p.lock.Lock()
+			if len(p.queue) == 0 || p.closed {
+				p.lock.Unlock()
+				break
+			}
+			msg := p.queue[0]
+			p.queue = p.queue[1:]
+			p.lock.Unlock()
+
+			if err := p2p.Send(p.rw, msg.code, msg.data); err != nil {
+				// The message never arrives, the connection is going down
+				p.sim.delivered()
+				p.close()
+				return
+			}
+		}
+	}
+}
+
+// close stops the writer, dropping all messages still queued.
+func (p *peer) close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.closed {
+		return
+	}
+	p.closed = true
+	for range p.queue {
+		p.sim.delivered()
+	}
+	p.queue = nil
+	close(p.term)
+}
@@ -0,0 +1,591 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package simulation runs deterministic multi-witness devote networks on top of
+// p2p/simulations. Every node runs a devote chain with a simulated clock, all
+// nodes are registered as masternodes in genesis, and the network is driven slot
+// by slot. Faults like offline witnesses, delayed blocks and network partitions
+// can be injected between slots.
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/node"
+	"github.com/etherzero/go-etherzero/p2p/enode"
+	"github.com/etherzero/go-etherzero/p2p/simulations"
+	"github.com/etherzero/go-etherzero/p2p/simulations/adapters"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+const (
+	serviceName = "devote"
+
+	// genesisCycle is the cycle the genesis block is timestamped at the start of.
+	genesisCycle = 1000
+
+	defaultTimeout = 10 * time.Second
+)
+
+var (
+	// ErrNotSettled is returned if the messages between the nodes didn't settle
+	// within the configured timeout.
+	ErrNotSettled = errors.New("network didn't settle")
+
+	// ErrNodeOffline is returned if a fault is injected into a stopped node.
+	ErrNodeOffline = errors.New("node offline")
+)
+
+// Config are the parameters of a simulated devote network.
+type Config struct {
+	Nodes         int           // Number of nodes, all of them registered masternodes
+	SafeSize      int           // Minimum number of masternodes of an election (0 = half the nodes)
+	ConsensusSize int           // Distinct witnesses confirming a block (0 = two thirds of the nodes)
+	Timeout       time.Duration // Time the nodes are given to settle after every step (0 = 10s)
+}
+
+// Simulation is a network of devote nodes running with a shared simulated clock.
+type Simulation struct {
+	// Net is exposed as a way to access lower level functionalities of
+	// p2p/simulations.Network.
+	Net *simulations.Network
+
+	clock   *devote.SimulatedClock
+	genesis *core.Genesis
+	timeout time.Duration
+
+	nodes []*Node
+	byID  map[enode.ID]*Node
+	links map[[2]int]bool // Connections between node indices, lower index first
+
+	inflight int        // Number of messages queued but not handled yet
+	lock     sync.Mutex // Protects inflight
+}
+
+// Node is a single witness of the simulated network. Its database survives
+// restarts of the node.
+type Node struct {
+	Index   int      // Index of the node in the simulation
+	ID      enode.ID // Identifier of the node in the p2p network
+	Witness string   // Masternode identifier of the node
+
+	sim *Simulation
+	db  ethdb.Database
+
+	group    int          // Partition the node belongs to
+	delay    uint64       // Slots the node withholds its blocks for
+	withheld []withheld   // Blocks sealed but not published yet
+	service  *service     // Running service, nil while offline
+	sealed   types.Blocks // Blocks sealed by the node
+	rejects  types.Blocks // Blocks the node refused to import
+	lock     sync.Mutex
+}
+
+type withheld struct {
+	release uint64 // Slot the block is published at
+	block   *types.Block
+}
+
+// New creates a simulated devote network of fully connected nodes, each holding
+// the genesis block which registers all of them as masternodes. The clock starts
+// at the genesis time, at the beginning of a cycle.
+func New(config Config) (*Simulation, error) {
+	if config.Nodes <= 0 {
+		return nil, errors.New("no nodes to simulate")
+	}
+	if config.SafeSize == 0 {
+		config.SafeSize = (config.Nodes + 1) / 2
+	}
+	if config.ConsensusSize == 0 {
+		config.ConsensusSize = config.Nodes*2/3 + 1
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+	s := &Simulation{
+		clock:   devote.NewSimulatedClock(time.Unix(int64(genesisCycle*params.CycleInterval), 0)),
+		timeout: config.Timeout,
+		byID:    make(map[enode.ID]*Node),
+		links:   make(map[[2]int]bool),
+	}
+	// Derive the node keys from their index, so that elections are reproducible
+	confs := make([]*adapters.NodeConfig, config.Nodes)
+	witnesses := make([]string, config.Nodes)
+	for i := range confs {
+		key, err := crypto.ToECDSA(crypto.Keccak256([]byte(fmt.Sprintf("devote simulation node %d", i))))
+		if err != nil {
+			return nil, err
+		}
+		confs[i] = &adapters.NodeConfig{
+			ID:         enode.PubkeyToIDV4(&key.PublicKey),
+			PrivateKey: key,
+			Name:       fmt.Sprintf("node%02d", i),
+			Services:   []string{serviceName},
+		}
+		witnesses[i] = fmt.Sprintf("%x", crypto.FromECDSAPub(&key.PublicKey)[1:9])
+
+		n := &Node{Index: i, ID: confs[i].ID, Witness: witnesses[i], sim: s, db: ethdb.NewMemDatabase()}
+		s.nodes = append(s.nodes, n)
+		s.byID[n.ID] = n
+	}
+	chainConfig := *params.AllEthashProtocolChanges
+	chainConfig.Ethash = nil
+	chainConfig.Devote = &params.DevoteConfig{
+		Witnesses: witnesses,
+		Schedule: []*params.DevoteParams{{
+			Block:           big.NewInt(0),
+			MaxWitnessSize:  uint64(config.Nodes),
+			SafeSize:        config.SafeSize,
+			ConsensusSize:   config.ConsensusSize,
+			BlockReward:     params.DefaultDevoteParams.BlockReward,
+			CommunityReward: params.DefaultDevoteParams.CommunityReward,
+			PingTimeout:     params.DefaultDevoteParams.PingTimeout,
+		}},
+	}
+	s.genesis = &core.Genesis{
+		Config:     &chainConfig,
+		Timestamp:  uint64(s.clock.Now().Unix()),
+		GasLimit:   params.GenesisGasLimit,
+		Difficulty: big.NewInt(1),
+	}
+	for _, n := range s.nodes {
+		s.genesis.MustCommit(n.db)
+	}
+	// Boot the nodes and connect all of them with each other
+	adapter := adapters.NewSimAdapter(adapters.Services{
+		serviceName: func(ctx *adapters.ServiceContext) (node.Service, error) {
+			n := s.byID[ctx.Config.ID]
+			srv, err := newService(s, n)
+			if err != nil {
+				return nil, err
+			}
+			n.lock.Lock()
+			n.service = srv
+			n.lock.Unlock()
+			return srv, nil
+		},
+	})
+	s.Net = simulations.NewNetwork(adapter, &simulations.NetworkConfig{DefaultService: serviceName})
+	for _, conf := range confs {
+		if _, err := s.Net.NewNodeWithConfig(conf); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if err := s.Net.Start(conf.ID); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	if err := s.rewire(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close shuts down all nodes of the network.
+func (s *Simulation) Close() {
+	s.Net.Shutdown()
+}
+
+// Nodes returns all nodes of the network, in the order of their index.
+func (s *Simulation) Nodes() []*Node {
+	return s.nodes
+}
+
+// Node returns the node with the given index.
+func (s *Simulation) Node(index int) *Node {
+	return s.nodes[index]
+}
+
+// Config returns the chain configuration of the network.
+func (s *Simulation) Config() *params.ChainConfig {
+	return s.genesis.Config
+}
+
+// Clock returns the simulated clock all nodes follow.
+func (s *Simulation) Clock() *devote.SimulatedClock {
+	return s.clock
+}
+
+// Slot returns the timestamp of the current slot.
+func (s *Simulation) Slot() uint64 {
+	return uint64(s.clock.Now().Unix())
+}
+
+// GenesisCycle returns the cycle of the genesis block.
+func (s *Simulation) GenesisCycle() uint64 {
+	return genesisCycle
+}
+
+// Cycle returns the cycle of the current slot.
+func (s *Simulation) Cycle() uint64 {
+	return s.Slot() / params.CycleInterval
+}
+
+// witnesses returns the masternode identifiers of all nodes.
+func (s *Simulation) witnesses() []string {
+	witnesses := make([]string, len(s.nodes))
+	for i, n := range s.nodes {
+		witnesses[i] = n.Witness
+	}
+	return witnesses
+}
+
+// Step advances the clock to the next slot. The online witness scheduled for
+// the slot on top of its own head seals a block, withheld blocks due at the slot
+// are published, and the step completes once all messages have been handled.
+func (s *Simulation) Step() error {
+	s.clock.Run(time.Duration(params.BlockInterval) * time.Second)
+	slot := s.Slot()
+
+	for _, n := range s.nodes {
+		srv := n.running()
+		if srv == nil {
+			continue
+		}
+		block, err := srv.seal()
+		if err != nil {
+			return fmt.Errorf("node %d failed to seal slot %d: %v", n.Index, slot, err)
+		}
+		if block == nil {
+			continue
+		}
+		n.lock.Lock()
+		n.sealed = append(n.sealed, block)
+		if n.delay > 0 {
+			n.withheld = append(n.withheld, withheld{release: slot + n.delay*params.BlockInterval, block: block})
+			block = nil
+		}
+		n.lock.Unlock()
+
+		if block != nil {
+			srv.publish(block)
+		}
+	}
+	for _, n := range s.nodes {
+		srv := n.running()
+		if srv == nil {
+			continue
+		}
+		for _, block := range n.due(slot) {
+			srv.publish(block)
+		}
+	}
+	return s.settle()
+}
+
+// Run steps through the given number of slots.
+func (s *Simulation) Run(slots int) error {
+	for i := 0; i < slots; i++ {
+		if err := s.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunCycle steps until the first slot of the next cycle was sealed.
+func (s *Simulation) RunCycle() error {
+	next := s.Cycle() + 1
+	for s.Cycle() < next {
+		if err := s.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop takes a node offline. Its blocks and chain are kept, and it reconnects to
+// the network once started again.
+func (s *Simulation) Stop(index int) error {
+	n := s.nodes[index]
+	if n.running() == nil {
+		return ErrNodeOffline
+	}
+	for link := range s.links {
+		if link[0] == index || link[1] == index {
+			if err := s.disconnect(link[0], link[1]); err != nil {
+				return err
+			}
+		}
+	}
+	if err := s.Net.Stop(n.ID); err != nil {
+		return err
+	}
+	n.lock.Lock()
+	n.service = nil
+	n.withheld = nil
+	n.lock.Unlock()
+
+	return s.settle()
+}
+
+// Start brings an offline node back online and connects it to the nodes of its
+// partition, which makes it catch up with their chain.
+func (s *Simulation) Start(index int) error {
+	if err := s.Net.Start(s.nodes[index].ID); err != nil {
+		return err
+	}
+	return s.rewire()
+}
+
+// Delay makes a node withhold the blocks it seals for the given number of slots
+// before publishing them. A delay of zero publishes blocks immediately again.
+func (s *Simulation) Delay(index int, slots uint64) error {
+	n := s.nodes[index]
+	if n.running() == nil {
+		return ErrNodeOffline
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.delay = slots
+	return nil
+}
+
+// Partition splits the network into the given groups of node indices. Nodes of
+// different groups are disconnected, nodes not listed form a group of their own.
+func (s *Simulation) Partition(groups ...[]int) error {
+	for _, n := range s.nodes {
+		n.group = 0
+	}
+	for i, group := range groups {
+		for _, index := range group {
+			s.nodes[index].group = i + 1
+		}
+	}
+	return s.rewire()
+}
+
+// Heal reconnects all partitions of the network.
+func (s *Simulation) Heal() error {
+	return s.Partition()
+}
+
+// rewire connects every pair of online nodes within the same partition and
+// disconnects all others, waiting until the nodes exchanged their heads.
+func (s *Simulation) rewire() error {
+	for i, one := range s.nodes {
+		for j := i + 1; j < len(s.nodes); j++ {
+			other := s.nodes[j]
+			want := one.running() != nil && other.running() != nil && one.group == other.group
+
+			switch link := [2]int{i, j}; {
+			case want && !s.links[link]:
+				if err := s.connect(i, j); err != nil {
+					return err
+				}
+			case !want && s.links[link]:
+				if err := s.disconnect(i, j); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return s.settle()
+}
+
+// connect dials the second node from the first one and waits until both run the
+// block propagation protocol.
+func (s *Simulation) connect(i, j int) error {
+	one, other := s.nodes[i], s.nodes[j]
+	if err := s.Net.Connect(one.ID, other.ID); err != nil {
+		return err
+	}
+	err := s.waitFor(func() bool {
+		return one.running().connected(other.ID) && other.running().connected(one.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect node %d to node %d: %v", i, j, err)
+	}
+	s.links[[2]int{i, j}] = true
+	return nil
+}
+
+// disconnect drops the connection between two nodes on both sides, so that
+// neither of them dials the other again.
+func (s *Simulation) disconnect(i, j int) error {
+	one, other := s.Net.GetNode(s.nodes[i].ID), s.Net.GetNode(s.nodes[j].ID)
+	for _, pair := range [][2]*simulations.Node{{one, other}, {other, one}} {
+		client, err := pair[0].Client()
+		if err != nil {
+			return err
+		}
+		if err := client.Call(nil, "admin_removePeer", string(pair[1].Addr())); err != nil {
+			return err
+		}
+	}
+	err := s.waitFor(func() bool {
+		return !s.nodes[i].running().connected(other.ID()) && !s.nodes[j].running().connected(one.ID())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to disconnect node %d from node %d: %v", i, j, err)
+	}
+	delete(s.links, [2]int{i, j})
+	return nil
+}
+
+// queued records a message sent between two nodes.
+func (s *Simulation) queued() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.inflight++
+}
+
+// delivered records a message handled by its recipient, or dropped.
+func (s *Simulation) delivered() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.inflight--
+}
+
+// settle waits until all messages between the nodes have been handled. As the
+// handlers send their replies before a message counts as handled, no message is
+// in flight afterwards until the next step.
+func (s *Simulation) settle() error {
+	return s.waitFor(func() bool {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		return s.inflight == 0
+	})
+}
+
+// waitFor polls the condition until it holds, or the timeout expires.
+func (s *Simulation) waitFor(cond func() bool) error {
+	deadline := time.Now().Add(s.timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return ErrNotSettled
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return nil
+}
+
+// running returns the service of the node, or nil if it's offline.
+func (n *Node) running() *service {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.service
+}
+
+// due removes and returns the withheld blocks to be published at the slot.
+func (n *Node) due(slot uint64) types.Blocks {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	var blocks types.Blocks
+	pending := n.withheld[:0]
+	for _, w := range n.withheld {
+		if w.release <= slot {
+			blocks = append(blocks, w.block)
+		} else {
+			pending = append(pending, w)
+		}
+	}
+	n.withheld = pending
+	return blocks
+}
+
+// rejected records a block the node refused to import.
+func (n *Node) rejected(block *types.Block) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.rejects = append(n.rejects, block)
+}
+
+// Online reports whether the node is running.
+func (n *Node) Online() bool {
+	return n.running() != nil
+}
+
+// Chain returns the block chain of the node, or nil if it's offline.
+func (n *Node) Chain() *core.BlockChain {
+	if srv := n.running(); srv != nil {
+		return srv.chain
+	}
+	return nil
+}
+
+// Engine returns the devote engine of the node, or nil if it's offline.
+func (n *Node) Engine() *devote.Devote {
+	if srv := n.running(); srv != nil {
+		return srv.engine
+	}
+	return nil
+}
+
+// Head returns the current head of the node's chain.
+func (n *Node) Head() *types.Header {
+	return n.Chain().CurrentHeader()
+}
+
+// Confirmed returns the last block the node considers irreversible.
+func (n *Node) Confirmed() *types.Header {
+	srv := n.running()
+	return srv.engine.ConfirmedBlockHeader(srv.chain)
+}
+
+// Canonical reports whether the block is part of the node's canonical chain.
+func (n *Node) Canonical(block *types.Block) bool {
+	header := n.Chain().GetHeaderByNumber(block.NumberU64())
+	return header != nil && header.Hash() == block.Hash()
+}
+
+// CycleInfo retrieves the witness schedule and production statistics of the
+// cycle through the devote RPC API of the node.
+func (n *Node) CycleInfo(cycle uint64) (*devote.CycleInfo, error) {
+	client, err := n.sim.Net.GetNode(n.ID).Client()
+	if err != nil {
+		return nil, err
+	}
+	info := new(devote.CycleInfo)
+	if err := client.Call(info, "devote_getCycle", cycle); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Sealed returns the blocks sealed by the node so far.
+func (n *Node) Sealed() types.Blocks {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return append(types.Blocks{}, n.sealed...)
+}
+
+// Rejects returns the blocks the node refused to import.
+func (n *Node) Rejects() types.Blocks {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return append(types.Blocks{}, n.rejects...)
+}
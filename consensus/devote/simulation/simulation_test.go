@@ -0,0 +1,301 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/params"
+)
+
+func newTestSimulation(t *testing.T, nodes int) *Simulation {
+	sim, err := New(Config{Nodes: nodes})
+	if err != nil {
+		t.Fatalf("failed to create simulation: %v", err)
+	}
+	return sim
+}
+
+// assertConverged checks that all online nodes agree on the head block.
+func assertConverged(t *testing.T, sim *Simulation) {
+	t.Helper()
+
+	var want *Node
+	for _, n := range sim.Nodes() {
+		if !n.Online() {
+			continue
+		}
+		if want == nil {
+			want = n
+			continue
+		}
+		if n.Head().Hash() != want.Head().Hash() {
+			t.Fatalf("node %d head mismatch: have #%d [%x], node %d has #%d [%x]",
+				n.Index, n.Head().Number, n.Head().Hash().Bytes()[:4], want.Index, want.Head().Number, want.Head().Hash().Bytes()[:4])
+		}
+	}
+}
+
+// witnessSet returns the elected witnesses of a cycle as reported by the node,
+// sorted for comparison.
+func witnessSet(t *testing.T, n *Node, cycle uint64) []string {
+	t.Helper()
+
+	info, err := n.CycleInfo(cycle)
+	if err != nil {
+		t.Fatalf("node %d: failed to retrieve cycle %d: %v", n.Index, cycle, err)
+	}
+	witnesses := make([]string, len(info.Witnesses))
+	for i, stats := range info.Witnesses {
+		witnesses[i] = stats.Witness
+	}
+	sort.Strings(witnesses)
+	return witnesses
+}
+
+// Tests that a healthy network fills every slot, elects all masternodes in every
+// cycle and keeps confirming blocks.
+func TestHealthyNetwork(t *testing.T) {
+	sim := newTestSimulation(t, 4)
+	defer sim.Close()
+
+	start := sim.GenesisCycle()
+	if err := sim.RunCycle(); err != nil {
+		t.Fatalf("failed to run cycle: %v", err)
+	}
+	if err := sim.Run(20); err != nil {
+		t.Fatalf("failed to run slots: %v", err)
+	}
+	assertConverged(t, sim)
+
+	head := sim.Node(0).Head()
+	if slots := (sim.Slot() - start*params.CycleInterval) / params.BlockInterval; head.Number.Uint64() != slots {
+		t.Errorf("head number mismatch: have %d, want %d", head.Number, slots)
+	}
+	all := sim.witnesses()
+	sort.Strings(all)
+	for _, n := range sim.Nodes() {
+		if len(n.Sealed()) == 0 {
+			t.Errorf("node %d sealed no blocks", n.Index)
+		}
+		if len(n.Rejects()) != 0 {
+			t.Errorf("node %d rejected blocks: %v", n.Index, n.Rejects())
+		}
+		if have := witnessSet(t, n, start+1); !equalWitnesses(have, all) {
+			t.Errorf("node %d: elected witnesses mismatch: have %v, want %v", n.Index, have, all)
+		}
+		// Every slot was filled, so the block confirmed by the last round of witnesses
+		// is only a few blocks behind the head
+		confirmed := n.Confirmed().Number.Uint64()
+		if confirmed+uint64(len(all)) < head.Number.Uint64() {
+			t.Errorf("node %d confirmed block lagging: have %d, head %d", n.Index, confirmed, head.Number)
+		}
+	}
+}
+
+// Tests that a witness offline for a full cycle misses its slots, while the
+// others fill theirs, is left out of the next election and catches up once back
+// online.
+func TestOfflineWitness(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping multi-cycle simulation in short mode")
+	}
+	sim := newTestSimulation(t, 4)
+	defer sim.Close()
+
+	// Take the node offline for the whole first cycle after the genesis one. Note,
+	// uncast filters the candidates in place without truncating them, so the last
+	// masternode of the contract list would stay elected: pick an earlier one.
+	if err := sim.RunCycle(); err != nil {
+		t.Fatalf("failed to run cycle: %v", err)
+	}
+	offline := sim.Node(1)
+	if err := sim.Stop(offline.Index); err != nil {
+		t.Fatalf("failed to stop node: %v", err)
+	}
+	cycle := sim.Cycle()
+	if err := sim.RunCycle(); err != nil {
+		t.Fatalf("failed to run cycle: %v", err)
+	}
+	if err := sim.Run(10); err != nil {
+		t.Fatalf("failed to run slots: %v", err)
+	}
+	assertConverged(t, sim)
+
+	observer := sim.Node(0)
+	info, err := observer.CycleInfo(cycle)
+	if err != nil {
+		t.Fatalf("failed to retrieve cycle: %v", err)
+	}
+	for _, stats := range info.Witnesses {
+		if stats.Witness == offline.Witness {
+			if stats.Blocks != 0 || len(stats.MissedSlots) == 0 {
+				t.Errorf("offline witness stats mismatch: have %d blocks, %d missed slots", stats.Blocks, len(stats.MissedSlots))
+			}
+		} else if len(stats.MissedSlots) != 0 {
+			t.Errorf("online witness %s missed slots: %v", stats.Witness, stats.MissedSlots)
+		}
+	}
+	// Having sealed no block, the witness is uncast from the next election while
+	// remaining a masternode candidate
+	var online []string
+	for _, witness := range witnessSet(t, observer, cycle) {
+		if witness != offline.Witness {
+			online = append(online, witness)
+		}
+	}
+	for _, n := range sim.Nodes() {
+		if !n.Online() {
+			continue
+		}
+		if have := witnessSet(t, n, cycle+1); !equalWitnesses(have, online) {
+			t.Errorf("node %d: elected witnesses after offline cycle mismatch: have %v, want %v", n.Index, have, online)
+		}
+	}
+	// Once restarted, the witness must sync the chain sealed while it was away
+	if err := sim.Start(offline.Index); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	if err := sim.Run(5); err != nil {
+		t.Fatalf("failed to run slots: %v", err)
+	}
+	assertConverged(t, sim)
+
+	// Uncasting only considers the witnesses of the previous cycle, so the node is
+	// elected again in the cycle after the one it was left out of
+	if err := sim.RunCycle(); err != nil {
+		t.Fatalf("failed to run cycle: %v", err)
+	}
+	if err := sim.Run(5); err != nil {
+		t.Fatalf("failed to run slots: %v", err)
+	}
+	assertConverged(t, sim)
+
+	all := sim.witnesses()
+	sort.Strings(all)
+	for _, n := range sim.Nodes() {
+		if have := witnessSet(t, n, cycle+2); !equalWitnesses(have, all) {
+			t.Errorf("node %d: re-elected witnesses mismatch: have %v, want %v", n.Index, have, all)
+		}
+	}
+}
+
+// Tests that a block published after the next witness sealed its slot is left
+// on a side chain everywhere, including at the witness that sealed it.
+func TestDelayedBlock(t *testing.T) {
+	sim := newTestSimulation(t, 4)
+	defer sim.Close()
+
+	if err := sim.Run(10); err != nil {
+		t.Fatalf("failed to run slots: %v", err)
+	}
+	late := sim.Node(1)
+	if err := sim.Delay(late.Index, 3); err != nil {
+		t.Fatalf("failed to delay node: %v", err)
+	}
+	sealed := len(late.Sealed())
+	for len(late.Sealed()) == sealed {
+		if err := sim.Step(); err != nil {
+			t.Fatalf("failed to step: %v", err)
+		}
+	}
+	if err := sim.Delay(late.Index, 0); err != nil {
+		t.Fatalf("failed to undelay node: %v", err)
+	}
+	withheld := late.Sealed()[sealed]
+
+	if err := sim.Run(10); err != nil {
+		t.Fatalf("failed to run slots: %v", err)
+	}
+	assertConverged(t, sim)
+
+	for _, n := range sim.Nodes() {
+		if n.Canonical(withheld) {
+			t.Errorf("node %d: delayed block #%d canonical", n.Index, withheld.Number())
+		}
+		if !n.Chain().HasBlock(withheld.Hash(), withheld.NumberU64()) {
+			t.Errorf("node %d: delayed block #%d not imported as side block", n.Index, withheld.Number())
+		}
+	}
+	// Exactly the slot of the delayed block is missing from the canonical chain
+	head := sim.Node(0).Head()
+	if slots := (sim.Slot() - sim.GenesisCycle()*params.CycleInterval) / params.BlockInterval; head.Number.Uint64() != slots-1 {
+		t.Errorf("head number mismatch: have %d, want %d", head.Number, slots-1)
+	}
+}
+
+// Tests that a partitioned network can't confirm blocks on either side, and that
+// the majority chain wins once the partition heals.
+func TestPartition(t *testing.T) {
+	sim := newTestSimulation(t, 5)
+	defer sim.Close()
+
+	if err := sim.Run(20); err != nil {
+		t.Fatalf("failed to run slots: %v", err)
+	}
+	split := sim.Node(0).Head()
+
+	majority, minority := []int{0, 1, 2}, []int{3, 4}
+	if err := sim.Partition(majority, minority); err != nil {
+		t.Fatalf("failed to partition network: %v", err)
+	}
+	if err := sim.Run(30); err != nil {
+		t.Fatalf("failed to run slots: %v", err)
+	}
+	major, minor := sim.Node(majority[0]).Head(), sim.Node(minority[0]).Head()
+	if major.Hash() == minor.Hash() {
+		t.Fatalf("partitions didn't fork")
+	}
+	if major.Number.Cmp(minor.Number) <= 0 {
+		t.Fatalf("majority chain not longer: have #%d, minority #%d", major.Number, minor.Number)
+	}
+	// Neither side has enough witnesses to confirm the blocks sealed since the split
+	for _, n := range sim.Nodes() {
+		if confirmed := n.Confirmed(); confirmed.Number.Cmp(split.Number) > 0 {
+			t.Errorf("node %d confirmed block #%d past the split at #%d", n.Index, confirmed.Number, split.Number)
+		}
+	}
+	if err := sim.Heal(); err != nil {
+		t.Fatalf("failed to heal network: %v", err)
+	}
+	if err := sim.Run(10); err != nil {
+		t.Fatalf("failed to run slots: %v", err)
+	}
+	assertConverged(t, sim)
+
+	for _, n := range sim.Nodes() {
+		if header := n.Chain().GetHeaderByNumber(major.Number.Uint64()); header == nil || header.Hash() != major.Hash() {
+			t.Errorf("node %d didn't adopt the majority chain", n.Index)
+		}
+		if confirmed := n.Confirmed(); confirmed.Number.Cmp(major.Number) <= 0 {
+			t.Errorf("node %d confirmed block not progressing: have #%d, want past #%d", n.Index, confirmed.Number, major.Number)
+		}
+	}
+}
+
+func equalWitnesses(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
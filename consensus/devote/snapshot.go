@@ -113,17 +113,22 @@ func (ec *Controller) uncast(cycle uint64, nodes []string) ([]string, error) {
 }
 
 func (ec *Controller) lookup(now uint64) (witness string, err error) {
+	witnesses, err := ec.devoteDB.GetWitnesses(ec.devoteDB.GetCycle())
+	if err != nil {
+		return
+	}
+	return lookupWitness(witnesses, now)
+}
 
+// lookupWitness returns the witness scheduled for the time slot of now in the
+// given witness list of a cycle.
+func lookupWitness(witnesses []string, now uint64) (witness string, err error) {
 	offset := now % params.CycleInterval
 	if offset%params.BlockInterval != 0 {
 		err = ErrInvalidMinerBlockTime
 		return
 	}
 	offset /= params.BlockInterval
-	witnesses, err := ec.devoteDB.GetWitnesses(ec.devoteDB.GetCycle())
-	if err != nil {
-		return
-	}
 
 	witnessSize := len(witnesses)
 	if witnessSize == 0 {
@@ -41,6 +41,9 @@ contract Masternode {
     mapping(address => mapping(address => bool)) voters;
     mapping (address => vote) votes;
 
+    mapping(bytes32 => mapping(address => bool)) doubleSignReporters;
+    mapping (bytes32 => uint) doubleSignReports;
+
     event join(bytes8 id, address addr);
     event quit(bytes8 id, address addr);
     event ping(bytes8 id, uint blockOnlineAcc, uint blockLastPing);
@@ -260,4 +263,69 @@ contract Masternode {
     {
         return voters[proposalAddr][voter];
     }
+
+    // The headers are not verified on chain, they are kept in the call data for
+    // the other masternode owners to check. The masternode is removed without
+    // refunding its deposit once more than half of them reported it.
+    function reportDoubleSign(bytes8 id, bytes header1, bytes header2) public
+    {
+        bytes8 reporter = getId(msg.sender);
+        bytes32 key = keccak256(id, nodes[id].block);
+        require(has(id)
+        && bytes8(0) != reporter
+        && id != reporter
+        && keccak256(header1) != keccak256(header2)
+        && false == doubleSignReporters[key][msg.sender]);
+        doubleSignReporters[key][msg.sender] = true;
+        doubleSignReports[key] += 1;
+        if (doubleSignReports[key] > (count / 2))
+        {
+            remove(id);
+        }
+    }
+
+    function checkDoubleSignReport(bytes8 id, address reporter) constant public returns(bool)
+    {
+        return doubleSignReporters[keccak256(id, nodes[id].block)][reporter];
+    }
+
+    function remove(bytes8 id) private
+    {
+        bytes32[2] memory input;
+        bytes32[1] memory output;
+        input[0] = nodes[id].id1;
+        input[1] = nodes[id].id2;
+        assembly {
+            if iszero(call(not(0), 0x0b, 0, input, 128, output, 32)) {
+              revert(0, 0)
+            }
+        }
+        address account = address(output[0]);
+        nodeAddressToId[account] = bytes8(0);
+
+        bytes8 preId = nodes[id].preId;
+        bytes8 nextId = nodes[id].nextId;
+        if(preId != bytes8(0)){
+            nodes[preId].nextId = nextId;
+        }
+        if(nextId != bytes8(0)){
+            nodes[nextId].preId = preId;
+        }else{
+            lastId = preId;
+        }
+        address owner = nodes[id].account;
+        nodes[id] = node(
+            bytes32(0),
+            bytes32(0),
+            bytes8(0),
+            bytes8(0),
+            address(0),
+            uint(0),
+            uint(0),
+            uint(0)
+        );
+        ids[owner] = bytes8(0);
+        count -= 1;
+        emit quit(id, owner);
+    }
 }
\ No newline at end of file
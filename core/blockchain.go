@@ -55,6 +55,10 @@ var (
 	blockWriteTimer      = metrics.NewRegisteredTimer("chain/write", nil)
 
 	ErrNoGenesis = errors.New("Genesis not found in chain")
+
+	// ErrReorgBelowFinalized is returned if a chain reorganisation would drop a
+	// block that was already finalized by the consensus engine.
+	ErrReorgBelowFinalized = errors.New("reorg below finalized block")
 )
 
 const (
@@ -97,15 +101,17 @@ type BlockChain struct {
 	chainConfig *params.ChainConfig // Chain & network configuration
 	cacheConfig *CacheConfig        // Cache configuration for pruning
 
-	db     ethdb.Database // Low level persistent database to store final content in
-	triegc *prque.Prque   // Priority queue mapping block numbers to tries to gc
-	gcproc time.Duration  // Accumulates canonical block processing for trie dumping
+	db       ethdb.Database // Low level persistent database to store final content in
+	triegc   *prque.Prque   // Priority queue mapping block numbers to tries to gc
+	devotegc *prque.Prque   // Priority queue mapping block numbers to devote protocols to gc
+	gcproc   time.Duration  // Accumulates canonical block processing for trie dumping
 
 	hc            *HeaderChain
 	rmLogsFeed    event.Feed
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
+	finalizedFeed event.Feed
 	logsFeed      event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
@@ -117,13 +123,15 @@ type BlockChain struct {
 	checkpoint       int          // checkpoint counts towards the new checkpoint
 	currentBlock     atomic.Value // Current head of the block chain
 	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
+	lastFinalized    uint64       // Number of the last finalized block announced (protected by chainmu)
 
-	stateCache    state.Database // State database to reuse between imports (contains state cache)
-	bodyCache     *lru.Cache     // Cache for the most recent block bodies
-	bodyRLPCache  *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
-	receiptsCache *lru.Cache     // Cache for the most recent receipts per block
-	blockCache    *lru.Cache     // Cache for the most recent entire blocks
-	futureBlocks  *lru.Cache     // future blocks are blocks added for later processing
+	stateCache    state.Database    // State database to reuse between imports (contains state cache)
+	devoteCache   devotedb.Database // Devote trie database to reuse between imports, shared with the engine
+	bodyCache     *lru.Cache        // Cache for the most recent block bodies
+	bodyRLPCache  *lru.Cache        // Cache for the most recent block bodies in RLP encoded format
+	receiptsCache *lru.Cache        // Cache for the most recent receipts per block
+	blockCache    *lru.Cache        // Cache for the most recent entire blocks
+	futureBlocks  *lru.Cache        // future blocks are blocks added for later processing
 
 	quit    chan struct{} // blockchain quit channel
 	running int32         // running must be called atomically
@@ -163,6 +171,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		cacheConfig:    cacheConfig,
 		db:             db,
 		triegc:         prque.New(nil),
+		devotegc:       prque.New(nil),
 		stateCache:     state.NewDatabaseWithCache(db, cacheConfig.TrieCleanLimit),
 		quit:           make(chan struct{}),
 		shouldPreserve: shouldPreserve,
@@ -175,6 +184,13 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		vmConfig:       vmConfig,
 		badBlocks:      badBlocks,
 	}
+	// The devote engine looks up witnesses in the tries of recent blocks, which
+	// are only kept in memory, so the trie database must be shared with it
+	if devoteEngine, isDevote := engine.(*devote.Devote); isDevote {
+		bc.devoteCache = devoteEngine.DevoteCache()
+	} else {
+		bc.devoteCache = devotedb.NewDatabase(db)
+	}
 	bc.SetValidator(NewBlockValidator(chainConfig, bc, engine))
 	bc.SetProcessor(NewStateProcessor(chainConfig, bc, engine))
 
@@ -190,6 +206,9 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	if err := bc.loadLastState(); err != nil {
 		return nil, err
 	}
+	if finalized := bc.CurrentFinalizedHeader(); finalized != nil {
+		bc.lastFinalized = finalized.Number.Uint64()
+	}
 	// Check the current state of the block hashes and make sure that we do not have any of the bad blocks in our chain
 	for hash := range BadHashes {
 		if header := bc.GetHeaderByHash(hash); header != nil {
@@ -230,7 +249,7 @@ func (bc *BlockChain) loadLastState() error {
 		return bc.Reset()
 	}
 	// Make sure the state associated with the block is available
-	if _, err := state.New(currentBlock.Root(), bc.stateCache); err != nil {
+	if _, err := state.New(currentBlock.Root(), bc.stateCache); err != nil || !bc.hasDevoteState(currentBlock.Header()) {
 		// Dangling block without a state associated, init from scratch
 		log.Warn("Head state missing, repairing chain", "number", currentBlock.Number(), "hash", currentBlock.Hash())
 		if err := bc.repair(&currentBlock); err != nil {
@@ -405,6 +424,12 @@ func (bc *BlockChain) StateCache() state.Database {
 	return bc.stateCache
 }
 
+// DevoteCache returns the caching database of the devote tries underpinning the
+// blockchain instance.
+func (bc *BlockChain) DevoteCache() devotedb.Database {
+	return bc.devoteCache
+}
+
 // Reset purges the entire blockchain, restoring it to its genesis state.
 func (bc *BlockChain) Reset() error {
 	return bc.ResetWithGenesisBlock(bc.genesisBlock)
@@ -445,7 +470,7 @@ func (bc *BlockChain) ResetWithGenesisBlock(genesis *types.Block) error {
 func (bc *BlockChain) repair(head **types.Block) error {
 	for {
 		// Abort if we've rewound to a head block that does have associated state
-		if _, err := state.New((*head).Root(), bc.stateCache); err == nil {
+		if _, err := state.New((*head).Root(), bc.stateCache); err == nil && bc.hasDevoteState((*head).Header()) {
 			log.Info("Rewound blockchain to past state", "number", (*head).Number(), "hash", (*head).Hash())
 			return nil
 		}
@@ -587,6 +612,31 @@ func (bc *BlockChain) HasState(hash common.Hash) bool {
 	return err == nil
 }
 
+// hasDevoteState checks if the devote tries of a header are fully present in the
+// database or not.
+func (bc *BlockChain) hasDevoteState(header *types.Header) bool {
+	if header.Protocol == nil {
+		return false
+	}
+	for _, root := range header.Protocol.Roots() {
+		if _, err := bc.devoteCache.OpenTrie(root); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// commitDevoteState flushes the devote tries of a header from memory to disk.
+func (bc *BlockChain) commitDevoteState(header *types.Header, report bool) error {
+	triedb := bc.devoteCache.TrieDB()
+	for _, root := range header.Protocol.Roots() {
+		if err := triedb.Commit(root, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // HasBlockAndState checks if a block and associated state trie is fully present
 // in the database or not, caching it if present.
 func (bc *BlockChain) HasBlockAndState(hash common.Hash, number uint64) bool {
@@ -712,11 +762,20 @@ func (bc *BlockChain) Stop() {
 				if err := triedb.Commit(recent.Root(), true); err != nil {
 					log.Error("Failed to commit recent state trie", "err", err)
 				}
+				if err := bc.commitDevoteState(recent.Header(), true); err != nil {
+					log.Error("Failed to commit recent devote tries", "err", err)
+				}
 			}
 		}
 		for !bc.triegc.Empty() {
 			triedb.Dereference(bc.triegc.PopItem().(common.Hash))
 		}
+		devotetriedb := bc.devoteCache.TrieDB()
+		for !bc.devotegc.Empty() {
+			for _, root := range bc.devotegc.PopItem().(*devotedb.DevoteProtocol).Roots() {
+				devotetriedb.Dereference(root)
+			}
+		}
 		if size, _ := triedb.Size(); size != 0 {
 			log.Error("Dangling trie nodes after full cleanup")
 		}
@@ -944,26 +1003,37 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 
 	rawdb.WriteBlock(bc.db, block)
 
-	if _, err := block.DevoteDB.Commit(); err != nil {
+	protocol, err := block.DevoteDB.Commit()
+	if err != nil {
 		return NonStatTy, err
 	}
-
 	root, err := state.Commit(bc.chainConfig.IsEIP158(block.Number()))
 	if err != nil {
 		return NonStatTy, err
 	}
 	triedb := bc.stateCache.TrieDB()
+	devotetriedb := bc.devoteCache.TrieDB()
 
 	// If we're running an archive node, always flush
 	if bc.cacheConfig.Disabled {
 		if err := triedb.Commit(root, false); err != nil {
 			return NonStatTy, err
 		}
+		for _, hash := range protocol.Roots() {
+			if err := devotetriedb.Commit(hash, false); err != nil {
+				return NonStatTy, err
+			}
+		}
 	} else {
 		// Full but not archive node, do proper garbage collection
 		triedb.Reference(root, common.Hash{}) // metadata reference to keep trie alive
 		bc.triegc.Push(root, -int64(block.NumberU64()))
 
+		for _, hash := range protocol.Roots() {
+			devotetriedb.Reference(hash, common.Hash{}) // keep the devote tries alive too
+		}
+		bc.devotegc.Push(protocol, -int64(block.NumberU64()))
+
 		if current := block.NumberU64(); current > triesInMemory {
 			// If we exceeded our memory allowance, flush matured singleton nodes to disk
 			var (
@@ -973,6 +1043,9 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 			if nodes > limit || imgs > 4*1024*1024 {
 				triedb.Cap(limit - ethdb.IdealBatchSize)
 			}
+			if nodes, _ := devotetriedb.Size(); nodes > limit {
+				devotetriedb.Cap(limit - ethdb.IdealBatchSize)
+			}
 			// Find the next state trie we need to commit
 			header := bc.GetHeaderByNumber(current - triesInMemory)
 			chosen := header.Number.Uint64()
@@ -986,6 +1059,7 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 				}
 				// Flush an entire trie and restart the counters
 				triedb.Commit(header.Root, true)
+				bc.commitDevoteState(header, true)
 				lastWrite = chosen
 				bc.gcproc = 0
 			}
@@ -998,6 +1072,16 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 				}
 				triedb.Dereference(root.(common.Hash))
 			}
+			for !bc.devotegc.Empty() {
+				protocol, number := bc.devotegc.Pop()
+				if uint64(-number) > chosen {
+					bc.devotegc.Push(protocol, number)
+					break
+				}
+				for _, root := range protocol.(*devotedb.DevoteProtocol).Roots() {
+					devotetriedb.Dereference(root)
+				}
+			}
 		}
 	}
 
@@ -1023,6 +1107,11 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 			reorg = !currentPreserve && (blockPreserve || mrand.Float64() < 0.5)
 		}
 	}
+	if reorg && block.ParentHash() != currentBlock.Hash() && !bc.extendsFinalized(block) {
+		// Never give up a finalized block, keep the better chain as a side chain
+		log.Warn("Refusing reorg below finalized block", "number", block.Number(), "hash", block.Hash())
+		reorg = false
+	}
 	if reorg {
 		// Reorganise the chain if the parent is not the head block
 		if block.ParentHash() != currentBlock.Hash() {
@@ -1194,12 +1283,12 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, []
 			parent = bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
 		}
 
-		block.DevoteDB, err = devotedb.NewDevoteByProtocol(devotedb.NewDatabase(bc.db), parent.Header().Protocol)
+		block.DevoteDB, err = devotedb.NewDevoteByProtocol(bc.devoteCache, parent.Header().Protocol)
 		if err != nil {
 			return it.index, events, coalescedLogs, err
 		}
 
-		state, err := state.New(parent.Root(), bc.stateCache)
+		state, err := bc.StateAt(parent.Root())
 		if err != nil {
 			return it.index, events, coalescedLogs, err
 		}
@@ -1256,6 +1345,20 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, []
 			events = append(events, ChainEvent{block, block.Hash(), logs})
 			lastCanon = block
 
+			if finalized := bc.CurrentFinalizedHeader(); finalized != nil && finalized.Number.Uint64() > bc.lastFinalized {
+				previous := bc.GetHeaderByNumber(bc.lastFinalized)
+				bc.lastFinalized = finalized.Number.Uint64()
+				events = append(events, ChainFinalizedEvent{finalized})
+
+				// Persist the devote tries of the first finalized block of every cycle
+				// as a checkpoint, finalized blocks can't be reorged
+				if previous == nil || previous.Time.Uint64()/params.CycleInterval != finalized.Time.Uint64()/params.CycleInterval {
+					if err := bc.commitDevoteState(finalized, false); err != nil {
+						log.Error("Failed to commit finalized devote tries", "number", finalized.Number, "err", err)
+					}
+				}
+			}
+
 			// Only count canonical blocks for GC processing time
 			bc.gcproc += proctime
 
@@ -1480,6 +1583,10 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 			return fmt.Errorf("Invalid new chain")
 		}
 	}
+	// Never drop a block finalized by the consensus engine
+	if finalized := bc.CurrentFinalizedHeader(); finalized != nil && commonBlock.NumberU64() < finalized.Number.Uint64() {
+		return ErrReorgBelowFinalized
+	}
 	// Ensure the user sees large reorgs
 	if len(oldChain) > 0 && len(newChain) > 0 {
 		logFn := log.Debug
@@ -1542,6 +1649,9 @@ func (bc *BlockChain) PostChainEvents(events []interface{}, logs []*types.Log) {
 
 		case ChainSideEvent:
 			bc.chainSideFeed.Send(ev)
+
+		case ChainFinalizedEvent:
+			bc.finalizedFeed.Send(ev)
 		}
 	}
 }
@@ -1711,6 +1821,36 @@ func (bc *BlockChain) GetHeaderByNumber(number uint64) *types.Header {
 	return bc.hc.GetHeaderByNumber(number)
 }
 
+// CurrentFinalizedHeader retrieves the last block finalized by the consensus
+// engine, or nil if the engine has no notion of finality.
+func (bc *BlockChain) CurrentFinalizedHeader() *types.Header {
+	devoteEngine, isDevote := bc.engine.(*devote.Devote)
+	if !isDevote {
+		return nil
+	}
+	header := devoteEngine.ConfirmedBlockHeader(bc)
+	if header == nil || rawdb.ReadCanonicalHash(bc.db, header.Number.Uint64()) != header.Hash() {
+		return nil
+	}
+	return header
+}
+
+// extendsFinalized reports whether block descends from the last finalized
+// block, i.e. whether it can become the head without dropping finality.
+func (bc *BlockChain) extendsFinalized(block *types.Block) bool {
+	finalized := bc.CurrentFinalizedHeader()
+	if finalized == nil {
+		return true
+	}
+	number := finalized.Number.Uint64()
+
+	header := block.Header()
+	for header != nil && header.Number.Uint64() > number {
+		header = bc.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	return header != nil && header.Hash() == finalized.Hash()
+}
+
 // Config retrieves the blockchain's chain configuration.
 func (bc *BlockChain) Config() *params.ChainConfig { return bc.chainConfig }
 
@@ -1737,6 +1877,11 @@ func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Su
 	return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
 }
 
+// SubscribeChainFinalizedEvent registers a subscription of ChainFinalizedEvent.
+func (bc *BlockChain) SubscribeChainFinalizedEvent(ch chan<- ChainFinalizedEvent) event.Subscription {
+	return bc.scope.Track(bc.finalizedFeed.Subscribe(ch))
+}
+
 // SubscribeLogsEvent registers a subscription of []*types.Log.
 func (bc *BlockChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return bc.scope.Track(bc.logsFeed.Subscribe(ch))
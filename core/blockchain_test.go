@@ -26,6 +26,7 @@ import (
 
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/consensus"
+	"github.com/etherzero/go-etherzero/consensus/devote"
 	"github.com/etherzero/go-etherzero/consensus/ethash"
 	"github.com/etherzero/go-etherzero/core/rawdb"
 	"github.com/etherzero/go-etherzero/core/state"
@@ -1483,3 +1484,120 @@ func BenchmarkBlockChain_1x1000Executions(b *testing.B) {
 
 	benchmarkLargeNumberOfValueToNonexisting(b, numTxs, numBlocks, recipientFn, dataFn)
 }
+
+// Tests that reorgs dropping a block finalized by the consensus engine are
+// refused, keeping the competing chain as a side chain.
+func TestReorgBelowFinalizedBlock(t *testing.T) {
+	witnesses := []string{"0123456789abcdef"}
+
+	config := *params.TestChainConfig
+	config.Ethash = nil
+	config.Devote = &params.DevoteConfig{Witnesses: witnesses}
+
+	gendb := ethdb.NewMemDatabase()
+	gspec := &Genesis{Config: &config, Difficulty: big.NewInt(1)}
+	genesis := gspec.MustCommit(gendb)
+
+	engine := devote.NewFaker(witnesses, gendb)
+	blocks, _ := GenerateChain(&config, genesis, engine, gendb, 4, nil)
+	forks, _ := GenerateChain(&config, genesis, engine, gendb, 6, func(i int, b *BlockGen) { b.OffsetTime(1) })
+
+	diskdb := ethdb.NewMemDatabase()
+	gspec.MustCommit(diskdb)
+
+	chain, err := NewBlockChain(diskdb, nil, &config, devote.NewFaker(witnesses, diskdb), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	finalizedCh := make(chan ChainFinalizedEvent, len(blocks))
+	sub := chain.SubscribeChainFinalizedEvent(finalizedCh)
+	defer sub.Unsubscribe()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert canonical chain: %v", err)
+	}
+	finalized := chain.CurrentFinalizedHeader()
+	if finalized == nil || finalized.Number.Sign() <= 0 {
+		t.Fatalf("finalized block mismatch: have %v, want above genesis", finalized)
+	}
+	select {
+	case ev := <-finalizedCh:
+		if ev.Header.Number.Uint64() > finalized.Number.Uint64() {
+			t.Errorf("finalized event ahead of chain: have %d, want at most %d", ev.Header.Number, finalized.Number)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("no finalized event received")
+	}
+	// The heavier fork branches off below the finalized block and must not win
+	if _, err := chain.InsertChain(forks); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != blocks[len(blocks)-1].Hash() {
+		t.Errorf("head mismatch: have #%d [%x], want #%d [%x]", head.Number(), head.Hash().Bytes()[:4], blocks[len(blocks)-1].Number(), blocks[len(blocks)-1].Hash().Bytes()[:4])
+	}
+	if td, forkTd := chain.GetTdByHash(chain.CurrentBlock().Hash()), chain.GetTdByHash(forks[len(forks)-1].Hash()); forkTd == nil || forkTd.Cmp(td) <= 0 {
+		t.Errorf("fork not heavier than canonical chain: have %v, canonical %v", forkTd, td)
+	}
+	if err := chain.reorg(chain.CurrentBlock(), forks[len(forks)-1]); err != ErrReorgBelowFinalized {
+		t.Errorf("reorg error mismatch: have %v, want %v", err, ErrReorgBelowFinalized)
+	}
+}
+
+// Tests that the devote tries of recent blocks are only kept in memory, and the
+// ones of older blocks are garbage collected unless they are cycle checkpoints.
+func TestDevoteTrieGC(t *testing.T) {
+	witnesses := []string{"0123456789abcdef"}
+
+	config := *params.TestChainConfig
+	config.Ethash = nil
+	config.Devote = &params.DevoteConfig{Witnesses: witnesses}
+
+	gendb := ethdb.NewMemDatabase()
+	gspec := &Genesis{Config: &config, Difficulty: big.NewInt(1)}
+	genesis := gspec.MustCommit(gendb)
+	blocks, _ := GenerateChain(&config, genesis, devote.NewFaker(witnesses, gendb), gendb, 2*triesInMemory, nil)
+
+	diskdb := ethdb.NewMemDatabase()
+	gspec.MustCommit(diskdb)
+
+	chain, err := NewBlockChain(diskdb, nil, &config, devote.NewFaker(witnesses, diskdb), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// The stats trie changes with every block, so its root tells whether the
+	// devote tries of a block were flushed
+	persisted := 0
+	for i, block := range blocks[:len(blocks)-triesInMemory] {
+		root := block.Header().Protocol.StatsHash
+		if ok, _ := diskdb.Has(root.Bytes()); ok {
+			persisted++
+			continue
+		}
+		if _, err := chain.DevoteCache().TrieDB().Node(root); err == nil {
+			t.Errorf("block %d: stale devote trie still alive after garbage collection", i)
+		}
+	}
+	cycles := int(blocks[len(blocks)-1].Time().Uint64()/params.CycleInterval + 1)
+	if persisted == 0 || persisted > cycles {
+		t.Errorf("persisted devote checkpoints mismatch: have %d, want 1 to %d", persisted, cycles)
+	}
+	head := blocks[len(blocks)-1]
+	if ok, _ := diskdb.Has(head.Header().Protocol.StatsHash.Bytes()); ok {
+		t.Errorf("head devote trie flushed before shutdown")
+	}
+	for i, block := range blocks[len(blocks)-triesInMemory:] {
+		if !chain.hasDevoteState(block.Header()) {
+			t.Errorf("block %d: recent devote trie missing", len(blocks)-triesInMemory+i)
+		}
+	}
+	// Shutting down must persist the devote tries of the head
+	chain.Stop()
+	if ok, _ := diskdb.Has(head.Header().Protocol.StatsHash.Bytes()); !ok {
+		t.Errorf("head devote trie not flushed on shutdown")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/consensus"
 	"github.com/etherzero/go-etherzero/consensus/misc"
+	"github.com/etherzero/go-etherzero/core/rawdb"
 	"github.com/etherzero/go-etherzero/core/state"
 	"github.com/etherzero/go-etherzero/core/types"
 	"github.com/etherzero/go-etherzero/core/vm"
@@ -171,13 +172,27 @@ func (b *BlockGen) OffsetTime(seconds int64) {
 // Blocks created by GenerateChain do not contain valid proof of work
 // values. Inserting them into BlockChain requires use of FakePow or
 // a similar non-validating proof of work implementation.
+//
+// GenerateChain panics if a block can't be finalized, use GenerateChainWithError
+// to handle the failure instead.
 func GenerateChain(config *params.ChainConfig, parent *types.Block, engine consensus.Engine, db ethdb.Database, n int, gen func(int, *BlockGen)) ([]*types.Block, []types.Receipts) {
+	blocks, receipts, err := GenerateChainWithError(config, parent, engine, db, n, gen)
+	if err != nil {
+		panic(err)
+	}
+	return blocks, receipts
+}
+
+// GenerateChainWithError creates a chain of n blocks like GenerateChain, but
+// returns the error of the first block failing to be finalized or written out
+// instead of panicking.
+func GenerateChainWithError(config *params.ChainConfig, parent *types.Block, engine consensus.Engine, db ethdb.Database, n int, gen func(int, *BlockGen)) ([]*types.Block, []types.Receipts, error) {
 	if config == nil {
 		config = params.TestChainConfig
 	}
 	blocks, receipts := make(types.Blocks, n), make([]types.Receipts, n)
-	chainreader := &fakeChainReader{config: config}
-	genblock := func(i int, parent *types.Block, statedb *state.StateDB) (*types.Block, types.Receipts) {
+	chainreader := &fakeChainReader{config: config, db: db, parent: parent, blocks: blocks}
+	genblock := func(i int, parent *types.Block, statedb *state.StateDB, devoteDB *devotedb.DevoteDB) (*types.Block, types.Receipts, error) {
 		b := &BlockGen{i: i, chain: blocks, parent: parent, statedb: statedb, config: config, engine: engine}
 		b.header = makeHeader(chainreader, parent, statedb, b.engine)
 
@@ -199,32 +214,45 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		}
 		if b.engine != nil {
 			// Finalize and seal the block
-			block, _ := b.engine.Finalize(chainreader, b.header, statedb, b.txs, b.uncles, b.receipts, nil)
-
-			//devote.AccumulateRewards(params.GovernanceContractAddress, statedb, h, b.uncles)
+			block, err := b.engine.Finalize(chainreader, b.header, statedb, b.txs, b.uncles, b.receipts, devoteDB)
+			if err != nil {
+				return nil, nil, fmt.Errorf("finalize error: %v", err)
+			}
 			// Write state changes to db
 			root, err := statedb.Commit(config.IsEIP158(b.header.Number))
 			if err != nil {
-				panic(fmt.Sprintf("state write error: %v", err))
+				return nil, nil, fmt.Errorf("state write error: %v", err)
 			}
 			if err := statedb.Database().TrieDB().Commit(root, false); err != nil {
-				panic(fmt.Sprintf("trie write error: %v", err))
+				return nil, nil, fmt.Errorf("trie write error: %v", err)
+			}
+			for _, hash := range block.Header().Protocol.Roots() {
+				if err := devoteDB.Database().TrieDB().Commit(hash, false); err != nil {
+					return nil, nil, fmt.Errorf("devote trie write error: %v", err)
+				}
 			}
-			return block, b.receipts
+			return block, b.receipts, nil
 		}
-		return nil, nil
+		return nil, nil, nil
 	}
 	for i := 0; i < n; i++ {
 		statedb, err := state.New(parent.Root(), state.NewDatabase(db))
 		if err != nil {
-			panic(err)
+			return nil, nil, err
+		}
+		devoteDB, err := devotedb.NewDevoteByProtocol(devotedb.NewDatabase(db), parent.Header().Protocol)
+		if err != nil {
+			return nil, nil, err
+		}
+		block, receipt, err := genblock(i, parent, statedb, devoteDB)
+		if err != nil {
+			return nil, nil, fmt.Errorf("block %d: %v", i, err)
 		}
-		block, receipt := genblock(i, parent, statedb)
 		blocks[i] = block
 		receipts[i] = receipt
 		parent = block
 	}
-	return blocks, receipts
+	return blocks, receipts, nil
 }
 
 func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.StateDB, engine consensus.Engine) *types.Header {
@@ -245,7 +273,7 @@ func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.S
 			Difficulty: parent.Difficulty(),
 			UncleHash:  parent.UncleHash(),
 		}),
-		Protocol:   &devotedb.DevoteProtocol{},
+		Protocol:   &devotedb.DevoteProtocol{CycleHash: parent.Header().Protocol.CycleHash, StatsHash: parent.Header().Protocol.StatsHash},
 		GasLimit: CalcGasLimit(parent),
 		Number:   new(big.Int).Add(parent.Number(), common.Big1),
 		Time:     time,
@@ -270,9 +298,17 @@ func makeBlockChain(parent *types.Block, n int, engine consensus.Engine, db ethd
 	return blocks
 }
 
+// fakeChainReader serves the headers of the chain being generated: the blocks
+// generated so far, and the block they build on together with its ancestors.
+// Nothing is looked up by number in the database, whose canonical chain may be
+// a different one than the generated side chain.
 type fakeChainReader struct {
 	config  *params.ChainConfig
 	genesis *types.Block
+
+	db     ethdb.Database // Database holding the ancestors of parent, if any
+	parent *types.Block   // Block the generated chain builds on
+	blocks []*types.Block // Blocks generated so far, unset ones are nil
 }
 
 // Config returns the chain configuration.
@@ -280,8 +316,72 @@ func (cr *fakeChainReader) Config() *params.ChainConfig {
 	return cr.config
 }
 
-func (cr *fakeChainReader) CurrentHeader() *types.Header                            { return nil }
-func (cr *fakeChainReader) GetHeaderByNumber(number uint64) *types.Header           { return nil }
-func (cr *fakeChainReader) GetHeaderByHash(hash common.Hash) *types.Header          { return nil }
-func (cr *fakeChainReader) GetHeader(hash common.Hash, number uint64) *types.Header { return nil }
-func (cr *fakeChainReader) GetBlock(hash common.Hash, number uint64) *types.Block   { return nil }
+func (cr *fakeChainReader) CurrentHeader() *types.Header { return nil }
+
+// GetHeaderByNumber walks the generated chain back from its latest block to the
+// header with the given number.
+func (cr *fakeChainReader) GetHeaderByNumber(number uint64) *types.Header {
+	var header *types.Header
+	for i := len(cr.blocks) - 1; i >= 0 && header == nil; i-- {
+		if cr.blocks[i] != nil {
+			header = cr.blocks[i].Header()
+		}
+	}
+	if header == nil && cr.parent != nil {
+		header = cr.parent.Header()
+	}
+	for header != nil && header.Number.Uint64() > number {
+		header = cr.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	if header == nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return header
+}
+
+func (cr *fakeChainReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	if block := cr.generated(hash); block != nil {
+		return block.Header()
+	}
+	if cr.db == nil {
+		return nil
+	}
+	number := rawdb.ReadHeaderNumber(cr.db, hash)
+	if number == nil {
+		return nil
+	}
+	return rawdb.ReadHeader(cr.db, hash, *number)
+}
+
+func (cr *fakeChainReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if block := cr.generated(hash); block != nil {
+		return block.Header()
+	}
+	if cr.db == nil {
+		return nil
+	}
+	return rawdb.ReadHeader(cr.db, hash, number)
+}
+
+func (cr *fakeChainReader) GetBlock(hash common.Hash, number uint64) *types.Block {
+	if block := cr.generated(hash); block != nil {
+		return block
+	}
+	if cr.db == nil {
+		return nil
+	}
+	return rawdb.ReadBlock(cr.db, hash, number)
+}
+
+// generated returns the generated block or the parent block with the given hash.
+func (cr *fakeChainReader) generated(hash common.Hash) *types.Block {
+	for _, block := range cr.blocks {
+		if block != nil && block.Hash() == hash {
+			return block
+		}
+	}
+	if cr.parent != nil && cr.parent.Hash() == hash {
+		return cr.parent
+	}
+	return nil
+}
@@ -17,11 +17,17 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
+	"testing"
 
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/consensus"
 	"github.com/etherzero/go-etherzero/consensus/ethash"
+	"github.com/etherzero/go-etherzero/core/state"
 	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/devotedb"
 	"github.com/etherzero/go-etherzero/core/vm"
 	"github.com/etherzero/go-etherzero/crypto"
 	"github.com/etherzero/go-etherzero/ethdb"
@@ -98,3 +104,78 @@ func ExampleGenerateChain() {
 	// balance of addr2: 10000
 	// balance of addr3: 19687500000000001000
 }
+
+// Tests that the chain reader handed to the consensus engine while generating a
+// side chain resolves headers from that side chain, not from the canonical one
+// already stored in the database.
+func TestGenerateChainSideChainLookups(t *testing.T) {
+	var (
+		db      = ethdb.NewMemDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+	)
+	canonical, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, nil)
+
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	defer blockchain.Stop()
+
+	if _, err := blockchain.InsertChain(canonical); err != nil {
+		t.Fatalf("failed to insert canonical chain: %v", err)
+	}
+	side, _ := GenerateChain(gspec.Config, canonical[0], ethash.NewFaker(), db, 2, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(common.Address{0x01})
+	})
+	reader := &fakeChainReader{config: gspec.Config, db: db, parent: canonical[0], blocks: side}
+
+	for i, want := range []*types.Block{genesis, canonical[0], side[0], side[1]} {
+		header := reader.GetHeaderByNumber(uint64(i))
+		if header == nil || header.Hash() != want.Hash() {
+			t.Errorf("header #%d: have %v, want %x", i, header, want.Hash())
+		}
+	}
+	if header := reader.GetHeaderByNumber(4); header != nil {
+		t.Errorf("header #4: have %x, want nil", header.Hash())
+	}
+	if block := reader.GetBlock(canonical[1].Hash(), 2); block == nil {
+		t.Errorf("stored block #2 not found by hash")
+	}
+	// Before any block is generated, lookups must stop at the parent
+	reader = &fakeChainReader{config: gspec.Config, db: db, parent: canonical[0], blocks: make([]*types.Block, 2)}
+	if header := reader.GetHeaderByNumber(2); header != nil {
+		t.Errorf("header #2 served before generation: %x", header.Hash())
+	}
+}
+
+// failingEngine is a faker consensus engine failing to finalize any block.
+type failingEngine struct {
+	consensus.Engine
+}
+
+var errFinalize = errors.New("finalize failure")
+
+func (failingEngine) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction,
+	uncles []*types.Header, receipts []*types.Receipt, devoteDB *devotedb.DevoteDB) (*types.Block, error) {
+	return nil, errFinalize
+}
+
+// Tests that a block failing to be finalized is reported to the caller.
+func TestGenerateChainFinalizeError(t *testing.T) {
+	var (
+		db      = ethdb.NewMemDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, receipts, err := GenerateChainWithError(gspec.Config, genesis, failingEngine{ethash.NewFaker()}, db, 2, nil)
+	if err == nil {
+		t.Fatalf("finalize error not reported")
+	}
+	if blocks != nil || receipts != nil {
+		t.Errorf("blocks returned along the error: %v, %v", blocks, receipts)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("GenerateChain didn't panic on finalize error")
+		}
+	}()
+	GenerateChain(gspec.Config, genesis, failingEngine{ethash.NewFaker()}, db, 2, nil)
+}
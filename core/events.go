@@ -49,3 +49,7 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// ChainFinalizedEvent is posted when the consensus engine finalizes a new block,
+// after which the block can no longer be reorganised out of the canonical chain.
+type ChainFinalizedEvent struct{ Header *types.Header }
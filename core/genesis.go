@@ -235,6 +235,11 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 	}
 
 	statedb, _ := state.New(g.StateRoot, state.NewDatabase(db))
+	if g.Config != nil {
+		// The chain config is only stored along the genesis block, set the fork
+		// of the power calculation explicitly
+		statedb.SetFixedPowerBlock(g.Config.FixedPowerBlock)
+	}
 	for addr, account := range g.Alloc {
 		statedb.AddBalance(addr, account.Balance, big.NewInt(1))
 		statedb.SetCode(addr, account.Code)
@@ -272,6 +277,9 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 	}
 	statedb.Commit(false)
 	statedb.Database().TrieDB().Commit(root, true)
+	for _, hash := range protcol.Roots() {
+		devoteDB.Database().TrieDB().Commit(hash, true)
+	}
 	block := types.NewBlock(head, nil, nil, nil)
 	block.DevoteDB = devoteDB
 
@@ -282,9 +290,6 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 // The block is committed as the canonical head block.
 func (g *Genesis) Commit(db ethdb.Database) (*types.Block, error) {
 	block := g.ToBlock(db)
-
-	fmt.Printf("genesis devoteProtocol Commit begin block.DevoteProtocol :%x\n", block.DevoteDB)
-
 	if block.Number().Sign() != 0 {
 		return nil, fmt.Errorf("can't commit genesis block with number > 0")
 	}
@@ -319,7 +324,10 @@ func GenesisBlockForTesting(db ethdb.Database, addr common.Address, balance *big
 	return g.MustCommit(db)
 }
 
-func masternodeContractAccount(masternodes []string) GenesisAccount {
+// MasternodeContractAccount returns the genesis account of the masternode contract
+// with the given enode URLs registered as the initial masternodes. A non-zero
+// governance address is set as the receiver of the community rewards.
+func MasternodeContractAccount(masternodes []string, governance common.Address) GenesisAccount {
 	addresses := []common.Address{
 		common.HexToAddress("0xa534296d6039880af6f98dc29a2b753892f4df84"),
 		common.HexToAddress("0xec38fc2dd43b359ece76747ef90a244a8d9160af"),
@@ -411,6 +419,9 @@ func masternodeContractAccount(masternodes []string) GenesisAccount {
 
 	data[common.HexToHash("00")] = common.BytesToHash(lastId[:8])
 	data[common.HexToHash("01")] = common.BytesToHash(big.NewInt(count).Bytes())
+	if governance != (common.Address{}) {
+		data[common.HexToHash("06")] = governance.Hash()
+	}
 
 	return GenesisAccount{
 		Balance: big.NewInt(2),
@@ -423,7 +434,7 @@ func masternodeContractAccount(masternodes []string) GenesisAccount {
 // DefaultGenesisBlock returns the Ethereum main net genesis block.
 func DefaultGenesisBlock() *Genesis {
 	alloc := decodePrealloc(mainnetAllocData)
-	alloc[common.BytesToAddress(params.MasterndeContractAddress.Bytes())] = masternodeContractAccount(params.MainnetMasternodes)
+	alloc[common.BytesToAddress(params.MasterndeContractAddress.Bytes())] = MasternodeContractAccount(params.MainnetMasternodes, common.Address{})
 	return &Genesis{
 		Config:     params.DevoteChainConfig,
 		Nonce:      66,
@@ -437,7 +448,7 @@ func DefaultGenesisBlock() *Genesis {
 // DefaultTestnetGenesisBlock returns the Ropsten network genesis block.
 func DefaultTestnetGenesisBlock() *Genesis {
 	alloc := decodePrealloc(testnetAllocData)
-	alloc[common.BytesToAddress(params.MasterndeContractAddress.Bytes())] = masternodeContractAccount(params.TestnetMasternodes)
+	alloc[common.BytesToAddress(params.MasterndeContractAddress.Bytes())] = MasternodeContractAccount(params.TestnetMasternodes, common.Address{})
 	alloc[common.HexToAddress("0x6b7f544158e4dacf3247125a491241889829a436")] = GenesisAccount{
 		Balance: new(big.Int).Mul(big.NewInt(1e+15), big.NewInt(1e+15)),
 	}
@@ -490,7 +501,7 @@ func DeveloperGenesisBlock(period uint64, faucet common.Address) *Genesis {
 	config := *params.AllCliqueProtocolChanges
 	config.Clique.Period = period
 	alloc := decodePrealloc(testnetAllocData)
-	alloc[common.BytesToAddress(params.MasterndeContractAddress.Bytes())] = masternodeContractAccount(params.TestnetMasternodes)
+	alloc[common.BytesToAddress(params.MasterndeContractAddress.Bytes())] = MasternodeContractAccount(params.TestnetMasternodes, common.Address{})
 	// Assemble and return the genesis with the precompiles and faucet pre-funded
 	return &Genesis{
 		Config:     &config,
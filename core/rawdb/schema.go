@@ -57,7 +57,8 @@ var (
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
 
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
-	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	BloomBitsIndexPrefix  = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	MasternodeIndexPrefix = []byte("iM") // MasternodeIndexPrefix is the data table of the masternode snapshot indexer to track its progress
 
 	preimageCounter    = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
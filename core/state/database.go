@@ -18,9 +18,11 @@ package state
 
 import (
 	"fmt"
+	"math/big"
 	"sync"
 
 	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/core/rawdb"
 	"github.com/etherzero/go-etherzero/ethdb"
 	"github.com/etherzero/go-etherzero/trie"
 	lru "github.com/hashicorp/golang-lru"
@@ -57,6 +59,10 @@ type Database interface {
 
 	// TrieDB retrieves the low level trie database used for data storage.
 	TrieDB() *trie.Database
+
+	// FixedPowerBlock retrieves the fixed-point power fork block of the chain
+	// the state belongs to.
+	FixedPowerBlock() *big.Int
 }
 
 // Trie is a Ethereum Merkle Trie.
@@ -94,6 +100,9 @@ type cachingDB struct {
 	mu            sync.Mutex
 	pastTries     []*trie.SecureTrie
 	codeSizeCache *lru.Cache
+
+	fixedPowerBlock *big.Int // Fixed-point power fork block of the stored chain config
+	configLoaded    bool     // Whether the chain config was found in the database
 }
 
 // OpenTrie opens the main account trie.
@@ -165,6 +174,33 @@ func (db *cachingDB) TrieDB() *trie.Database {
 	return db.db
 }
 
+// FixedPowerBlock retrieves the fixed-point power fork block from the chain config
+// stored in the database. It is cached once the config is found, the genesis may
+// not be written yet when the database is created.
+func (db *cachingDB) FixedPowerBlock() *big.Int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if !db.configLoaded {
+		db.fixedPowerBlock, db.configLoaded = ReadFixedPowerBlock(db.db.DiskDB())
+	}
+	return db.fixedPowerBlock
+}
+
+// ReadFixedPowerBlock reads the fixed-point power fork block from the chain config
+// stored in db. The boolean is false if no chain config is stored.
+func ReadFixedPowerBlock(db rawdb.DatabaseReader) (*big.Int, bool) {
+	genesis := rawdb.ReadCanonicalHash(db, 0)
+	if genesis == (common.Hash{}) {
+		return nil, false
+	}
+	config := rawdb.ReadChainConfig(db, genesis)
+	if config == nil {
+		return nil, false
+	}
+	return config.FixedPowerBlock, true
+}
+
 // cachedTrie inserts its trie into a cachingDB on commit.
 type cachedTrie struct {
 	*trie.SecureTrie
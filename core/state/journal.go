@@ -120,6 +120,10 @@ type (
 		account       *common.Address
 		key, prevalue common.Hash
 	}
+	storageReplaceChange struct {
+		account             *common.Address
+		prevfake, prevdirty Storage
+	}
 	codeChange struct {
 		account            *common.Address
 		prevcode, prevhash []byte
@@ -228,6 +232,14 @@ func (ch storageChange) dirtied() *common.Address {
 	return ch.account
 }
 
+func (ch storageReplaceChange) revert(s *StateDB) {
+	s.getStateObject(*ch.account).setStorage(ch.prevfake, ch.prevdirty)
+}
+
+func (ch storageReplaceChange) dirtied() *common.Address {
+	return ch.account
+}
+
 func (ch refundChange) revert(s *StateDB) {
 	s.refund = ch.prev
 }
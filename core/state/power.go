@@ -1,14 +1,113 @@
 package state
 
 import (
-	"math/big"
 	"math"
+	"math/big"
+
 	"github.com/etherzero/go-etherzero/common"
 )
 
-// EXP(−1÷(etz×50)×10000)×10000000+200000
-// EXP(−1÷(etz×2)×1000)×200000+1000
+// The power of an account regenerates per block along two exponential curves
+// of its balance in units of 0.01 etz (n):
+//
+//   max   = (floor(EXP(-20000/n) * 10000000) + 200000) * 18e9
+//   speed = (floor(EXP(-50000/n) * 200000 * gap) + 1000 * gap) * 18e9
+//
+// which equals EXP(−1÷(etz×50)×10000)×10000000+200000 and
+// EXP(−1÷(etz×2)×1000)×200000+1000 for etz = n/100. The curves are evaluated in
+// fixed-point integer arithmetic so that every node computes the exact same
+// result regardless of the architecture or compiler.
+
+const expPrecision = 256 // Number of fractional bits of the fixed-point exponential
+
+var (
+	powerMinBalance = big.NewInt(1e+16) // Balance unit of the power curves, accounts below hold no power
+	powerUnit       = big.NewInt(18e+9) // Power granted per point of the curves
+
+	maxPowerExp   = big.NewInt(20000)    // Exponent numerator of the max power curve
+	maxPowerScale = big.NewInt(10000000) // Scale of the max power curve
+	maxPowerBase  = big.NewInt(200000)   // Max power granted regardless of the balance
+
+	speedExp   = big.NewInt(50000)  // Exponent numerator of the regeneration curve
+	speedScale = big.NewInt(200000) // Scale of the regeneration curve
+	speedBase  = big.NewInt(1000)   // Power regenerated per block regardless of the balance
+
+	expOne = new(big.Int).Lsh(common.Big1, expPrecision)
+)
+
+// expNeg returns EXP(-a/b) for positive a and b as a fixed-point number with
+// expPrecision fractional bits, rounded towards zero.
+func expNeg(a, b *big.Int) *big.Int {
+	// Halve the exponent until it drops below one, so the Taylor series converges
+	// without cancellation, then square the result back up.
+	squarings := uint(0)
+	for x := new(big.Int).Set(a); x.Cmp(b) >= 0; x.Rsh(x, 1) {
+		squarings++
+	}
+	y := new(big.Int).Lsh(a, expPrecision)
+	y.Div(y, new(big.Int).Lsh(b, squarings))
+
+	result := new(big.Int).Set(expOne)
+	term := new(big.Int).Set(expOne)
+	for i := int64(1); term.Sign() > 0; i++ {
+		term.Mul(term, y)
+		term.Rsh(term, expPrecision)
+		term.Div(term, big.NewInt(i))
+		if i%2 == 1 {
+			result.Sub(result, term)
+		} else {
+			result.Add(result, term)
+		}
+	}
+	for i := uint(0); i < squarings; i++ {
+		result.Mul(result, result)
+		result.Rsh(result, expPrecision)
+	}
+	return result
+}
+
+// CalculatePower returns the power of an account holding balance at newBlock, given
+// it had prevPower at prevBlock.
 func CalculatePower(prevBlock, newBlock, prevPower, balance *big.Int) *big.Int {
+	if balance.Cmp(powerMinBalance) < 0 {
+		return common.Big0
+	}
+	if prevBlock.Cmp(newBlock) >= 0 {
+		return prevPower
+	}
+	n := new(big.Int).Div(balance, powerMinBalance)
+	gap := new(big.Int).Sub(newBlock, prevBlock)
+
+	speed := new(big.Int).Mul(expNeg(speedExp, n), speedScale)
+	speed.Mul(speed, gap)
+	speed.Rsh(speed, expPrecision)
+	speed.Add(speed, new(big.Int).Mul(speedBase, gap))
+
+	power := new(big.Int).Add(prevPower, speed.Mul(speed, powerUnit))
+	if max := MaxPower(balance); power.Cmp(max) > 0 {
+		return max
+	}
+	return power
+}
+
+// MaxPower returns the upper bound of the power an account holding balance can
+// accumulate. Balances below powerMinBalance yield the base of the curve, even
+// though such accounts don't regenerate any power.
+func MaxPower(balance *big.Int) *big.Int {
+	n := new(big.Int).Div(balance, powerMinBalance)
+	if n.Sign() == 0 {
+		return new(big.Int).Mul(maxPowerBase, powerUnit)
+	}
+
+	max := new(big.Int).Mul(expNeg(maxPowerExp, n), maxPowerScale)
+	max.Rsh(max, expPrecision)
+	max.Add(max, maxPowerBase)
+	return max.Mul(max, powerUnit)
+}
+
+// calculatePowerFloat is the float64 based power calculation used before the
+// fixed-point fork. It is only kept to replay the blocks prior to the fork.
+func calculatePowerFloat(prevBlock, newBlock, prevPower, balance *big.Int) *big.Int {
 	if balance.Cmp(big.NewInt(1e+16)) < 0 {
 		return common.Big0
 	}
@@ -19,11 +118,11 @@ func CalculatePower(prevBlock, newBlock, prevPower, balance *big.Int) *big.Int {
 	etz1 := new(big.Int).Div(balance, big.NewInt(1e+16))
 	etz2 := float64(etz1.Uint64()) / 100.0
 
-	max1 := math.Exp(-1/(etz2*50)*10000) * 10000000 + 200000
+	max1 := math.Exp(-1/(etz2*50)*10000)*10000000 + 200000
 	max2 := new(big.Int).Mul(big.NewInt(int64(max1)), big.NewInt(18e+9))
 
 	blockGap := float64(new(big.Int).Sub(newBlock, prevBlock).Uint64())
-	speed := math.Exp(-1/(etz2*2)*1000) * 200000 + 1000
+	speed := math.Exp(-1/(etz2*2)*1000)*200000 + 1000
 
 	power1 := big.NewInt(int64(blockGap * speed))
 	power1.Mul(power1, big.NewInt(18e+9))
@@ -35,10 +134,37 @@ func CalculatePower(prevBlock, newBlock, prevPower, balance *big.Int) *big.Int {
 	return power2
 }
 
-func MaxPower(balance *big.Int) *big.Int {
-	etz1 := new(big.Int).Div(balance, big.NewInt(1e+16))
-	etz2 := float64(etz1.Uint64()) / 100.0
-	max := math.Exp(-1/(etz2*50)*10000) * 10000000 + 200000
-	return new(big.Int).Mul(big.NewInt(int64(max)), big.NewInt(18e+9))
+// PowerRate returns the amount of power an account holding balance regenerates
+// per block until it reaches MaxPower.
+func PowerRate(balance *big.Int) *big.Int {
+	if balance.Cmp(powerMinBalance) < 0 {
+		return common.Big0
+	}
+	return CalculatePower(common.Big0, common.Big1, common.Big0, balance)
 }
 
+// PowerForecast returns the number of blocks an account holding balance needs
+// to regenerate from power to at least required. The boolean is false if the
+// required power exceeds the maximum power of the balance.
+func PowerForecast(power, required, balance *big.Int) (uint64, bool) {
+	if power.Cmp(required) >= 0 {
+		return 0, true
+	}
+	if MaxPower(balance).Cmp(required) < 0 {
+		return 0, false
+	}
+	rate := PowerRate(balance)
+	if rate.Sign() <= 0 {
+		return 0, false
+	}
+	// Estimate from the linear rate, then step over any rounding of the curve
+	deficit := new(big.Int).Sub(required, power)
+	blocks := new(big.Int).Div(new(big.Int).Add(deficit, new(big.Int).Sub(rate, common.Big1)), rate).Uint64()
+	for blocks > 0 && CalculatePower(common.Big0, new(big.Int).SetUint64(blocks-1), power, balance).Cmp(required) >= 0 {
+		blocks--
+	}
+	for CalculatePower(common.Big0, new(big.Int).SetUint64(blocks), power, balance).Cmp(required) < 0 {
+		blocks++
+	}
+	return blocks, true
+}
@@ -0,0 +1,211 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/core/rawdb"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// Tests that the fixed-point power calculation reproduces the float based one for
+// every balance up to 200 etz, and for balances spread over the whole range the
+// float based calculation supports beyond, both for the maximum and the
+// regenerated power.
+func TestPowerFixedPointEquivalence(t *testing.T) {
+	limit := uint64(20000)
+	if testing.Short() {
+		limit = 2000
+	}
+	for n := uint64(1); n <= limit; n++ {
+		testPowerEquivalence(t, n)
+	}
+	// Step through the larger balances geometrically, with a few units of jitter
+	// to hit balances that aren't round numbers of etz
+	jitter := rand.New(rand.NewSource(1))
+	for n := float64(limit); n < math.MaxUint64/2; n *= 1.01 {
+		testPowerEquivalence(t, uint64(n)+uint64(jitter.Intn(100)))
+	}
+	for _, n := range []uint64{1e8, 1e9, 1e10, 1e11, 1e12, math.MaxInt64, math.MaxUint64 - 1, math.MaxUint64} {
+		testPowerEquivalence(t, n)
+	}
+}
+
+// testPowerEquivalence checks the fixed-point power calculation against the float
+// based one for a balance of n power units.
+func testPowerEquivalence(t *testing.T, n uint64) {
+	var (
+		zero  = new(big.Int)
+		gaps  = []int64{1, 2, 3, 10, 21, 600, 3600}
+		start = big.NewInt(1000)
+	)
+	balance := new(big.Int).Mul(new(big.Int).SetUint64(n), powerMinBalance)
+
+	// The maximum is reached by regenerating from zero for long enough
+	end := big.NewInt(1 << 40)
+	if want, have := calculatePowerFloat(start, end, zero, balance), CalculatePower(start, end, zero, balance); want.Cmp(have) != 0 {
+		t.Fatalf("balance %v: max power mismatch: have %v, want %v", balance, have, want)
+	}
+	if want, have := calculatePowerFloat(start, end, zero, balance), MaxPower(balance); want.Cmp(have) != 0 {
+		t.Fatalf("balance %v: MaxPower mismatch: have %v, want %v", balance, have, want)
+	}
+	for _, gap := range gaps {
+		end := new(big.Int).Add(start, big.NewInt(gap))
+		if want, have := calculatePowerFloat(start, end, zero, balance), CalculatePower(start, end, zero, balance); want.Cmp(have) != 0 {
+			t.Fatalf("balance %v, gap %d: power mismatch: have %v, want %v", balance, gap, have, want)
+		}
+	}
+}
+
+// Tests that the state keeps calculating power with floats before the fork block
+// and switches to the fixed-point calculation afterwards.
+func TestPowerFixedPointFork(t *testing.T) {
+	var (
+		addr = common.BytesToAddress([]byte("power"))
+		prev = big.NewInt(100)
+		fork = big.NewInt(200)
+	)
+	// Balances beyond 2^64 units overflow the float based calculation
+	balance := new(big.Int).Lsh(powerMinBalance, 64)
+	balance.Add(balance, powerMinBalance)
+
+	before := new(big.Int).Sub(fork, common.Big1)
+	legacy := calculatePowerFloat(prev, before, common.Big0, balance)
+	if legacy.Cmp(CalculatePower(prev, before, common.Big0, balance)) == 0 {
+		t.Fatalf("float and fixed-point power unexpectedly equal: %v", legacy)
+	}
+	statedb, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+	statedb.SetFixedPowerBlock(fork)
+	statedb.SetBalance(addr, balance, prev)
+
+	if have := statedb.GetPower(addr, before); have.Cmp(legacy) != 0 {
+		t.Errorf("pre-fork power mismatch: have %v, want %v", have, legacy)
+	}
+	if want, have := CalculatePower(prev, fork, common.Big0, balance), statedb.GetPower(addr, fork); want.Cmp(have) != 0 {
+		t.Errorf("post-fork power mismatch: have %v, want %v", have, want)
+	}
+	// Copies of the state must retain the fork
+	if want, have := CalculatePower(prev, fork, common.Big0, balance), statedb.Copy().GetPower(addr, fork); want.Cmp(have) != 0 {
+		t.Errorf("copied post-fork power mismatch: have %v, want %v", have, want)
+	}
+}
+
+// Tests that the state takes the fork block from the chain config stored in its
+// database, once the genesis was written.
+func TestPowerFixedPointForkFromConfig(t *testing.T) {
+	var (
+		db      = ethdb.NewMemDatabase()
+		genesis = common.HexToHash("0x01")
+		fork    = big.NewInt(200)
+	)
+	sdb := NewDatabase(db)
+	if statedb, _ := New(common.Hash{}, sdb); statedb.fixedPowerBlock != nil {
+		t.Fatalf("fork set without chain config: %v", statedb.fixedPowerBlock)
+	}
+	rawdb.WriteCanonicalHash(db, genesis, 0)
+	rawdb.WriteChainConfig(db, genesis, &params.ChainConfig{FixedPowerBlock: fork})
+
+	for i, sdb := range []Database{sdb, NewDatabase(db)} {
+		statedb, _ := New(common.Hash{}, sdb)
+		if statedb.fixedPowerBlock == nil || statedb.fixedPowerBlock.Cmp(fork) != 0 {
+			t.Errorf("database %d: fork block mismatch: have %v, want %v", i, statedb.fixedPowerBlock, fork)
+		}
+	}
+}
+
+// Tests that balances beyond the range of the float based calculation still
+// yield a power within the bounds of the curves.
+func TestPowerLargeBalance(t *testing.T) {
+	balance := new(big.Int).Exp(big.NewInt(10), big.NewInt(40), nil)
+	ceiling := new(big.Int).Mul(new(big.Int).Add(maxPowerScale, maxPowerBase), powerUnit)
+
+	max := MaxPower(balance)
+	if max.Cmp(ceiling) >= 0 || max.Cmp(new(big.Int).Mul(maxPowerBase, powerUnit)) <= 0 {
+		t.Fatalf("max power out of bounds: %v", max)
+	}
+	if power := CalculatePower(common.Big0, big.NewInt(1<<62), common.Big0, balance); power.Cmp(max) != 0 {
+		t.Fatalf("power not capped at the maximum: have %v, want %v", power, max)
+	}
+}
+
+// Tests that balances below the power unit keep the base of the maximum power
+// curve, but don't regenerate anything.
+func TestPowerSmallBalance(t *testing.T) {
+	base := new(big.Int).Mul(maxPowerBase, powerUnit)
+	for _, balance := range []*big.Int{common.Big0, common.Big1, new(big.Int).Sub(powerMinBalance, common.Big1)} {
+		if max := MaxPower(balance); max.Cmp(base) != 0 {
+			t.Errorf("balance %v: max power mismatch: have %v, want %v", balance, max, base)
+		}
+		if rate := PowerRate(balance); rate.Sign() != 0 {
+			t.Errorf("balance %v: rate mismatch: have %v, want 0", balance, rate)
+		}
+	}
+	if rate := PowerRate(powerMinBalance); rate.Sign() <= 0 {
+		t.Errorf("minimum balance: rate not positive: %v", rate)
+	}
+}
+
+// Tests that power forecasts report the first block at which the required power
+// is regenerated, and refuse requirements which can never be met.
+func TestPowerForecast(t *testing.T) {
+	etz := new(big.Int).Mul(powerMinBalance, big.NewInt(100))
+	tests := []struct {
+		power, required, balance *big.Int
+		reachable                bool
+	}{
+		// Enough power already, no matter the balance
+		{big.NewInt(21000), big.NewInt(21000), common.Big0, true},
+		{MaxPower(etz), big.NewInt(1), etz, true},
+		// Regeneration over one or many blocks
+		{common.Big0, big.NewInt(1), etz, true},
+		{common.Big0, PowerRate(etz), etz, true},
+		{common.Big0, new(big.Int).Add(PowerRate(etz), common.Big1), etz, true},
+		{big.NewInt(12345), MaxPower(etz), etz, true},
+		{common.Big0, MaxPower(powerMinBalance), powerMinBalance, true},
+		// Beyond the maximum power, or without any regeneration
+		{common.Big0, new(big.Int).Add(MaxPower(etz), common.Big1), etz, false},
+		{common.Big0, big.NewInt(1), new(big.Int).Sub(powerMinBalance, common.Big1), false},
+	}
+	for i, tt := range tests {
+		blocks, ok := PowerForecast(tt.power, tt.required, tt.balance)
+		if ok != tt.reachable {
+			t.Errorf("test %d: reachability mismatch: have %v, want %v", i, ok, tt.reachable)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if have := CalculatePower(common.Big0, new(big.Int).SetUint64(blocks), tt.power, tt.balance); have.Cmp(tt.required) < 0 && blocks > 0 {
+			t.Errorf("test %d: power after %d blocks below requirement: have %v, want %v", i, blocks, have, tt.required)
+		}
+		if blocks == 0 {
+			if tt.power.Cmp(tt.required) < 0 {
+				t.Errorf("test %d: no blocks forecast for missing power", i)
+			}
+			continue
+		}
+		if prev := CalculatePower(common.Big0, new(big.Int).SetUint64(blocks-1), tt.power, tt.balance); prev.Cmp(tt.required) >= 0 {
+			t.Errorf("test %d: forecast of %d blocks not minimal", i, blocks)
+		}
+	}
+}
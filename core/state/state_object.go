@@ -79,6 +79,7 @@ type stateObject struct {
 
 	originStorage Storage // Storage cache of original entries to dedup rewrites
 	dirtyStorage  Storage // Storage entries that need to be flushed to disk
+	fakeStorage   Storage // Storage replacing the trie entries when simulating calls
 
 	// Cache flags.
 	// When an object is marked suicided it will be delete from the trie
@@ -180,6 +181,10 @@ func (self *stateObject) GetState(db Database, key common.Hash) common.Hash {
 
 // GetCommittedState retrieves a value from the committed account storage trie.
 func (self *stateObject) GetCommittedState(db Database, key common.Hash) common.Hash {
+	// If the storage was replaced, the trie is ignored altogether
+	if self.fakeStorage != nil {
+		return self.fakeStorage[key]
+	}
 	// If we have the original value cached, return that
 	value, cached := self.originStorage[key]
 	if cached {
@@ -222,6 +227,27 @@ func (self *stateObject) setState(key, value common.Hash) {
 	self.dirtyStorage[key] = value
 }
 
+// SetStorage replaces the entire storage of the account with the given entries,
+// any other slot reads as empty. The replacement is never written into the
+// storage trie, it's only meant for simulating calls.
+func (self *stateObject) SetStorage(storage map[common.Hash]common.Hash) {
+	self.db.journal.append(storageReplaceChange{
+		account:   &self.address,
+		prevfake:  self.fakeStorage,
+		prevdirty: self.dirtyStorage,
+	})
+	fake := make(Storage, len(storage))
+	for key, value := range storage {
+		fake[key] = value
+	}
+	self.setStorage(fake, make(Storage))
+}
+
+func (self *stateObject) setStorage(fake, dirty Storage) {
+	self.fakeStorage = fake
+	self.dirtyStorage = dirty
+}
+
 // updateTrie writes cached storage modifications into the object's storage trie.
 func (self *stateObject) updateTrie(db Database) Trie {
 	tr := self.getTrie(db)
@@ -335,7 +361,7 @@ func (self *stateObject) SetPower(amount *big.Int) {
 func (self *stateObject) UpdatePower(blockNumber *big.Int) {
 	prevpower := self.data.Power
 	prevblock := self.data.BlockNumber
-	power := CalculatePower(prevblock, blockNumber, prevpower, self.data.Balance)
+	power := self.db.CalculatePower(prevblock, blockNumber, prevpower, self.data.Balance)
 	self.db.journal.append(blockChange{
 		account:   &self.address,
 		prevpower: prevpower,
@@ -355,6 +381,9 @@ func (self *stateObject) deepCopy(db *StateDB) *stateObject {
 	stateObject.code = self.code
 	stateObject.dirtyStorage = self.dirtyStorage.Copy()
 	stateObject.originStorage = self.originStorage.Copy()
+	if self.fakeStorage != nil {
+		stateObject.fakeStorage = self.fakeStorage.Copy()
+	}
 	stateObject.suicided = self.suicided
 	stateObject.dirtyCode = self.dirtyCode
 	stateObject.deleted = self.deleted
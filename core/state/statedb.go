@@ -86,6 +86,10 @@ type StateDB struct {
 	journal        *journal
 	validRevisions []revision
 	nextRevisionId int
+
+	// Block from which on power is calculated in fixed-point arithmetic, nil if
+	// the float based calculation is still in effect.
+	fixedPowerBlock *big.Int
 }
 
 // Create a new state from a given trie.
@@ -102,6 +106,7 @@ func New(root common.Hash, db Database) (*StateDB, error) {
 		logs:              make(map[common.Hash][]*types.Log),
 		preimages:         make(map[common.Hash][]byte),
 		journal:           newJournal(),
+		fixedPowerBlock:   db.FixedPowerBlock(),
 	}, nil
 }
 
@@ -215,11 +220,28 @@ func (self *StateDB) GetBalance(addr common.Address) *big.Int {
 func (self *StateDB) GetPower(addr common.Address, blockNumber *big.Int) *big.Int {
 	stateObject := self.getStateObject(addr)
 	if stateObject != nil {
-		return CalculatePower(stateObject.BlockNumber(), blockNumber, stateObject.Power(), stateObject.Balance())
+		return self.CalculatePower(stateObject.BlockNumber(), blockNumber, stateObject.Power(), stateObject.Balance())
 	}
 	return common.Big0
 }
 
+// SetFixedPowerBlock sets the block from which on power is calculated in fixed-point
+// arithmetic instead of the legacy float based calculation. Nil disables the fork.
+// The block defaults to the one of the chain config stored in the state database,
+// overriding it is only meant for states without a chain.
+func (self *StateDB) SetFixedPowerBlock(number *big.Int) {
+	self.fixedPowerBlock = number
+}
+
+// CalculatePower calculates the power at newBlock of an account holding balance,
+// given it had prevPower at prevBlock, with the rules in effect at newBlock.
+func (self *StateDB) CalculatePower(prevBlock, newBlock, prevPower, balance *big.Int) *big.Int {
+	if self.fixedPowerBlock == nil || newBlock.Cmp(self.fixedPowerBlock) < 0 {
+		return calculatePowerFloat(prevBlock, newBlock, prevPower, balance)
+	}
+	return CalculatePower(prevBlock, newBlock, prevPower, balance)
+}
+
 func (self *StateDB) GetNonce(addr common.Address) uint64 {
 	stateObject := self.getStateObject(addr)
 	if stateObject != nil {
@@ -396,6 +418,16 @@ func (self *StateDB) SetState(addr common.Address, key, value common.Hash) {
 	}
 }
 
+// SetStorage replaces the entire storage of the given account, slots missing
+// from storage read as empty. It's only meant for simulating calls, the state
+// must not be committed afterwards.
+func (self *StateDB) SetStorage(addr common.Address, storage map[common.Hash]common.Hash) {
+	stateObject := self.GetOrNewStateObject(addr)
+	if stateObject != nil {
+		stateObject.SetStorage(storage)
+	}
+}
+
 // Suicide marks the given account as suicided.
 // This clears the account balance.
 //
@@ -540,6 +572,7 @@ func (self *StateDB) Copy() *StateDB {
 		logSize:           self.logSize,
 		preimages:         make(map[common.Hash][]byte),
 		journal:           newJournal(),
+		fixedPowerBlock:   self.fixedPowerBlock,
 	}
 	// Copy the dirty states, logs, and preimages
 	for addr := range self.journal.dirties {
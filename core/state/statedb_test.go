@@ -45,7 +45,7 @@ func TestUpdateLeaks(t *testing.T) {
 	// Update it with some accounts
 	for i := byte(0); i < 255; i++ {
 		addr := common.BytesToAddress([]byte{i})
-		state.AddBalance(addr, big.NewInt(int64(11*i)))
+		state.AddBalance(addr, big.NewInt(int64(11*i)), common.Big0)
 		state.SetNonce(addr, uint64(42*i))
 		if i%2 == 0 {
 			state.SetState(addr, common.BytesToHash([]byte{i, i, i}), common.BytesToHash([]byte{i, i, i, i}))
@@ -72,7 +72,7 @@ func TestIntermediateLeaks(t *testing.T) {
 	finalState, _ := New(common.Hash{}, NewDatabase(finalDb))
 
 	modify := func(state *StateDB, addr common.Address, i, tweak byte) {
-		state.SetBalance(addr, big.NewInt(int64(11*i)+int64(tweak)))
+		state.SetBalance(addr, big.NewInt(int64(11*i)+int64(tweak)), common.Big0)
 		state.SetNonce(addr, uint64(42*i+tweak))
 		if i%2 == 0 {
 			state.SetState(addr, common.Hash{i, i, i, 0}, common.Hash{})
@@ -126,7 +126,7 @@ func TestCopy(t *testing.T) {
 
 	for i := byte(0); i < 255; i++ {
 		obj := orig.GetOrNewStateObject(common.BytesToAddress([]byte{i}))
-		obj.AddBalance(big.NewInt(int64(i)))
+		obj.AddBalance(big.NewInt(int64(i)), common.Big0)
 		orig.updateStateObject(obj)
 	}
 	orig.Finalise(false)
@@ -138,8 +138,8 @@ func TestCopy(t *testing.T) {
 		origObj := orig.GetOrNewStateObject(common.BytesToAddress([]byte{i}))
 		copyObj := copy.GetOrNewStateObject(common.BytesToAddress([]byte{i}))
 
-		origObj.AddBalance(big.NewInt(2 * int64(i)))
-		copyObj.AddBalance(big.NewInt(3 * int64(i)))
+		origObj.AddBalance(big.NewInt(2*int64(i)), common.Big0)
+		copyObj.AddBalance(big.NewInt(3*int64(i)), common.Big0)
 
 		orig.updateStateObject(origObj)
 		copy.updateStateObject(copyObj)
@@ -209,14 +209,14 @@ func newTestAction(addr common.Address, r *rand.Rand) testAction {
 		{
 			name: "SetBalance",
 			fn: func(a testAction, s *StateDB) {
-				s.SetBalance(addr, big.NewInt(a.args[0]))
+				s.SetBalance(addr, big.NewInt(a.args[0]), common.Big0)
 			},
 			args: make([]int64, 1),
 		},
 		{
 			name: "AddBalance",
 			fn: func(a testAction, s *StateDB) {
-				s.AddBalance(addr, big.NewInt(a.args[0]))
+				s.AddBalance(addr, big.NewInt(a.args[0]), common.Big0)
 			},
 			args: make([]int64, 1),
 		},
@@ -410,7 +410,7 @@ func (s *StateSuite) TestTouchDelete(c *check.C) {
 	s.state.Reset(root)
 
 	snapshot := s.state.Snapshot()
-	s.state.AddBalance(common.Address{}, new(big.Int))
+	s.state.AddBalance(common.Address{}, new(big.Int), common.Big0)
 
 	if len(s.state.journal.dirties) != 1 {
 		c.Fatal("expected one dirty state object")
@@ -426,7 +426,7 @@ func (s *StateSuite) TestTouchDelete(c *check.C) {
 func TestCopyOfCopy(t *testing.T) {
 	sdb, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
 	addr := common.HexToAddress("aaaa")
-	sdb.SetBalance(addr, big.NewInt(42))
+	sdb.SetBalance(addr, big.NewInt(42), common.Big0)
 
 	if got := sdb.Copy().GetBalance(addr).Uint64(); got != 42 {
 		t.Fatalf("1st copy fail, expected 42, got %v", got)
@@ -435,3 +435,39 @@ func TestCopyOfCopy(t *testing.T) {
 		t.Fatalf("2nd copy fail, expected 42, got %v", got)
 	}
 }
+
+// Tests that replacing the storage of an account hides all its committed slots,
+// while writes and reverts on top of the replacement still work.
+func TestSetStorage(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+
+	addr := common.Address{0x01}
+	kept, dropped := common.Hash{0x01}, common.Hash{0x02}
+
+	state.SetState(addr, kept, common.Hash{0xaa})
+	state.SetState(addr, dropped, common.Hash{0xbb})
+	root, _ := state.Commit(false)
+	state, _ = New(root, state.Database())
+
+	state.SetStorage(addr, map[common.Hash]common.Hash{kept: {0xcc}})
+	if have := state.GetState(addr, kept); have != (common.Hash{0xcc}) {
+		t.Errorf("replaced slot mismatch: have %x, want %x", have, common.Hash{0xcc})
+	}
+	if have := state.GetState(addr, dropped); have != (common.Hash{}) {
+		t.Errorf("slot missing from the replacement not empty: have %x", have)
+	}
+	// Writes on top of the replaced storage must be revertible
+	snapshot := state.Snapshot()
+	state.SetState(addr, kept, common.Hash{0xdd})
+	if have := state.GetState(addr, kept); have != (common.Hash{0xdd}) {
+		t.Errorf("written slot mismatch: have %x, want %x", have, common.Hash{0xdd})
+	}
+	state.RevertToSnapshot(snapshot)
+	if have := state.GetState(addr, kept); have != (common.Hash{0xcc}) {
+		t.Errorf("reverted slot mismatch: have %x, want %x", have, common.Hash{0xcc})
+	}
+	// Copies must keep the replacement
+	if have := state.Copy().GetState(addr, dropped); have != (common.Hash{}) {
+		t.Errorf("copied slot missing from the replacement not empty: have %x", have)
+	}
+}
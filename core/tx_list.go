@@ -169,20 +169,30 @@ func (m *txSortedMap) Remove(nonce uint64) bool {
 }
 
 // Ready retrieves a sequentially increasing list of transactions starting at the
-// provided nonce that is ready for processing. The returned transactions will be
-// removed from the list.
+// provided nonce that is ready for processing. The sequence stops at the first
+// transaction the power left over by its predecessors can't pay for. The returned
+// transactions will be removed from the list.
 //
 // Note, all transactions with nonces lower than start will also be returned to
 // prevent getting into and invalid state. This is not something that should ever
 // happen but better to be self correcting than failing!
-func (m *txSortedMap) Ready(start uint64) types.Transactions {
+func (m *txSortedMap) Ready(start uint64, power *big.Int) types.Transactions {
 	// Short circuit if no transactions are available
 	if m.index.Len() == 0 || (*m.index)[0] > start {
 		return nil
 	}
 	// Otherwise start accumulating incremental transactions
-	var ready types.Transactions
+	var (
+		ready  types.Transactions
+		budget = new(big.Int).Set(power)
+	)
 	for next := (*m.index)[0]; m.index.Len() > 0 && (*m.index)[0] == next; next++ {
+		cost := m.items[next].Cost()
+		if cost.Cmp(budget) > 0 {
+			break
+		}
+		budget.Sub(budget, cost)
+
 		ready = append(ready, m.items[next])
 		delete(m.items, next)
 		heap.Pop(m.index)
@@ -343,14 +353,38 @@ func (l *txList) Remove(tx *types.Transaction) (bool, types.Transactions) {
 }
 
 // Ready retrieves a sequentially increasing list of transactions starting at the
-// provided nonce that is ready for processing. The returned transactions will be
-// removed from the list.
+// provided nonce that is ready for processing, stopping at the first transaction
+// the power left over by its predecessors can't pay for. The returned transactions
+// will be removed from the list.
 //
 // Note, all transactions with nonces lower than start will also be returned to
 // prevent getting into and invalid state. This is not something that should ever
 // happen but better to be self correcting than failing!
-func (l *txList) Ready(start uint64) types.Transactions {
-	return l.txs.Ready(start)
+func (l *txList) Ready(start uint64, power *big.Int) types.Transactions {
+	return l.txs.Ready(start, power)
+}
+
+// Overspent removes all transactions from the first one, in nonce order, that
+// together with its predecessors costs more than the given power. Every removed
+// transaction is returned for any post-removal maintenance.
+func (l *txList) Overspent(power *big.Int) types.Transactions {
+	spent := new(big.Int)
+	for _, tx := range l.txs.Flatten() {
+		if spent.Add(spent, tx.Cost()).Cmp(power) > 0 {
+			nonce := tx.Nonce()
+			return l.txs.Filter(func(tx *types.Transaction) bool { return tx.Nonce() >= nonce })
+		}
+	}
+	return nil
+}
+
+// Cost returns the total power the transactions in the list pay for gas.
+func (l *txList) Cost() *big.Int {
+	cost := new(big.Int)
+	for _, tx := range l.txs.items {
+		cost.Add(cost, tx.Cost())
+	}
+	return cost
 }
 
 // Len returns the length of the transaction list.
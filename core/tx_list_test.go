@@ -17,6 +17,7 @@
 package core
 
 import (
+	"math/big"
 	"math/rand"
 	"testing"
 
@@ -49,3 +50,42 @@ func TestStrictTxListAdd(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the power of an account is spent in nonce order, holding back every
+// transaction its predecessors left too little power for.
+func TestTxListPowerBudget(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	// Every transaction costs 100 power
+	txs := make(types.Transactions, 4)
+	for i := 0; i < len(txs); i++ {
+		txs[i] = transaction(uint64(i), 100, key)
+	}
+	list := newTxList(true)
+	for _, tx := range txs {
+		list.Add(tx, DefaultTxPoolConfig.PriceBump)
+	}
+	if cost := list.Cost(); cost.Cmp(big.NewInt(400)) != 0 {
+		t.Fatalf("list cost mismatch: have %v, want %v", cost, 400)
+	}
+	// Enough power for each transaction alone, but only for two in a row
+	if overspent := list.Overspent(big.NewInt(250)); len(overspent) != 2 || overspent[0].Nonce() < 2 || overspent[1].Nonce() < 2 {
+		t.Fatalf("overspent transactions mismatch: have %v, want nonces 2 and 3", overspent)
+	}
+	if list.Len() != 2 {
+		t.Fatalf("list length mismatch: have %d, want %d", list.Len(), 2)
+	}
+	for _, tx := range txs[2:] {
+		list.Add(tx, DefaultTxPoolConfig.PriceBump)
+	}
+	ready := list.Ready(0, big.NewInt(299))
+	if len(ready) != 2 || ready[0].Nonce() != 0 || ready[1].Nonce() != 1 {
+		t.Fatalf("ready transactions mismatch: have %v, want nonces 0 and 1", ready)
+	}
+	if ready = list.Ready(2, big.NewInt(99)); len(ready) != 0 {
+		t.Fatalf("underpowered transactions ready: %v", ready)
+	}
+	if ready = list.Ready(2, big.NewInt(200)); len(ready) != 2 {
+		t.Fatalf("ready transactions mismatch: have %v, want nonces 2 and 3", ready)
+	}
+}
@@ -541,6 +541,75 @@ func (pool *TxPool) Pending() (map[common.Address]types.Transactions, error) {
 	return pending, nil
 }
 
+// Waiting retrieves the queued transactions held back until their sender has
+// regenerated enough power, mapped to the block number they are predicted to
+// become includable at.
+func (pool *TxPool) Waiting() map[common.Hash]uint64 {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	number := pool.chain.CurrentBlock().Number()
+
+	waiting := make(map[common.Hash]uint64)
+	for addr, list := range pool.queue {
+		// Transactions pay in nonce order, each spending the power left over by
+		// its predecessors and what regenerated while waiting for them. Nothing
+		// after a nonce gap becomes executable, whatever the power.
+		var (
+			balance = pool.currentState.GetBalance(addr)
+			power   = pool.unspentPower(addr)
+			nonce   = pool.pendingState.GetNonce(addr)
+			blocks  uint64
+		)
+		for _, tx := range list.Flatten() {
+			if tx.Nonce() < nonce {
+				continue
+			}
+			if tx.Nonce() > nonce {
+				break
+			}
+			nonce++
+
+			wait, ok := state.PowerForecast(power, tx.Cost(), balance)
+			if !ok {
+				break
+			}
+			if wait > 0 {
+				from := new(big.Int).SetUint64(number.Uint64() + blocks)
+				power = pool.currentState.CalculatePower(from, new(big.Int).SetUint64(from.Uint64()+wait), power, balance)
+				blocks += wait
+			}
+			power = new(big.Int).Sub(power, tx.Cost())
+			if blocks > 0 {
+				waiting[tx.Hash()] = number.Uint64() + blocks
+			}
+		}
+	}
+	return waiting
+}
+
+// unspentPower returns the power of an account left over after paying for all of
+// its pending transactions.
+func (pool *TxPool) unspentPower(addr common.Address) *big.Int {
+	power := new(big.Int).Set(pool.currentState.GetPower(addr, pool.chain.CurrentBlock().Number()))
+	if list := pool.pending[addr]; list != nil {
+		power.Sub(power, list.Cost())
+	}
+	if power.Sign() < 0 {
+		power.SetInt64(0)
+	}
+	return power
+}
+
+// powerLimit returns the most power an account holding balance can ever spend,
+// nothing if the balance is too low to regenerate any power at all.
+func powerLimit(balance *big.Int) *big.Int {
+	if state.PowerRate(balance).Sign() == 0 {
+		return common.Big0
+	}
+	return state.MaxPower(balance)
+}
+
 // Locals retrieves the accounts currently considered local by the pool.
 func (pool *TxPool) Locals() []common.Address {
 	pool.mu.Lock()
@@ -604,7 +673,9 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if pool.currentState.GetBalance(from).Cmp(big.NewInt(1e+16)) < 0 {
 		return ErrInsufficientMinFunds
 	}
-	if pool.currentState.GetPower(from, pool.chain.CurrentBlock().Number()).Cmp(tx.Cost()) < 0 {
+	// Power regenerates every block, only reject transactions the sender can never
+	// afford. The rest wait in the queue until enough power accrued.
+	if powerLimit(pool.currentState.GetBalance(from)).Cmp(tx.Cost()) < 0 {
 		return ErrInsufficientPower
 	}
 	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil, pool.homestead)
@@ -954,8 +1025,9 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 		}
-		// Drop all transactions that are too costly (low balance or out of gas)
-		drops, _ := list.Filter(pool.currentState.GetBalance(addr), pool.currentState.GetPower(addr, pool.chain.CurrentBlock().Number()), pool.currentMaxGas)
+		// Drop all transactions that are too costly (low balance, unreachable power or out of gas)
+		balance := pool.currentState.GetBalance(addr)
+		drops, _ := list.Filter(balance, powerLimit(balance), pool.currentMaxGas)
 
 		for _, tx := range drops {
 			hash := tx.Hash()
@@ -964,8 +1036,9 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			pool.priced.Removed()
 			queuedNofundsCounter.Inc(1)
 		}
-		// Gather all executable transactions and promote them
-		for _, tx := range list.Ready(pool.pendingState.GetNonce(addr)) {
+		// Gather all executable transactions and promote them, holding back the
+		// ones waiting for power the pending transactions don't already spend
+		for _, tx := range list.Ready(pool.pendingState.GetNonce(addr), pool.unspentPower(addr)) {
 			hash := tx.Hash()
 			if pool.promoteTx(addr, hash, tx) {
 				log.Trace("Promoting queued transaction", "hash", hash)
@@ -1119,8 +1192,9 @@ func (pool *TxPool) demoteUnexecutables() {
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 		}
-		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
-		drops, invalids := list.Filter(pool.currentState.GetBalance(addr),pool.currentState.GetPower(addr, pool.chain.CurrentBlock().Number()), pool.currentMaxGas)
+		// Drop all transactions that are too costly (low balance, unreachable power or out of gas), and queue any invalids back for later
+		balance := pool.currentState.GetBalance(addr)
+		drops, invalids := list.Filter(balance, powerLimit(balance), pool.currentMaxGas)
 		for _, tx := range drops {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
@@ -1128,6 +1202,10 @@ func (pool *TxPool) demoteUnexecutables() {
 			pool.priced.Removed()
 			pendingNofundsCounter.Inc(1)
 		}
+		// Transactions the sender can't power yet, together with the preceding ones,
+		// wait in the queue until enough regenerated
+		invalids = append(invalids, list.Overspent(pool.currentState.GetPower(addr, pool.chain.CurrentBlock().Number()))...)
+
 		for _, tx := range invalids {
 			hash := tx.Hash()
 			log.Trace("Demoting pending transaction", "hash", hash)
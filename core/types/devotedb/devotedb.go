@@ -106,17 +106,19 @@ func (db *DevoteDB) Root() (h common.Hash) {
 	return h
 }
 
+// Commit writes the cycle and stats tries into the in-memory trie database and
+// returns their roots. The tries are not flushed to disk, that is left to the
+// owner of the database, which keeps recent roots referenced and garbage
+// collects the rest.
 func (d *DevoteDB) Commit() (*DevoteProtocol, error) {
 	cycleRoot, err := d.cycleTrie.Commit(nil)
 	if err != nil {
 		return nil, err
 	}
-	d.db.TrieDB().Commit(cycleRoot, false)
 	statsRoot, err := d.statsTrie.Commit(nil)
 	if err != nil {
 		return nil, err
 	}
-	d.db.TrieDB().Commit(statsRoot, false)
 	a := &DevoteProtocol{
 		CycleHash: cycleRoot,
 		StatsHash: statsRoot,
@@ -299,6 +301,11 @@ type DevoteProtocol struct {
 	StatsHash common.Hash `json:"statshash"  gencodec:"required"`
 }
 
+// Roots returns the roots of the cycle and stats tries.
+func (d *DevoteProtocol) Roots() []common.Hash {
+	return []common.Hash{d.CycleHash, d.StatsHash}
+}
+
 func (d *DevoteProtocol) Root() (h common.Hash) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -22,12 +22,13 @@ package masternode
 import (
 	"net"
 	"sync"
-	"crypto/ecdsa"
 	"errors"
+	"math/big"
 
-	"github.com/etherzero/go-etherzero/p2p"
+	"github.com/etherzero/go-etherzero/core/types"
 	"github.com/etherzero/go-etherzero/crypto"
 	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/common/hexutil"
 
 	"fmt"
 )
@@ -43,31 +44,51 @@ const (
 // provides the specified masternode.
 var ErrUnknownMasternode = errors.New("unknown masternode")
 
+// PingState is the ping schedule of the local masternode, derived from the last
+// ping recorded in the masternode contract.
+type PingState struct {
+	LastPing hexutil.Uint64 `json:"lastPing"`          // Block of the last ping recorded by the contract
+	NextPing hexutil.Uint64 `json:"nextPing"`          // Block from which the next ping is sent
+	Deadline hexutil.Uint64 `json:"deadline"`          // Block after which the masternode is dropped from the witnesses
+	Pending  *common.Hash   `json:"pending,omitempty"` // Ping transaction waiting to be included
+	Error    string         `json:"error,omitempty"`   // Reason the last ping could not be sent
+}
+
 //Responsible for activating the Masternode and pinging the network
 type ActiveMasternode struct {
 	ID          string
 	NodeID      [64]byte
 	NodeAccount common.Address
-	PrivateKey  *ecdsa.PrivateKey
+	signer      Signer
 	activeState int
 	Addr        net.TCPAddr
 
 	mu sync.RWMutex
 }
 
-func NewActiveMasternode(srvr *p2p.Server) *ActiveMasternode {
-	x8 := srvr.Self().X8()
-	id := fmt.Sprintf("%x", x8[:])
+// NewActiveMasternode creates the local masternode identified by the public key
+// of signer, which is the key registered in the masternode contract.
+func NewActiveMasternode(signer Signer) *ActiveMasternode {
+	pubkey := signer.PublicKey()
+
+	var xy [64]byte
+	copy(xy[:], crypto.FromECDSAPub(pubkey)[1:])
 	am := &ActiveMasternode{
-		ID:          id,
-		NodeID:      srvr.Self().XY(),
+		ID:          fmt.Sprintf("%x", xy[:8]),
+		NodeID:      xy,
 		activeState: ACTIVE_MASTERNODE_INITIAL,
-		PrivateKey:  srvr.Config.PrivateKey,
-		NodeAccount: crypto.PubkeyToAddress(srvr.Config.PrivateKey.PublicKey),
+		signer:      signer,
+		NodeAccount: crypto.PubkeyToAddress(*pubkey),
 	}
 	return am
 }
 
+// X8 returns the masternode id as stored in the masternode contract.
+func (am *ActiveMasternode) X8() (id [8]byte) {
+	copy(id[:], am.NodeID[:8])
+	return id
+}
+
 func (am *ActiveMasternode) State() int {
 	return am.activeState
 }
@@ -76,9 +97,9 @@ func (am *ActiveMasternode) SetState(state int) {
 	am.activeState = state
 }
 
-// SignHash calculates a ECDSA signature for the given hash. The produced
+// SignHeader calculates the seal signature of a devote header. The produced
 // signature is in the [R || S || V] format where V is 0 or 1.
-func (a *ActiveMasternode) SignHash(id string, hash []byte) ([]byte, error) {
+func (a *ActiveMasternode) SignHeader(id string, header *types.Header) ([]byte, error) {
 	// Look up the key to sign with and abort if it cannot be found
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -86,6 +107,13 @@ func (a *ActiveMasternode) SignHash(id string, hash []byte) ([]byte, error) {
 	if id != a.ID{
 		return nil, ErrUnknownMasternode
 	}
-	// Sign the hash using plain ECDSA operations
-	return crypto.Sign(hash, a.PrivateKey)
+	return a.signer.SignHeader(header)
+}
+
+// SignTx signs a transaction sent from the masternode account, such as a ping.
+func (a *ActiveMasternode) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.signer.SignTx(tx, chainID)
 }
\ No newline at end of file
@@ -0,0 +1,62 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package masternode
+
+import (
+	"bytes"
+
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/rlp"
+)
+
+// ReportDoubleSignInput returns the masternode contract call data reporting the
+// masternode of the given id for sealing both headers in the same slot. The call
+// must be sent from the owner account of another masternode, the reported one is
+// removed once more than half of the owners reported it.
+func ReportDoubleSignInput(id [8]byte, first, second *types.Header) ([]byte, error) {
+	header1, err := rlp.EncodeToBytes(first)
+	if err != nil {
+		return nil, err
+	}
+	header2, err := rlp.EncodeToBytes(second)
+	if err != nil {
+		return nil, err
+	}
+	return contractABI.Pack("reportDoubleSign", id, header1, header2)
+}
+
+// ReportDoubleSignID returns the id of the masternode reported by the given
+// masternode contract call data, or false if the data is no double sign report.
+func ReportDoubleSignID(input []byte) ([8]byte, bool) {
+	var id [8]byte
+	method := contractABI.Methods["reportDoubleSign"]
+	if len(input) < 4+3*32 || !bytes.Equal(input[:4], method.Id()) {
+		return id, false
+	}
+	copy(id[:], input[4:])
+	return id, true
+}
+
+// SupportsDoubleSignReports reports whether the given masternode contract code
+// dispatches double sign reports. Contracts deployed before the method existed
+// would run such a call as a plain transaction, which quits the masternode of
+// the sender instead.
+func SupportsDoubleSignReports(code []byte) bool {
+	// The Solidity dispatcher pushes the selector of every method with PUSH4
+	selector := append([]byte{0x63}, contractABI.Methods["reportDoubleSign"].Id()...)
+	return bytes.Contains(code, selector)
+}
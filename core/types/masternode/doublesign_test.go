@@ -0,0 +1,67 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package masternode
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/rlp"
+)
+
+// Tests that double sign reports carry both headers to the masternode contract,
+// and that contracts without the method are told apart.
+func TestDoubleSignReports(t *testing.T) {
+	id := [8]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+	first := &types.Header{Number: big.NewInt(1), Time: big.NewInt(10), Difficulty: common.Big1, Witness: "0123456789abcdef"}
+	second := &types.Header{Number: big.NewInt(1), Time: big.NewInt(10), Difficulty: common.Big1, Witness: "0123456789abcdef", Extra: []byte{0x01}}
+
+	input, err := ReportDoubleSignInput(id, first, second)
+	if err != nil {
+		t.Fatalf("failed to pack report: %v", err)
+	}
+	if selector := crypto.Keccak256([]byte("reportDoubleSign(bytes8,bytes,bytes)"))[:4]; !bytes.HasPrefix(input, selector) {
+		t.Errorf("report selector mismatch: have %x, want %x", input[:4], selector)
+	}
+	if have, ok := ReportDoubleSignID(input); !ok || have != id {
+		t.Errorf("reported id mismatch: have %x (%v), want %x", have, ok, id)
+	}
+	for i, header := range []*types.Header{first, second} {
+		blob, _ := rlp.EncodeToBytes(header)
+		if !bytes.Contains(input, blob) {
+			t.Errorf("header %d missing from report", i)
+		}
+	}
+	// Other calls are no reports
+	if _, ok := ReportDoubleSignID(input[:4+2*32]); ok {
+		t.Errorf("truncated report decoded")
+	}
+	if _, ok := ReportDoubleSignID(append(common.FromHex("0x2f926732"), input[4:]...)); ok {
+		t.Errorf("register call decoded as report")
+	}
+	// Only contract code dispatching the method supports reports
+	if SupportsDoubleSignReports(common.FromHex("0x6080604052600436106101065763ffffffff7c01000000000000000000000000000000000000000000000000000000006000350416632f926732811461064b57")) {
+		t.Errorf("contract without the method reported to support it")
+	}
+	if !SupportsDoubleSignReports(common.FromHex("0x6080604052600436106101065763ffffffff7c0100000000000000000000000000000000000000000000000000000000600035041663c83b0f7f811461064b57")) {
+		t.Errorf("contract with the method reported not to support it")
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package masternode
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/etherzero/go-etherzero/accounts/abi/bind"
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/contracts/masternode/contract"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// ErrUnknownProposal is returned if no governance proposal exists for an address.
+var ErrUnknownProposal = errors.New("unknown governance proposal")
+
+// ProposalFee is the fee the masternode contract charges for creating a
+// governance address proposal.
+var ProposalFee = new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+
+// Proposal is a vote of the masternodes on a new governance address, which is
+// credited with the community reward of every block once adopted.
+type Proposal struct {
+	Address    common.Address // Proposed governance address
+	Creator    common.Address // Account which paid the proposal fee
+	VoteCount  *big.Int       // Number of masternode owners voting for the proposal
+	StartBlock *big.Int       // Block the proposal was created in
+	StopBlock  *big.Int       // Block the voting ends, or ended on adoption
+}
+
+// Open reports whether masternode owners can vote for the proposal in the block
+// following the given one.
+func (p *Proposal) Open(number *big.Int) bool {
+	next := new(big.Int).Add(number, common.Big1)
+	return next.Cmp(p.StartBlock) > 0 && next.Cmp(p.StopBlock) < 0
+}
+
+// Governance is the state of the governance voting in the masternode contract
+// at a given block.
+type Governance struct {
+	Address     common.Address // Governance address set in the contract
+	Masternodes *big.Int       // Number of masternodes, more than half must vote to adopt a proposal
+	Proposals   []*Proposal    // Proposals from the most recently created one
+}
+
+// Quorum returns the number of votes a proposal needs to be adopted.
+func (g *Governance) Quorum() *big.Int {
+	quorum := new(big.Int).Div(g.Masternodes, common.Big2)
+	return quorum.Add(quorum, common.Big1)
+}
+
+// Proposal returns the proposal of the given governance address.
+func (g *Governance) Proposal(addr common.Address) (*Proposal, error) {
+	for _, proposal := range g.Proposals {
+		if proposal.Address == addr {
+			return proposal, nil
+		}
+	}
+	return nil, ErrUnknownProposal
+}
+
+// GetGovernance retrieves the governance address and all proposals from the
+// contract at the given block, walking the proposal list from the most recent.
+func GetGovernance(contract *contract.Contract, blockNumber *big.Int) (*Governance, error) {
+	if blockNumber == nil {
+		blockNumber = new(big.Int)
+	}
+	opts := new(bind.CallOpts)
+	opts.BlockNumber = blockNumber
+
+	addr, err := contract.GovernanceAddress(opts)
+	if err != nil {
+		return nil, err
+	}
+	count, err := contract.Count(opts)
+	if err != nil {
+		return nil, err
+	}
+	governance := &Governance{Address: addr, Masternodes: count}
+
+	next, err := contract.LastProposalAddress(opts)
+	if err != nil {
+		return nil, err
+	}
+	for next != (common.Address{}) {
+		info, err := contract.GetVoteInfo(opts, next)
+		if err != nil {
+			return nil, err
+		}
+		governance.Proposals = append(governance.Proposals, &Proposal{
+			Address:    next,
+			Creator:    info.Creator,
+			VoteCount:  info.VoteCount,
+			StartBlock: info.StartBlock,
+			StopBlock:  info.StopBlock,
+		})
+		next = info.LastAddress
+	}
+	return governance, nil
+}
+
+// HasVoted reports whether the voter voted for the proposal up to the given block.
+func HasVoted(contract *contract.Contract, proposal, voter common.Address, blockNumber *big.Int) (bool, error) {
+	if blockNumber == nil {
+		blockNumber = new(big.Int)
+	}
+	opts := new(bind.CallOpts)
+	opts.BlockNumber = blockNumber
+	return contract.CheckVote(opts, proposal, voter)
+}
+
+// ProposeInput returns the masternode contract call data proposing the given
+// governance address. The call must be sent along with ProposalFee.
+func ProposeInput(addr common.Address) ([]byte, error) {
+	return contractABI.Pack("createGovernanceAddressVote", addr)
+}
+
+// VoteInput returns the masternode contract call data voting for the proposal
+// of the given governance address. The call must be sent from the owner account
+// of a masternode.
+func VoteInput(addr common.Address) ([]byte, error) {
+	return contractABI.Pack("voteForGovernanceAddress", addr)
+}
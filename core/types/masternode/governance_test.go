@@ -0,0 +1,104 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package masternode
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/accounts/abi/bind/backends"
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/contracts/masternode/contract"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/p2p/enode"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// Tests that governance proposals created in the masternode contract are listed
+// with their voting window and tally.
+func TestGovernanceProposals(t *testing.T) {
+	nodeKey, _ := crypto.GenerateKey()
+	key, _ := crypto.GenerateKey()
+	creator := crypto.PubkeyToAddress(key.PublicKey)
+	governance := common.HexToAddress("0x1234567890123456789012345678901234567890")
+
+	url := enode.NewV4(&nodeKey.PublicKey, net.ParseIP("127.0.0.1"), 30303, 30303).String()
+	alloc := core.GenesisAlloc{
+		params.MasterndeContractAddress: core.MasternodeContractAccount([]string{url}, governance),
+		creator:                         {Balance: new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))},
+	}
+	sim := backends.NewDevoteSimulatedBackend(alloc, 10000000, []string{"0123456789abcdef"})
+	sim.Commit()
+
+	masternodes, err := contract.NewContract(params.MasterndeContractAddress, sim)
+	if err != nil {
+		t.Fatalf("failed to bind contract: %v", err)
+	}
+	proposed := []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02")}
+	for i, addr := range proposed {
+		input, err := ProposeInput(addr)
+		if err != nil {
+			t.Fatalf("failed to pack proposal: %v", err)
+		}
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), params.MasterndeContractAddress, ProposalFee, 300000, big.NewInt(1), input), types.HomesteadSigner{}, key)
+		if err := sim.SendTransaction(context.Background(), tx); err != nil {
+			t.Fatalf("failed to send proposal: %v", err)
+		}
+		sim.Commit()
+	}
+	number := big.NewInt(3)
+	state, err := GetGovernance(masternodes, number)
+	if err != nil {
+		t.Fatalf("failed to retrieve governance: %v", err)
+	}
+	if state.Address != governance {
+		t.Errorf("governance address mismatch: have %x, want %x", state.Address, governance)
+	}
+	if state.Quorum().Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("quorum mismatch: have %v, want 1", state.Quorum())
+	}
+	if len(state.Proposals) != len(proposed) {
+		t.Fatalf("proposal count mismatch: have %d, want %d", len(state.Proposals), len(proposed))
+	}
+	for i, proposal := range state.Proposals {
+		// Proposals are listed from the most recent one
+		want := proposed[len(proposed)-1-i]
+		if proposal.Address != want || proposal.Creator != creator {
+			t.Errorf("proposal %d mismatch: have %x by %x, want %x by %x", i, proposal.Address, proposal.Creator, want, creator)
+		}
+		if proposal.VoteCount.Sign() != 0 {
+			t.Errorf("proposal %d: unexpected votes %v", i, proposal.VoteCount)
+		}
+		if !proposal.Open(number) {
+			t.Errorf("proposal %d: voting closed right after creation", i)
+		}
+		if proposal.Open(new(big.Int).Sub(proposal.StartBlock, common.Big1)) || proposal.Open(proposal.StopBlock) {
+			t.Errorf("proposal %d: voting open outside of its window", i)
+		}
+	}
+	if _, err := state.Proposal(common.HexToAddress("0x03")); err != ErrUnknownProposal {
+		t.Errorf("unknown proposal error mismatch: have %v, want %v", err, ErrUnknownProposal)
+	}
+	voted, err := HasVoted(masternodes, proposed[0], creator, number)
+	if err != nil || voted {
+		t.Errorf("unexpected vote of the creator: %v, %v", voted, err)
+	}
+}
@@ -97,7 +97,11 @@ func GetGovernanceAddress(contract *contract.Contract, blockNumber *big.Int) (co
 	return addr, err
 }
 
-func GetIdsByBlockNumber(contract *contract.Contract, blockNumber *big.Int) ([]string, error) {
+// GetIdsByBlockNumber returns the ids of the masternodes which pinged the contract
+// within pingTimeout blocks before the given block, along with the genesis
+// masternodes which never pinged. The list is topped up from the whole contract
+// if it holds fewer than witnessSize nodes.
+func GetIdsByBlockNumber(contract *contract.Contract, blockNumber *big.Int, pingTimeout uint64, witnessSize uint64) ([]string, error) {
 	if blockNumber == nil {
 		blockNumber = new(big.Int)
 	}
@@ -120,7 +124,7 @@ func GetIdsByBlockNumber(contract *contract.Contract, blockNumber *big.Int) ([]s
 		}
 		lastId = ctx.pre
 		if ctx.Node.BlockLastPing.Cmp(common.Big0) > 0 {
-			if new(big.Int).Sub(blockNumber, ctx.Node.BlockLastPing).Cmp(big.NewInt(3600)) > 0 {
+			if new(big.Int).Sub(blockNumber, ctx.Node.BlockLastPing).Cmp(new(big.Int).SetUint64(pingTimeout)) > 0 {
 				continue
 			}
 		} else if ctx.Node.OriginBlock.Cmp(common.Big0) > 0 {
@@ -128,7 +132,7 @@ func GetIdsByBlockNumber(contract *contract.Contract, blockNumber *big.Int) ([]s
 		}
 		ids = append(ids, ctx.Node.ID)
 	}
-	if len(ids) < 21 {
+	if uint64(len(ids)) < witnessSize {
 		lastId, err = contract.LastId(opts)
 		if err != nil {
 			return ids, err
@@ -141,7 +145,7 @@ func GetIdsByBlockNumber(contract *contract.Contract, blockNumber *big.Int) ([]s
 			}
 			lastId = ctx.pre
 			if ctx.Node.BlockLastPing.Cmp(common.Big0) > 0 {
-				if new(big.Int).Sub(blockNumber, ctx.Node.BlockLastPing).Cmp(big.NewInt(3600)) <= 0 {
+				if new(big.Int).Sub(blockNumber, ctx.Node.BlockLastPing).Cmp(new(big.Int).SetUint64(pingTimeout)) <= 0 {
 					repeat := false
 					for _, n := range ids {
 						if n == ctx.Node.ID {
@@ -158,6 +162,54 @@ func GetIdsByBlockNumber(contract *contract.Contract, blockNumber *big.Int) ([]s
 	return ids, nil
 }
 
+// GetMasternodes returns all masternodes registered in the contract at the given
+// block, walking the contract's linked list from the most recently joined node.
+func GetMasternodes(contract *contract.Contract, blockNumber *big.Int) ([]*Masternode, error) {
+	if blockNumber == nil {
+		blockNumber = new(big.Int)
+	}
+	opts := new(bind.CallOpts)
+	opts.BlockNumber = blockNumber
+
+	lastId, err := contract.LastId(opts)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*Masternode
+	for lastId != ([8]byte{}) {
+		ctx, err := GetMasternodeContext(opts, contract, lastId)
+		if err != nil {
+			return nodes, err
+		}
+		nodes = append(nodes, ctx.Node)
+		lastId = ctx.pre
+	}
+	return nodes, nil
+}
+
+// GetMasternode retrieves a single registered masternode from the contract at
+// the given block, returning ErrUnknownMasternode if the id is not registered.
+func GetMasternode(contract *contract.Contract, id [8]byte, blockNumber *big.Int) (*Masternode, error) {
+	if blockNumber == nil {
+		blockNumber = new(big.Int)
+	}
+	opts := new(bind.CallOpts)
+	opts.BlockNumber = blockNumber
+
+	has, err := contract.Has(opts, id)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrUnknownMasternode
+	}
+	ctx, err := GetMasternodeContext(opts, contract, id)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Node, nil
+}
+
 func GetMasternodeID(ID discv5.NodeID) string {
 	return fmt.Sprintf("%x", ID[:8])
 }
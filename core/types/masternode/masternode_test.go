@@ -18,8 +18,20 @@ package masternode
 
 import (
 	"fmt"
+	"math/big"
+	"net"
 	"testing"
 	"time"
+
+	"github.com/etherzero/go-etherzero/accounts/abi/bind"
+	"github.com/etherzero/go-etherzero/accounts/abi/bind/backends"
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/common/math"
+	"github.com/etherzero/go-etherzero/contracts/masternode/contract"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/p2p/enode"
+	"github.com/etherzero/go-etherzero/params"
 )
 
 func Test_rlphash(t *testing.T) {
@@ -29,3 +41,98 @@ func Test_rlphash(t *testing.T) {
 
 	fmt.Printf("%v", uint64(time.Now().Sub(createdTime)))
 }
+
+// Tests that registered masternodes can be listed and looked up from the
+// masternode contract.
+func TestGetMasternodes(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	nodeKey, _ := crypto.GenerateKey()
+
+	balance := new(big.Int).Mul(big.NewInt(50000), big.NewInt(1e18))
+	sim := backends.NewDevoteSimulatedBackend(core.GenesisAlloc{owner: {Balance: balance}}, 10000000, []string{"0123456789abcdef"})
+	sim.Commit()
+
+	auth := bind.NewKeyedTransactor(key)
+	_, _, masternodes, err := contract.DeployContract(auth, sim)
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	sim.Commit()
+
+	deposit, err := masternodes.EtzPerNode(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve deposit: %v", err)
+	}
+	var id1, id2 [32]byte
+	copy(id1[:], math.PaddedBigBytes(nodeKey.PublicKey.X, 32))
+	copy(id2[:], math.PaddedBigBytes(nodeKey.PublicKey.Y, 32))
+	auth.Value = deposit
+	if _, err := masternodes.Register(auth, id1, id2); err != nil {
+		t.Fatalf("failed to register masternode: %v", err)
+	}
+	sim.Commit()
+
+	number := big.NewInt(3)
+	nodes, err := GetMasternodes(masternodes, number)
+	if err != nil {
+		t.Fatalf("failed to list masternodes: %v", err)
+	}
+	if len(nodes) != 1 {
+		t.Fatalf("masternode count mismatch: have %d, want 1", len(nodes))
+	}
+	var id [8]byte
+	copy(id[:], id1[:8])
+	if want := fmt.Sprintf("%x", id); nodes[0].ID != want {
+		t.Errorf("masternode id mismatch: have %s, want %s", nodes[0].ID, want)
+	}
+	if nodes[0].Account != owner {
+		t.Errorf("masternode account mismatch: have %x, want %x", nodes[0].Account, owner)
+	}
+	node, err := GetMasternode(masternodes, id, number)
+	if err != nil {
+		t.Fatalf("failed to retrieve masternode: %v", err)
+	}
+	if node.OriginBlock.Cmp(number) != 0 {
+		t.Errorf("origin block mismatch: have %v, want %v", node.OriginBlock, number)
+	}
+	if _, err := GetMasternode(masternodes, [8]byte{1}, number); err != ErrUnknownMasternode {
+		t.Errorf("unknown masternode error mismatch: have %v, want %v", err, ErrUnknownMasternode)
+	}
+}
+
+// Tests that the genesis masternode contract lists the initial masternodes and
+// the configured governance address.
+func TestGenesisMasternodeContract(t *testing.T) {
+	nodeKey, _ := crypto.GenerateKey()
+	url := enode.NewV4(&nodeKey.PublicKey, net.ParseIP("127.0.0.1"), 30303, 30303).String()
+	governance := common.HexToAddress("0x1234567890123456789012345678901234567890")
+
+	alloc := core.GenesisAlloc{
+		params.MasterndeContractAddress: core.MasternodeContractAccount([]string{url}, governance),
+	}
+	sim := backends.NewDevoteSimulatedBackend(alloc, 10000000, []string{"0123456789abcdef"})
+	sim.Commit()
+
+	masternodes, err := contract.NewContract(params.MasterndeContractAddress, sim)
+	if err != nil {
+		t.Fatalf("failed to bind contract: %v", err)
+	}
+	nodes, err := GetMasternodes(masternodes, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("failed to list masternodes: %v", err)
+	}
+	if len(nodes) != 1 {
+		t.Fatalf("masternode count mismatch: have %d, want 1", len(nodes))
+	}
+	if want := fmt.Sprintf("%x", enode.MustParseV4(url).X8()); nodes[0].ID != want {
+		t.Errorf("masternode id mismatch: have %s, want %s", nodes[0].ID, want)
+	}
+	addr, err := GetGovernanceAddress(masternodes, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("failed to retrieve governance address: %v", err)
+	}
+	if addr != governance {
+		t.Errorf("governance address mismatch: have %x, want %x", addr, governance)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package masternode
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+
+	"github.com/etherzero/go-etherzero/accounts/abi"
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/contracts/masternode/contract"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/p2p/discv5"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// Names of the masternode contract events announcing membership changes.
+const (
+	JoinEvent = "join"
+	QuitEvent = "quit"
+)
+
+// DepositAmount is the stake a masternode owner deposits in the masternode
+// contract on registration, and gets refunded on quit.
+var DepositAmount = new(big.Int).Mul(big.NewInt(20000), big.NewInt(params.Ether))
+
+// contractABI is the parsed ABI of the masternode contract.
+var contractABI, _ = abi.JSON(strings.NewReader(contract.ContractABI))
+
+// RegisterInput returns the masternode contract call data registering the
+// masternode with the given public key. The call must be sent from the owner
+// account along with DepositAmount. Quitting only takes a plain transaction
+// without data from the same owner account.
+func RegisterInput(nodeID [64]byte) ([]byte, error) {
+	var id1, id2 [32]byte
+	copy(id1[:], nodeID[:32])
+	copy(id2[:], nodeID[32:])
+	return contractABI.Pack("register", id1, id2)
+}
+
+// RegisterNodeID returns the masternode public key registered by the given
+// masternode contract call data, or false if the data is no register call.
+func RegisterNodeID(input []byte) (discv5.NodeID, bool) {
+	var nodeID discv5.NodeID
+	method := contractABI.Methods["register"]
+	if len(input) != 4+len(nodeID) || !bytes.Equal(input[:4], method.Id()) {
+		return nodeID, false
+	}
+	copy(nodeID[:], input[4:])
+	return nodeID, true
+}
+
+// MembershipLog is a decoded join or quit event of the masternode contract.
+type MembershipLog struct {
+	Event string
+	Id    [8]byte
+	Addr  common.Address
+}
+
+// ParseMembershipLog decodes a join or quit event emitted by the masternode
+// contract. It returns nil for any other log.
+func ParseMembershipLog(log *types.Log) *MembershipLog {
+	if log.Address != params.MasterndeContractAddress || len(log.Topics) == 0 {
+		return nil
+	}
+	for _, name := range []string{JoinEvent, QuitEvent} {
+		if log.Topics[0] != contractABI.Events[name].Id() {
+			continue
+		}
+		event := &MembershipLog{Event: name}
+		if err := contractABI.Unpack(event, name, log.Data); err != nil {
+			return nil
+		}
+		return event
+	}
+	return nil
+}
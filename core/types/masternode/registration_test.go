@@ -0,0 +1,74 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package masternode
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// Tests that the register call data matches the one operators used to send by
+// hand, and that membership events of the masternode contract are decoded.
+func TestRegistrationCalls(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	var nodeID [64]byte
+	copy(nodeID[:], crypto.FromECDSAPub(&key.PublicKey)[1:])
+
+	input, err := RegisterInput(nodeID)
+	if err != nil {
+		t.Fatalf("failed to pack register call: %v", err)
+	}
+	if want := append(common.FromHex("0x2f926732"), nodeID[:]...); !bytes.Equal(input, want) {
+		t.Errorf("register input mismatch: have %x, want %x", input, want)
+	}
+	var id [8]byte
+	copy(id[:], nodeID[:8])
+	owner := common.HexToAddress("0x0102030405060708090a0b0c0d0e0f1011121314")
+
+	data := append(common.RightPadBytes(id[:], 32), common.LeftPadBytes(owner[:], 32)...)
+	for _, name := range []string{JoinEvent, QuitEvent} {
+		log := &types.Log{
+			Address: params.MasterndeContractAddress,
+			Topics:  []common.Hash{crypto.Keccak256Hash([]byte(name + "(bytes8,address)"))},
+			Data:    data,
+		}
+		event := ParseMembershipLog(log)
+		if event == nil {
+			t.Fatalf("%s event not decoded", name)
+		}
+		if event.Event != name || event.Id != id || event.Addr != owner {
+			t.Errorf("%s event mismatch: have %+v", name, event)
+		}
+		log.Address = common.Address{1}
+		if ParseMembershipLog(log) != nil {
+			t.Errorf("%s event of a foreign contract decoded", name)
+		}
+	}
+	ping := &types.Log{
+		Address: params.MasterndeContractAddress,
+		Topics:  []common.Hash{crypto.Keccak256Hash([]byte("ping(bytes8,uint256,uint256)"))},
+		Data:    make([]byte, 96),
+	}
+	if ParseMembershipLog(ping) != nil {
+		t.Errorf("ping event decoded as membership change")
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package masternode
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/common/hexutil"
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/rlp"
+	"github.com/etherzero/go-etherzero/rpc"
+)
+
+var (
+	// errSignerMismatch is returned if an external signer produced a signature
+	// that does not belong to the configured masternode key.
+	errSignerMismatch = errors.New("external signer used a different key")
+
+	// errSignerModifiedTx is returned if an external signer signed a transaction
+	// different from the requested one.
+	errSignerModifiedTx = errors.New("external signer modified the transaction")
+)
+
+// Signer signs devote blocks and masternode contract transactions on behalf of
+// the local masternode. Its key is the masternode identity registered in the
+// contract, which is independent from the devp2p node key.
+type Signer interface {
+	// PublicKey returns the public key of the masternode.
+	PublicKey() *ecdsa.PublicKey
+
+	// SignHeader returns the seal signature of a devote block header.
+	SignHeader(header *types.Header) ([]byte, error)
+
+	// SignTx signs a transaction sent from the masternode account.
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// keySigner is a Signer holding the masternode private key in memory.
+type keySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewKeySigner creates a masternode signer from a private key, usually one
+// decrypted from a keystore file.
+func NewKeySigner(key *ecdsa.PrivateKey) Signer {
+	return &keySigner{key: key}
+}
+
+func (s *keySigner) PublicKey() *ecdsa.PublicKey {
+	return &s.key.PublicKey
+}
+
+func (s *keySigner) SignHeader(header *types.Header) ([]byte, error) {
+	return crypto.Sign(devote.SealHash(header).Bytes(), s.key)
+}
+
+func (s *keySigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.NewEIP155Signer(chainID), s.key)
+}
+
+// externalSigner is a Signer delegating all signatures to an external signer,
+// such as clef, so the masternode key never enters the node.
+type externalSigner struct {
+	client  *rpc.Client
+	pubkey  *ecdsa.PublicKey
+	account common.Address
+}
+
+// externalTxArgs are the transaction fields sent to the external signer.
+type externalTxArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      hexutil.Uint64  `json:"gas"`
+	GasPrice hexutil.Big     `json:"gasPrice"`
+	Value    hexutil.Big     `json:"value"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	Data     *hexutil.Bytes  `json:"data"`
+}
+
+// externalTxResult is the signed transaction returned by the external signer.
+type externalTxResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+// NewExternalSigner creates a masternode signer asking the external signer at
+// endpoint (an IPC path or HTTP URL) to sign with the key of pubkey.
+func NewExternalSigner(endpoint string, pubkey *ecdsa.PublicKey) (Signer, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &externalSigner{
+		client:  client,
+		pubkey:  pubkey,
+		account: crypto.PubkeyToAddress(*pubkey),
+	}, nil
+}
+
+func (s *externalSigner) PublicKey() *ecdsa.PublicKey {
+	return s.pubkey
+}
+
+func (s *externalSigner) SignHeader(header *types.Header) ([]byte, error) {
+	blob, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	var signature hexutil.Bytes
+	if err := s.client.Call(&signature, "account_signDevoteHeader", s.account, hexutil.Bytes(blob)); err != nil {
+		return nil, err
+	}
+	// Make sure the signer did not sign with some other key of its keystore
+	pubkey, err := crypto.SigToPub(devote.SealHash(header).Bytes(), signature)
+	if err != nil {
+		return nil, err
+	}
+	if crypto.PubkeyToAddress(*pubkey) != s.account {
+		return nil, errSignerMismatch
+	}
+	return signature, nil
+}
+
+func (s *externalSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := &externalTxArgs{
+		From:     s.account,
+		Gas:      hexutil.Uint64(tx.Gas()),
+		GasPrice: hexutil.Big(*tx.GasPrice()),
+		Value:    hexutil.Big(*tx.Value()),
+		Nonce:    hexutil.Uint64(tx.Nonce()),
+	}
+	if to := tx.To(); to != nil {
+		args.To = to
+	}
+	if data := tx.Data(); len(data) > 0 {
+		input := hexutil.Bytes(data)
+		args.Data = &input
+	}
+	var result externalTxResult
+	if err := s.client.Call(&result, "account_signTransaction", args); err != nil {
+		return nil, err
+	}
+	signed := new(types.Transaction)
+	if err := rlp.DecodeBytes(result.Raw, signed); err != nil {
+		return nil, err
+	}
+	signer := types.NewEIP155Signer(chainID)
+	if signer.Hash(signed) != signer.Hash(tx) {
+		return nil, errSignerModifiedTx
+	}
+	sender, err := types.Sender(signer, signed)
+	if err != nil {
+		return nil, err
+	}
+	if sender != s.account {
+		return nil, errSignerMismatch
+	}
+	return signed, nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package masternode
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/common/hexutil"
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/devotedb"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/rlp"
+	"github.com/etherzero/go-etherzero/rpc"
+)
+
+// FakeTxArgs and FakeTxResult are the exported mirrors of the external signer
+// transaction types, as required by the RPC server.
+type (
+	FakeTxArgs   externalTxArgs
+	FakeTxResult externalTxResult
+)
+
+// FakeExternalSigner is a minimal external signer service serving the account
+// namespace with a single key.
+type FakeExternalSigner struct {
+	key     *ecdsa.PrivateKey
+	chainID *big.Int
+}
+
+func (s *FakeExternalSigner) SignDevoteHeader(addr common.MixedcaseAddress, blob hexutil.Bytes) (hexutil.Bytes, error) {
+	if addr.Address() != crypto.PubkeyToAddress(s.key.PublicKey) {
+		return nil, fmt.Errorf("unknown account %s", addr.Address().Hex())
+	}
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(blob, header); err != nil {
+		return nil, err
+	}
+	return crypto.Sign(devote.SealHash(header).Bytes(), s.key)
+}
+
+func (s *FakeExternalSigner) SignTransaction(args FakeTxArgs) (*FakeTxResult, error) {
+	var data []byte
+	if args.Data != nil {
+		data = *args.Data
+	}
+	tx := types.NewTransaction(uint64(args.Nonce), *args.To, args.Value.ToInt(), uint64(args.Gas), args.GasPrice.ToInt(), data)
+	signed, err := types.SignTx(tx, types.NewEIP155Signer(s.chainID), s.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		return nil, err
+	}
+	return &FakeTxResult{Raw: raw}, nil
+}
+
+// Tests that the masternode identity derives from the signing key and that both
+// local and external signers produce seals recoverable to the masternode.
+func TestMasternodeSigners(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	chainID := big.NewInt(90)
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("account", &FakeExternalSigner{key: key, chainID: chainID}); err != nil {
+		t.Fatalf("failed to register signer service: %v", err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	external, err := NewExternalSigner(httpsrv.URL, &key.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to create external signer: %v", err)
+	}
+	header := &types.Header{Number: big.NewInt(1), Time: big.NewInt(100), Extra: make([]byte, 65), Protocol: new(devotedb.DevoteProtocol)}
+	tx := types.NewTransaction(3, common.HexToAddress("0x01"), new(big.Int), 90000, big.NewInt(1), []byte{0xde, 0xad, 0xbe, 0xef})
+
+	for name, signer := range map[string]Signer{"local": NewKeySigner(key), "external": external} {
+		active := NewActiveMasternode(signer)
+		if want := fmt.Sprintf("%x", crypto.FromECDSAPub(&key.PublicKey)[1:9]); active.ID != want {
+			t.Errorf("%s: id mismatch: have %s, want %s", name, active.ID, want)
+		}
+		if want := crypto.PubkeyToAddress(key.PublicKey); active.NodeAccount != want {
+			t.Errorf("%s: account mismatch: have %x, want %x", name, active.NodeAccount, want)
+		}
+		sig, err := active.SignHeader(active.ID, header)
+		if err != nil {
+			t.Fatalf("%s: failed to seal header: %v", name, err)
+		}
+		pubkey, err := crypto.SigToPub(devote.SealHash(header).Bytes(), sig)
+		if err != nil || crypto.PubkeyToAddress(*pubkey) != active.NodeAccount {
+			t.Errorf("%s: seal not signed by the masternode key", name)
+		}
+		if _, err := active.SignHeader("0000000000000000", header); err != ErrUnknownMasternode {
+			t.Errorf("%s: sealed for a foreign witness: %v", name, err)
+		}
+		signed, err := active.SignTx(tx, chainID)
+		if err != nil {
+			t.Fatalf("%s: failed to sign transaction: %v", name, err)
+		}
+		if sender, _ := types.Sender(types.NewEIP155Signer(chainID), signed); sender != active.NodeAccount {
+			t.Errorf("%s: transaction sender mismatch: have %x, want %x", name, sender, active.NodeAccount)
+		}
+	}
+	// An external signer holding another key must be rejected
+	other, _ := crypto.GenerateKey()
+	mismatched, err := NewExternalSigner(httpsrv.URL, &other.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to create external signer: %v", err)
+	}
+	if _, err := mismatched.SignHeader(header); err == nil {
+		t.Errorf("seal of a foreign key accepted")
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package masternode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/contracts/masternode/contract"
+	"github.com/etherzero/go-etherzero/core/rawdb"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/p2p/discv5"
+	"github.com/etherzero/go-etherzero/params"
+	"github.com/etherzero/go-etherzero/rlp"
+)
+
+// PingEvent is the name of the masternode contract event announcing a ping.
+const PingEvent = "ping"
+
+var (
+	// snapshotPrefix is the database key prefix masternode snapshots are stored
+	// under, followed by the block hash.
+	snapshotPrefix = []byte("masternode-")
+
+	// snapshotHashesPrefix is the database key prefix the hashes of the blocks
+	// with a stored snapshot are tracked under, followed by the block number.
+	snapshotHashesPrefix = []byte("masternode-n-")
+)
+
+// SnapshotNode is a masternode registered in the contract, as tracked by a
+// snapshot.
+type SnapshotNode struct {
+	Id          [8]byte
+	NodeID      discv5.NodeID
+	Account     common.Address
+	OriginBlock uint64
+	OnlineAcc   uint64
+	LastPing    uint64
+}
+
+// Snapshot is the list of masternodes registered in the contract after a given
+// block, ordered from the most recently joined one like the linked list of the
+// contract.
+type Snapshot struct {
+	Number uint64          // Block number where the snapshot was created
+	Hash   common.Hash     // Block hash where the snapshot was created
+	Nodes  []*SnapshotNode // Masternodes in the order of the contract
+}
+
+// NewSnapshot creates a snapshot from the masternodes listed by the contract at
+// the given block.
+func NewSnapshot(number uint64, hash common.Hash, nodes []*Masternode) *Snapshot {
+	snap := &Snapshot{Number: number, Hash: hash}
+	for _, node := range nodes {
+		n := &SnapshotNode{
+			NodeID:      node.NodeID,
+			Account:     node.Account,
+			OriginBlock: node.OriginBlock.Uint64(),
+			OnlineAcc:   node.BlockOnlineAcc.Uint64(),
+			LastPing:    node.BlockLastPing.Uint64(),
+		}
+		copy(n.Id[:], node.NodeID[:8])
+		snap.Nodes = append(snap.Nodes, n)
+	}
+	return snap
+}
+
+// LoadSnapshot loads an existing snapshot from the database.
+func LoadSnapshot(db ethdb.Database, hash common.Hash) (*Snapshot, error) {
+	blob, err := db.Get(append(snapshotPrefix, hash[:]...))
+	if err != nil {
+		return nil, err
+	}
+	snap := new(Snapshot)
+	if err := rlp.DecodeBytes(blob, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// LoadCanonicalSnapshot loads the snapshot of the canonical block with the given
+// number, or nil if none was stored for it.
+func LoadCanonicalSnapshot(db ethdb.Database, number *big.Int) *Snapshot {
+	if number == nil {
+		number = new(big.Int)
+	}
+	hash := rawdb.ReadCanonicalHash(db, number.Uint64())
+	if hash == (common.Hash{}) {
+		return nil
+	}
+	snapshot, err := LoadSnapshot(db, hash)
+	if err != nil {
+		return nil
+	}
+	return snapshot
+}
+
+// GetIds returns the ids of the masternodes electing the witnesses at the
+// canonical block with the given number. They are read from the snapshot of the
+// block, or from the contract state if no snapshot was stored for it.
+func GetIds(db ethdb.Database, contract *contract.Contract, config *params.DevoteConfig, number *big.Int) ([]string, error) {
+	rules := config.Params(number)
+	if snapshot := LoadCanonicalSnapshot(db, number); snapshot != nil {
+		return snapshot.Ids(rules.PingTimeout), nil
+	}
+	return GetIdsByBlockNumber(contract, number, rules.PingTimeout, rules.MaxWitnessSize)
+}
+
+// DeleteSnapshot removes the snapshot of the given block from the database.
+func DeleteSnapshot(db ethdb.Deleter, hash common.Hash) error {
+	return db.Delete(append(snapshotPrefix, hash[:]...))
+}
+
+// snapshotHashesKey = snapshotHashesPrefix + num (uint64 big endian)
+func snapshotHashesKey(number uint64) []byte {
+	key := make([]byte, len(snapshotHashesPrefix)+8)
+	copy(key, snapshotHashesPrefix)
+	binary.BigEndian.PutUint64(key[len(snapshotHashesPrefix):], number)
+	return key
+}
+
+// ReadSnapshotHashes retrieves the hashes of all the blocks with the given number
+// a snapshot was stored for, canonical or not.
+func ReadSnapshotHashes(db ethdb.Database, number uint64) []common.Hash {
+	blob, err := db.Get(snapshotHashesKey(number))
+	if err != nil {
+		return nil
+	}
+	var hashes []common.Hash
+	if err := rlp.DecodeBytes(blob, &hashes); err != nil {
+		return nil
+	}
+	return hashes
+}
+
+// WriteSnapshotHashes stores the hashes of the blocks with the given number a
+// snapshot was stored for.
+func WriteSnapshotHashes(db ethdb.Putter, number uint64, hashes []common.Hash) error {
+	blob, err := rlp.EncodeToBytes(hashes)
+	if err != nil {
+		return err
+	}
+	return db.Put(snapshotHashesKey(number), blob)
+}
+
+// DeleteSnapshotHashes removes the tracked snapshot hashes of the given number.
+func DeleteSnapshotHashes(db ethdb.Deleter, number uint64) error {
+	return db.Delete(snapshotHashesKey(number))
+}
+
+// Store inserts the snapshot into the database.
+func (s *Snapshot) Store(db ethdb.Putter) error {
+	blob, err := rlp.EncodeToBytes(s)
+	if err != nil {
+		return err
+	}
+	return db.Put(append(snapshotPrefix, s.Hash[:]...), blob)
+}
+
+// Copy creates a deep copy of the snapshot.
+func (s *Snapshot) Copy() *Snapshot {
+	cpy := &Snapshot{
+		Number: s.Number,
+		Hash:   s.Hash,
+		Nodes:  make([]*SnapshotNode, len(s.Nodes)),
+	}
+	for i, node := range s.Nodes {
+		n := *node
+		cpy.Nodes[i] = &n
+	}
+	return cpy
+}
+
+// Apply creates a new snapshot of the given block by applying the logs of its
+// transactions to the snapshot of the parent block. The join event only carries
+// the id of the masternode, so its public key is retrieved through nodeID.
+func (s *Snapshot) Apply(header *types.Header, logs []*types.Log, nodeID func(log *types.Log, id [8]byte) (discv5.NodeID, error)) (*Snapshot, error) {
+	number := header.Number.Uint64()
+	if number != s.Number+1 || header.ParentHash != s.Hash {
+		return nil, fmt.Errorf("snapshot of block #%d [%x…] not the parent of #%d", s.Number, s.Hash[:4], number)
+	}
+	snap := s.Copy()
+	snap.Number, snap.Hash = number, header.Hash()
+
+	for _, log := range logs {
+		if log.Address != params.MasterndeContractAddress || len(log.Topics) == 0 {
+			continue
+		}
+		switch log.Topics[0] {
+		case contractABI.Events[JoinEvent].Id():
+			event := new(MembershipLog)
+			if err := contractABI.Unpack(event, JoinEvent, log.Data); err != nil {
+				return nil, err
+			}
+			id, err := nodeID(log, event.Id)
+			if err != nil {
+				return nil, err
+			}
+			node := &SnapshotNode{
+				Id:          event.Id,
+				NodeID:      id,
+				Account:     event.Addr,
+				OriginBlock: number,
+			}
+			snap.Nodes = append([]*SnapshotNode{node}, snap.Nodes...)
+
+		case contractABI.Events[QuitEvent].Id():
+			event := new(MembershipLog)
+			if err := contractABI.Unpack(event, QuitEvent, log.Data); err != nil {
+				return nil, err
+			}
+			for i, node := range snap.Nodes {
+				if node.Id == event.Id {
+					snap.Nodes = append(snap.Nodes[:i], snap.Nodes[i+1:]...)
+					break
+				}
+			}
+
+		case contractABI.Events[PingEvent].Id():
+			var event struct {
+				Id             [8]byte
+				BlockOnlineAcc *big.Int
+				BlockLastPing  *big.Int
+			}
+			if err := contractABI.Unpack(&event, PingEvent, log.Data); err != nil {
+				return nil, err
+			}
+			for _, node := range snap.Nodes {
+				if node.Id == event.Id {
+					node.OnlineAcc = event.BlockOnlineAcc.Uint64()
+					node.LastPing = event.BlockLastPing.Uint64()
+					break
+				}
+			}
+		}
+	}
+	return snap, nil
+}
+
+// Ids returns the ids of the masternodes which pinged the contract within
+// pingTimeout blocks before the snapshot block, along with the genesis
+// masternodes which never pinged, in the order of GetIdsByBlockNumber. As the
+// snapshot always holds the complete list, the top-up of GetIdsByBlockNumber
+// for lists shorter than the witness size never adds any further node.
+func (s *Snapshot) Ids(pingTimeout uint64) []string {
+	var ids []string
+	for _, node := range s.Nodes {
+		if node.LastPing > 0 {
+			if s.Number-node.LastPing > pingTimeout {
+				continue
+			}
+		} else if node.OriginBlock > 0 {
+			continue
+		}
+		ids = append(ids, fmt.Sprintf("%x", node.Id))
+	}
+	return ids
+}
+
+// Masternodes returns all masternodes registered in the contract at the
+// snapshot block, in the order of GetMasternodes.
+func (s *Snapshot) Masternodes() []*Masternode {
+	nodes := make([]*Masternode, 0, len(s.Nodes))
+	for _, node := range s.Nodes {
+		nodes = append(nodes, newMasternode(node.NodeID, node.Account, new(big.Int).SetUint64(node.OriginBlock),
+			new(big.Int).SetUint64(node.OnlineAcc), new(big.Int).SetUint64(node.LastPing)))
+	}
+	return nodes
+}
@@ -0,0 +1,168 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package masternode
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/etherzero/go-etherzero"
+	"github.com/etherzero/go-etherzero/accounts/abi/bind"
+	"github.com/etherzero/go-etherzero/accounts/abi/bind/backends"
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/contracts/masternode/contract"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/p2p/discv5"
+	"github.com/etherzero/go-etherzero/p2p/enode"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// Tests that snapshots built from the masternode contract logs list the same
+// masternodes, in the same order, as the contract itself.
+func TestSnapshotMatchesContract(t *testing.T) {
+	var (
+		genesisKey, _ = crypto.GenerateKey()
+		nodeKeyA, _   = crypto.GenerateKey()
+		nodeKeyB, _   = crypto.GenerateKey()
+		ownerKeyA, _  = crypto.GenerateKey()
+		ownerKeyB, _  = crypto.GenerateKey()
+	)
+	url := enode.NewV4(&genesisKey.PublicKey, net.ParseIP("127.0.0.1"), 30303, 30303).String()
+	balance := new(big.Int).Mul(big.NewInt(50000), big.NewInt(params.Ether))
+	alloc := core.GenesisAlloc{
+		params.MasterndeContractAddress:              core.MasternodeContractAccount([]string{url}, common.Address{}),
+		crypto.PubkeyToAddress(genesisKey.PublicKey): {Balance: balance},
+		crypto.PubkeyToAddress(ownerKeyA.PublicKey):  {Balance: balance},
+		crypto.PubkeyToAddress(ownerKeyB.PublicKey):  {Balance: balance},
+	}
+	sim := backends.NewDevoteSimulatedBackend(alloc, 10000000, []string{"0123456789abcdef"})
+	sim.Commit()
+
+	masternodes, err := contract.NewContract(params.MasterndeContractAddress, sim)
+	if err != nil {
+		t.Fatalf("failed to bind contract: %v", err)
+	}
+	nodes, err := GetMasternodes(masternodes, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("failed to list genesis masternodes: %v", err)
+	}
+	snap := NewSnapshot(1, common.Hash{}, nodes)
+
+	bound := bind.NewBoundContract(params.MasterndeContractAddress, contractABI, sim, sim, sim)
+	register := func(owner, node *ecdsa.PrivateKey) {
+		var id1, id2 [32]byte
+		copy(id1[:], crypto.FromECDSAPub(&node.PublicKey)[1:33])
+		copy(id2[:], crypto.FromECDSAPub(&node.PublicKey)[33:])
+
+		auth := bind.NewKeyedTransactor(owner)
+		auth.Value = DepositAmount
+		auth.GasLimit = 500000
+		if _, err := bound.Transact(auth, "register", id1, id2); err != nil {
+			t.Fatalf("failed to register masternode: %v", err)
+		}
+	}
+	transfer := func(key *ecdsa.PrivateKey) {
+		auth := bind.NewKeyedTransactor(key)
+		auth.GasLimit = 200000
+		if _, err := bound.Transfer(auth); err != nil {
+			t.Fatalf("failed to send contract transfer: %v", err)
+		}
+	}
+	steps := []func(){
+		func() { register(ownerKeyA, nodeKeyA); register(ownerKeyB, nodeKeyB) },
+		func() { transfer(nodeKeyA) },
+		func() { transfer(genesisKey); transfer(nodeKeyB) },
+		func() { transfer(ownerKeyB) },
+		func() {},
+		func() { transfer(nodeKeyA) },
+		func() {},
+		func() {},
+		func() {},
+	}
+	const pingTimeout = 2
+	for i, step := range steps {
+		step()
+		sim.Commit()
+
+		number := big.NewInt(int64(i + 2))
+		logs, err := sim.FilterLogs(context.Background(), ethereum.FilterQuery{FromBlock: number, ToBlock: number})
+		if err != nil {
+			t.Fatalf("block %d: failed to filter logs: %v", number, err)
+		}
+		var entries []*types.Log
+		for j := range logs {
+			entries = append(entries, &logs[j])
+		}
+		nodeID := func(log *types.Log, id [8]byte) (discv5.NodeID, error) {
+			node, err := GetMasternode(masternodes, id, number)
+			if err != nil {
+				return discv5.NodeID{}, err
+			}
+			return node.NodeID, nil
+		}
+		header := &types.Header{Number: number, ParentHash: snap.Hash}
+		if snap, err = snap.Apply(header, entries, nodeID); err != nil {
+			t.Fatalf("block %d: failed to apply logs: %v", number, err)
+		}
+		want, err := GetIdsByBlockNumber(masternodes, number, pingTimeout, 21)
+		if err != nil {
+			t.Fatalf("block %d: failed to list contract ids: %v", number, err)
+		}
+		if have := snap.Ids(pingTimeout); !reflect.DeepEqual(have, want) {
+			t.Errorf("block %d: ids mismatch: have %v, want %v", number, have, want)
+		}
+		contractNodes, err := GetMasternodes(masternodes, number)
+		if err != nil {
+			t.Fatalf("block %d: failed to list contract masternodes: %v", number, err)
+		}
+		if have, want := summarize(snap.Masternodes()), summarize(contractNodes); !reflect.DeepEqual(have, want) {
+			t.Errorf("block %d: masternodes mismatch: have %v, want %v", number, have, want)
+		}
+	}
+	if len(snap.Nodes) != 2 || len(snap.Ids(pingTimeout)) != 0 {
+		t.Errorf("unexpected final snapshot: %d nodes, ids %v", len(snap.Nodes), snap.Ids(pingTimeout))
+	}
+	// Snapshots must survive a database round trip
+	db := ethdb.NewMemDatabase()
+	if err := snap.Store(db); err != nil {
+		t.Fatalf("failed to store snapshot: %v", err)
+	}
+	stored, err := LoadSnapshot(db, snap.Hash)
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %v", err)
+	}
+	if !reflect.DeepEqual(stored, snap) {
+		t.Errorf("stored snapshot mismatch: have %+v, want %+v", stored, snap)
+	}
+}
+
+// summarize renders the contract fields of masternodes for comparison.
+func summarize(nodes []*Masternode) []string {
+	var summary []string
+	for _, n := range nodes {
+		summary = append(summary, fmt.Sprintf("%s %x %x origin %v online %v ping %v", n.ID, n.NodeID, n.Account, n.OriginBlock, n.BlockOnlineAcc, n.BlockLastPing))
+	}
+	return summary
+}
@@ -23,10 +23,12 @@ import (
 	"github.com/etherzero/go-etherzero/accounts"
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/common/math"
+	"github.com/etherzero/go-etherzero/consensus/devote"
 	"github.com/etherzero/go-etherzero/core"
 	"github.com/etherzero/go-etherzero/core/bloombits"
 	"github.com/etherzero/go-etherzero/core/state"
 	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
 	"github.com/etherzero/go-etherzero/core/vm"
 	"github.com/etherzero/go-etherzero/eth/downloader"
 	"github.com/etherzero/go-etherzero/eth/gasprice"
@@ -70,6 +72,9 @@ func (b *EthAPIBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNum
 	if blockNr == rpc.LatestBlockNumber {
 		return b.eth.blockchain.CurrentBlock().Header(), nil
 	}
+	if blockNr == rpc.FinalizedBlockNumber {
+		return b.eth.blockchain.CurrentFinalizedHeader(), nil
+	}
 	return b.eth.blockchain.GetHeaderByNumber(uint64(blockNr)), nil
 }
 
@@ -87,6 +92,13 @@ func (b *EthAPIBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumb
 	if blockNr == rpc.LatestBlockNumber {
 		return b.eth.blockchain.CurrentBlock(), nil
 	}
+	if blockNr == rpc.FinalizedBlockNumber {
+		header := b.eth.blockchain.CurrentFinalizedHeader()
+		if header == nil {
+			return nil, nil
+		}
+		return b.eth.blockchain.GetBlock(header.Hash(), header.Number.Uint64()), nil
+	}
 	return b.eth.blockchain.GetBlockByNumber(uint64(blockNr)), nil
 }
 
@@ -146,6 +158,10 @@ func (b *EthAPIBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Sub
 	return b.eth.BlockChain().SubscribeChainEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeChainFinalizedEvent(ch chan<- core.ChainFinalizedEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeChainFinalizedEvent(ch)
+}
+
 func (b *EthAPIBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
 	return b.eth.BlockChain().SubscribeChainHeadEvent(ch)
 }
@@ -190,6 +206,10 @@ func (b *EthAPIBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.eth.TxPool().Content()
 }
 
+func (b *EthAPIBackend) TxPoolWaiting() map[common.Hash]uint64 {
+	return b.eth.TxPool().Waiting()
+}
+
 func (b *EthAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.TxPool().SubscribeNewTxsEvent(ch)
 }
@@ -229,57 +249,69 @@ func (b *EthAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.Ma
 	}
 }
 
-// Masternodes return masternode info
-func (b *EthAPIBackend) Masternodes() []string {
-	list, _ := b.eth.masternodeManager.MasternodeList(b.eth.blockchain.CurrentBlock().Number())
-	return list
+// Masternodes returns all masternodes registered in the contract at the given block
+func (b *EthAPIBackend) Masternodes(ctx context.Context, blockNr rpc.BlockNumber) ([]*masternode.Masternode, error) {
+	header, err := b.HeaderByNumber(ctx, blockNr)
+	if header == nil || err != nil {
+		return nil, err
+	}
+	return b.eth.masternodeManager.Masternodes(header.Number)
 }
 
 // GetInfo return related info in masternode contract
-func (b *EthAPIBackend) GetInfo(nodeid string) string {
+func (b *EthAPIBackend) GetInfo(ctx context.Context, nodeid string, blockNr rpc.BlockNumber) (*masternode.Masternode, error) {
 	var id [8]byte
 	node, err := hex.DecodeString(strings.TrimPrefix(nodeid, "0x"))
 	if err != nil {
-		fmt.Printf("err %v\n", err)
-		return ""
+		return nil, err
 	} else if len(node) != len(id) {
-		return ""
+		return nil, fmt.Errorf("invalid masternode id length %d, want %d", len(node), len(id))
 	}
 	copy(id[:], node)
-	fmt.Println("nodeid ", nodeid)
 
-	info, err := b.eth.masternodeManager.contract.GetInfo(nil, id)
-	if err != nil {
-		fmt.Errorf("contract.Has", "error", err)
-		return ""
+	header, err := b.HeaderByNumber(ctx, blockNr)
+	if header == nil || err != nil {
+		return nil, err
+	}
+	return b.eth.masternodeManager.MasternodeInfo(id, header.Number)
+}
+
+// Governance returns the governance voting state of the masternode contract
+func (b *EthAPIBackend) Governance(ctx context.Context, blockNr rpc.BlockNumber) (*masternode.Governance, error) {
+	header, err := b.HeaderByNumber(ctx, blockNr)
+	if header == nil || err != nil {
+		return nil, err
 	}
+	return b.eth.masternodeManager.Governance(header.Number)
+}
 
-	return fmt.Sprintf("Id1: %v,Id2:%v,PreId:0x%v,NextId:0x%v,BlockNumber:%v,Account:%v,BlockOnlineAcc:%v,BloakLastPing:%v",
-		common.BytesToHash(info.Id1[:]).String(), common.BytesToHash(info.Id2[:]).String(), common.Bytes2Hex(info.PreId[:]), common.Bytes2Hex(info.NextId[:]), info.BlockNumber.String(), info.Account.String(),
-		info.BlockOnlineAcc.String(), info.BlockLastPing.String())
+// GovernanceVoted reports whether the voter voted for a governance proposal
+func (b *EthAPIBackend) GovernanceVoted(ctx context.Context, proposal, voter common.Address, blockNr rpc.BlockNumber) (bool, error) {
+	header, err := b.HeaderByNumber(ctx, blockNr)
+	if header == nil || err != nil {
+		return false, err
+	}
+	return b.eth.masternodeManager.HasVoted(proposal, voter, header.Number)
 }
 
 // Data
 // Masternodes return masternode contract data
-func (b *EthAPIBackend) Data() (strPromotion string) {
-	if b.eth.masternodeManager.srvr.Self() == nil {
-		strPromotion = "wait for more 10 seconds to initial the geth"
-		return
+func (b *EthAPIBackend) Data() (string, error) {
+	active := b.eth.masternodeManager.active
+	if active == nil {
+		return "wait for more 10 seconds to initial the geth", nil
 	}
-	xy := b.eth.masternodeManager.srvr.Self().XY()
-
-	var id [8]byte
-	copy(id[:], xy[0:8])
-	has, err := b.eth.masternodeManager.contract.Has(nil, id)
+	xy := active.NodeID
+	has, err := b.eth.masternodeManager.contract.Has(nil, active.X8())
 	if err != nil {
-		fmt.Errorf("contract.Has error %v", err)
-		return
+		return "", fmt.Errorf("contract.Has error %v", err)
 	}
+	var strPromotion string
 	if has {
 		strPromotion = fmt.Sprintf("### It's already been a masternode!,don't send your masternode data any more!")
 	}
 	data := "0x2f926732" + common.Bytes2Hex(xy[:])
-	return fmt.Sprintf("%v your masternode data is %v", strPromotion, data)
+	return fmt.Sprintf("%v your masternode data is %v", strPromotion, data), nil
 }
 
 // Masternodes return masternode contract data
@@ -287,14 +319,37 @@ func (b *EthAPIBackend) Ns() int64 {
 	return discover.NanoDrift()
 }
 
-// StartMasternode
-// TODO StartMasternode just call the start function of instantx
-func (b *EthAPIBackend) StartMasternode() bool {
-	return true
+// StartMasternode starts pinging the masternode contract for the local node
+func (b *EthAPIBackend) StartMasternode() error {
+	return b.eth.masternodeManager.StartMasternode()
 }
 
-// StopMasternode
-// TODO stop masternode
-func (b *EthAPIBackend) StopMasternode() bool {
-	return true
+// StopMasternode stops pinging the masternode contract for the local node
+func (b *EthAPIBackend) StopMasternode() error {
+	return b.eth.masternodeManager.StopMasternode()
+}
+
+// ActiveMasternode returns the identity of the local masternode
+func (b *EthAPIBackend) ActiveMasternode() (*masternode.ActiveMasternode, error) {
+	return b.eth.masternodeManager.Active()
+}
+
+// MasternodePinging reports whether the local masternode pings the contract
+func (b *EthAPIBackend) MasternodePinging() bool {
+	return b.eth.masternodeManager.Pinging()
+}
+
+// MasternodePingState returns the ping schedule of the local masternode
+func (b *EthAPIBackend) MasternodePingState() *masternode.PingState {
+	state := b.eth.masternodeManager.PingState()
+	return &state
+}
+
+// DoubleSignEvidence returns the double signing evidence collected by the devote
+// engine, nothing for other engines
+func (b *EthAPIBackend) DoubleSignEvidence() ([]*devote.DoubleSignEvidence, error) {
+	if engine, ok := b.eth.engine.(*devote.Devote); ok {
+		return engine.Evidence()
+	}
+	return nil, nil
 }
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"runtime"
 	"sync"
 	"time"
@@ -570,6 +571,44 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Ha
 	return api.traceTx(ctx, msg, vmctx, statedb, config)
 }
 
+// TraceCall returns the structured logs created during the execution of the given
+// call on top of the requested block and returns them as a JSON object. The call
+// is not required to be signed or to be valid on chain, allowing to debug it
+// before broadcasting.
+func (api *PrivateDebugAPI) TraceCall(ctx context.Context, args ethapi.CallArgs, number rpc.BlockNumber, config *TraceConfig) (interface{}, error) {
+	// Fetch the block that we want to trace on top of, along with its state
+	var (
+		block   *types.Block
+		statedb *state.StateDB
+		err     error
+	)
+	if number == rpc.PendingBlockNumber {
+		block, statedb = api.eth.miner.Pending()
+	} else if block, err = api.eth.APIBackend.BlockByNumber(ctx, number); err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	if statedb == nil {
+		reexec := defaultTraceReexec
+		if config != nil && config.Reexec != nil {
+			reexec = *config.Reexec
+		}
+		if statedb, err = api.computeStateDB(block, reexec); err != nil {
+			return nil, err
+		}
+	}
+	// Assemble the call message, capping the gas allowance at the block limit. Gas
+	// is free unless a price is requested, so the sender needs no power by default
+	// and the traced state is left untouched.
+	msg := args.ToMessage(api.eth.AccountManager(), block.GasLimit(), new(big.Int))
+
+	// Trace the call and return
+	vmctx := core.NewEVMContext(msg, block.Header(), api.eth.blockchain, nil)
+	return api.traceTx(ctx, msg, vmctx, statedb, config)
+}
+
 // traceTx configures a new tracer according to the provided configuration, and
 // executes the given message in the provided environment. The return value will
 // be tracer dependent.
@@ -0,0 +1,192 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/consensus/ethash"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/vm"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/internal/ethapi"
+	"github.com/etherzero/go-etherzero/params"
+	"github.com/etherzero/go-etherzero/rpc"
+)
+
+// Tests that calls are traced on top of the state of historical blocks, both by
+// the struct logger and by JavaScript tracers, including calls which revert.
+func TestTraceCall(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0x1000")
+
+		// Stores the call data in slot 0 if any, otherwise returns slot 0 or
+		// reverts if it is empty
+		code = common.FromHex("0x366019576000548060105760006000fd5b60005260206000f35b60003560005500")
+
+		db    = ethdb.NewMemDatabase()
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				addr:     {Balance: big.NewInt(params.Ether)},
+				contract: {Balance: common.Big0, Code: code},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	// Set the slot of the contract in the second block only
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 2, func(i int, block *core.BlockGen) {
+		if i == 1 {
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(addr), contract, common.Big0, 50000, common.Big1, common.LeftPadBytes([]byte{42}, 32)), signer, key)
+			block.AddTx(tx)
+		}
+	})
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	eth := &Ethereum{chainDb: db, blockchain: chain, chainConfig: gspec.Config}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+
+	api := NewPrivateDebugAPI(gspec.Config, eth)
+	args := ethapi.CallArgs{From: addr, To: &contract}
+
+	// The struct logger reports the revert on top of the first block
+	res, err := api.TraceCall(context.Background(), args, rpc.BlockNumber(1), nil)
+	if err != nil {
+		t.Fatalf("failed to trace call: %v", err)
+	}
+	result := res.(*ethapi.ExecutionResult)
+	if !result.Failed || result.ReturnValue != "" {
+		t.Errorf("historical call result mismatch: failed %v, return %q", result.Failed, result.ReturnValue)
+	}
+	if n := len(result.StructLogs); n == 0 || result.StructLogs[n-1].Op != "REVERT" {
+		t.Errorf("historical call not traced up to the revert: %+v", result.StructLogs)
+	}
+	// ... and the stored value on top of the latest one
+	res, err = api.TraceCall(context.Background(), args, rpc.LatestBlockNumber, nil)
+	if err != nil {
+		t.Fatalf("failed to trace call: %v", err)
+	}
+	result = res.(*ethapi.ExecutionResult)
+	if want := common.Bytes2Hex(common.LeftPadBytes([]byte{42}, 32)); result.Failed || result.ReturnValue != want {
+		t.Errorf("latest call result mismatch: failed %v, return %q, want %q", result.Failed, result.ReturnValue, want)
+	}
+	// The call tracer reports the revert as the error of the top call
+	tracer := "callTracer"
+	res, err = api.TraceCall(context.Background(), args, rpc.BlockNumber(1), &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		t.Fatalf("failed to trace call: %v", err)
+	}
+	var call struct {
+		Type  string         `json:"type"`
+		From  common.Address `json:"from"`
+		To    common.Address `json:"to"`
+		Error string         `json:"error"`
+	}
+	if err := json.Unmarshal(res.(json.RawMessage), &call); err != nil {
+		t.Fatalf("failed to decode call trace: %v", err)
+	}
+	if call.Type != "CALL" || call.From != addr || call.To != contract || !strings.Contains(call.Error, "revert") {
+		t.Errorf("call trace mismatch: %+v", call)
+	}
+	// Unknown blocks are rejected
+	if _, err := api.TraceCall(context.Background(), args, rpc.BlockNumber(3), nil); err == nil {
+		t.Errorf("call traced on top of an unknown block")
+	}
+}
+
+// Tests that calls are traced on top of the block finalized by the consensus
+// engine when requested by its tag.
+func TestTraceCallFinalized(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0x1000")
+
+		// Stores the call data in slot 0 if any, otherwise returns slot 0 or
+		// reverts if it is empty
+		code = common.FromHex("0x366019576000548060105760006000fd5b60005260206000f35b60003560005500")
+
+		witnesses = []string{"0123456789abcdef"}
+		config    = *params.TestChainConfig
+		db        = ethdb.NewMemDatabase()
+		engine    = devote.NewFaker(witnesses, db)
+	)
+	config.Ethash = nil
+	config.Devote = &params.DevoteConfig{Witnesses: witnesses}
+
+	gspec := &core.Genesis{
+		Config:     &config,
+		Difficulty: big.NewInt(1),
+		Alloc: core.GenesisAlloc{
+			addr:     {Balance: new(big.Int).Mul(big.NewInt(50000), big.NewInt(params.Ether))},
+			contract: {Balance: common.Big0, Code: code},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.NewEIP155Signer(config.ChainID)
+
+	// Store the number of every block in the contract, once the sender regenerated
+	// some power
+	blocks, _ := core.GenerateChain(&config, genesis, engine, db, 10, func(i int, block *core.BlockGen) {
+		if i == 0 {
+			return
+		}
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(addr), contract, common.Big0, 50000, common.Big1, common.LeftPadBytes(block.Number().Bytes(), 32)), signer, key)
+		block.AddTx(tx)
+	})
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	finalized := chain.CurrentFinalizedHeader()
+	if finalized == nil || finalized.Number.Uint64() < 2 || finalized.Number.Uint64() == chain.CurrentBlock().NumberU64() {
+		t.Fatalf("finalized block not between genesis and head: %v", finalized)
+	}
+	eth := &Ethereum{chainDb: db, blockchain: chain, chainConfig: &config, engine: engine}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+
+	api := NewPrivateDebugAPI(&config, eth)
+	res, err := api.TraceCall(context.Background(), ethapi.CallArgs{From: addr, To: &contract}, rpc.FinalizedBlockNumber, nil)
+	if err != nil {
+		t.Fatalf("failed to trace call: %v", err)
+	}
+	result := res.(*ethapi.ExecutionResult)
+	if want := common.Bytes2Hex(common.LeftPadBytes(finalized.Number.Bytes(), 32)); result.Failed || result.ReturnValue != want {
+		t.Errorf("finalized call result mismatch: failed %v, return %q, want %q", result.Failed, result.ReturnValue, want)
+	}
+}
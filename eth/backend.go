@@ -46,6 +46,7 @@ import (
 	"github.com/etherzero/go-etherzero/miner"
 	"github.com/etherzero/go-etherzero/node"
 	"github.com/etherzero/go-etherzero/p2p"
+	"github.com/etherzero/go-etherzero/p2p/discover"
 	"github.com/etherzero/go-etherzero/params"
 	"github.com/etherzero/go-etherzero/rlp"
 	"github.com/etherzero/go-etherzero/rpc"
@@ -86,6 +87,8 @@ type Ethereum struct {
 	bloomRequests chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer  *core.ChainIndexer             // Bloom indexer operating during block imports
 
+	masternodeIndexer *core.ChainIndexer // Masternode snapshot indexer operating during block imports
+
 	APIBackend *EthAPIBackend
 
 	miner     *miner.Miner
@@ -182,14 +185,17 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		return nil, err
 	}
 
-	devoteDB, err := devotedb.NewDevoteByProtocol(devotedb.NewDatabase(eth.chainDb), eth.blockchain.CurrentBlock().Header().Protocol)
+	devoteDB, err := devotedb.NewDevoteByProtocol(eth.blockchain.DevoteCache(), eth.blockchain.CurrentBlock().Header().Protocol)
 
 	contractBackend := NewContractBackend(eth)
 	contract, err := contract.NewContract(params.MasterndeContractAddress, contractBackend)
-	if eth.masternodeManager = NewMasternodeManager(devoteDB, eth.blockchain, contract, eth.txPool); err != nil {
+	if eth.masternodeManager = NewMasternodeManager(devoteDB, eth.blockchain, contract, eth.txPool, chainDb); err != nil {
 		return nil, err
 	}
 	eth.protocolManager.mm = eth.masternodeManager
+	if eth.masternodeManager.signer, err = makeMasternodeSigner(config); err != nil {
+		return nil, err
+	}
 
 	if devote, ok := eth.engine.(*devote.Devote); ok {
 		devote.Masternodes(eth.masternodeManager.MasternodeList)
@@ -204,6 +210,10 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		gpoParams.Default = config.MinerGasPrice
 	}
 	eth.APIBackend.gpo = gasprice.NewOracle(eth.APIBackend, gpoParams)
+	eth.masternodeManager.gasPrice = eth.APIBackend.gpo.SuggestPrice
+
+	eth.masternodeIndexer = NewMasternodeIndexer(chainDb, contract)
+	eth.masternodeIndexer.Start(eth.blockchain)
 
 	return eth, nil
 }
@@ -241,7 +251,9 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) (ethdb.Data
 func CreateConsensusEngine(ctx *node.ServiceContext, chainConfig *params.ChainConfig, config *ethash.Config, notify []string, noverify bool, db ethdb.Database) consensus.Engine {
 	// If Masternode is requested, set it up
 	if chainConfig.Devote != nil {
-		return devote.NewDevote(chainConfig.Devote, db)
+		engine := devote.NewDevote(chainConfig.Devote, db)
+		engine.SetClock(devote.NewAdjustedClock(discover.PeerTimeOffset, engine.MaxFutureDrift()))
+		return engine
 	}
 	// If proof-of-authority is requested, set it up
 	if chainConfig.Clique != nil {
@@ -482,7 +494,7 @@ func (s *Ethereum) StartMining(threads int) error {
 			return fmt.Errorf("signer missing: %v", errors.New("Active Masternode is nil"))
 		}
 		if devote, ok := s.engine.(*devote.Devote); ok {
-			devote.Authorize(witness, active.SignHash)
+			devote.Authorize(witness, active.SignHeader)
 		}
 		if clique, ok := s.engine.(*clique.Clique); ok {
 			wallet, err := s.accountManager.Find(accounts.Account{Address: eb})
@@ -531,6 +543,8 @@ func (s *Ethereum) NetVersion() uint64                 { return s.networkID }
 func (s *Ethereum) Downloader() *downloader.Downloader { return s.protocolManager.downloader }
 func (s *Ethereum) DevoteDB() *devotedb.DevoteDB       { return s.masternodeManager.devoteDB }
 
+func (s *Ethereum) MasternodeManager() *MasternodeManager { return s.masternodeManager }
+
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
 func (s *Ethereum) Protocols() []p2p.Protocol {
@@ -587,6 +601,7 @@ func (s *Ethereum) startMasternode(srvr *p2p.Server) {
 // Ethereum protocol.
 func (s *Ethereum) Stop() error {
 	s.bloomIndexer.Close()
+	s.masternodeIndexer.Close()
 	s.blockchain.Stop()
 	s.engine.Close()
 	s.protocolManager.Stop()
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"crypto/ecdsa"
 	"math/big"
 	"os"
 	"os/user"
@@ -110,6 +111,11 @@ type Config struct {
 	MinerRecommit  time.Duration
 	MinerNoverify  bool
 
+	// Masternode options
+	MasternodeKey    *ecdsa.PrivateKey `toml:"-"`          // Masternode signing key, the p2p node key if nil
+	MasternodeSigner string            `toml:",omitempty"` // External signer endpoint holding the masternode key
+	MasternodePubkey string            `toml:",omitempty"` // Public key of the masternode key held by the external signer
+
 	// Ethash options
 	Ethash ethash.Config
 
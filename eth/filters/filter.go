@@ -40,6 +40,7 @@ type Backend interface {
 
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
 	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
+	SubscribeChainFinalizedEvent(ch chan<- core.ChainFinalizedEvent) event.Subscription
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
 
@@ -135,6 +136,18 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	}
 	head := header.Number.Uint64()
 
+	if f.begin == rpc.FinalizedBlockNumber.Int64() || f.end == rpc.FinalizedBlockNumber.Int64() {
+		finalized, _ := f.backend.HeaderByNumber(ctx, rpc.FinalizedBlockNumber)
+		if finalized == nil {
+			return nil, errors.New("no finalized block")
+		}
+		if f.begin == rpc.FinalizedBlockNumber.Int64() {
+			f.begin = finalized.Number.Int64()
+		}
+		if f.end == rpc.FinalizedBlockNumber.Int64() {
+			f.end = finalized.Number.Int64()
+		}
+	}
 	if f.begin == -1 {
 		f.begin = int64(head)
 	}
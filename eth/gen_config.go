@@ -3,6 +3,7 @@
 package eth
 
 import (
+	"crypto/ecdsa"
 	"math/big"
 	"time"
 
@@ -39,6 +40,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		MinerGasPrice           *big.Int
 		MinerRecommit           time.Duration
 		MinerNoverify           bool
+		MasternodeKey           *ecdsa.PrivateKey `toml:"-"`
+		MasternodeSigner        string            `toml:",omitempty"`
+		MasternodePubkey        string            `toml:",omitempty"`
 		Ethash                  ethash.Config
 		TxPool                  core.TxPoolConfig
 		GPO                     gasprice.Config
@@ -68,6 +72,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.MinerGasPrice = c.MinerGasPrice
 	enc.MinerRecommit = c.MinerRecommit
 	enc.MinerNoverify = c.MinerNoverify
+	enc.MasternodeKey = c.MasternodeKey
+	enc.MasternodeSigner = c.MasternodeSigner
+	enc.MasternodePubkey = c.MasternodePubkey
 	enc.Ethash = c.Ethash
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
@@ -101,6 +108,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		MinerGasPrice           *big.Int
 		MinerRecommit           *time.Duration
 		MinerNoverify           *bool
+		MasternodeKey           *ecdsa.PrivateKey `toml:"-"`
+		MasternodeSigner        *string           `toml:",omitempty"`
+		MasternodePubkey        *string           `toml:",omitempty"`
 		Ethash                  *ethash.Config
 		TxPool                  *core.TxPoolConfig
 		GPO                     *gasprice.Config
@@ -173,6 +183,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.MinerNoverify != nil {
 		c.MinerNoverify = *dec.MinerNoverify
 	}
+	if dec.MasternodeKey != nil {
+		c.MasternodeKey = dec.MasternodeKey
+	}
+	if dec.MasternodeSigner != nil {
+		c.MasternodeSigner = *dec.MasternodeSigner
+	}
+	if dec.MasternodePubkey != nil {
+		c.MasternodePubkey = *dec.MasternodePubkey
+	}
 	if dec.Ethash != nil {
 		c.Ethash = *dec.Ethash
 	}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/consensus"
+	"github.com/etherzero/go-etherzero/consensus/devote"
 	"github.com/etherzero/go-etherzero/consensus/misc"
 	"github.com/etherzero/go-etherzero/core"
 	"github.com/etherzero/go-etherzero/core/types"
@@ -167,6 +168,9 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 	manager.downloader = downloader.New(mode, chaindb, manager.eventMux, blockchain, nil, manager.removePeer)
 
 	validator := func(header *types.Header) error {
+		if devoteEngine, ok := engine.(*devote.Devote); ok {
+			devoteEngine.CheckDoubleSign(header)
+		}
 		return engine.VerifyHeader(blockchain, header, true)
 	}
 	heighter := func() uint64 {
@@ -645,6 +649,12 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		request.Block.ReceivedAt = msg.ReceivedAt
 		request.Block.ReceivedFrom = p
 
+		// Propagated blocks conflicting with an already seen seal are proof of
+		// a double signing witness, even if they never make it into the chain
+		if devoteEngine, ok := pm.blockchain.Engine().(*devote.Devote); ok {
+			devoteEngine.CheckDoubleSign(request.Block.Header())
+		}
+
 		// Mark the peer as owning the block and schedule it for import
 		p.MarkBlock(request.Block.Hash())
 		pm.fetcher.Enqueue(p.id, request.Block)
@@ -18,6 +18,8 @@ package eth
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"sync"
@@ -25,21 +27,26 @@ import (
 	"time"
 
 	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/common/hexutil"
 	"github.com/etherzero/go-etherzero/contracts/masternode/contract"
 	"github.com/etherzero/go-etherzero/core"
-	"github.com/etherzero/go-etherzero/core/types"
 	"github.com/etherzero/go-etherzero/core/types/devotedb"
 	"github.com/etherzero/go-etherzero/core/types/masternode"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/eth/downloader"
+	"github.com/etherzero/go-etherzero/ethdb"
 	"github.com/etherzero/go-etherzero/event"
 	"github.com/etherzero/go-etherzero/log"
 	"github.com/etherzero/go-etherzero/p2p"
-	"github.com/etherzero/go-etherzero/params"
-	"github.com/etherzero/go-etherzero/eth/downloader"
 	"github.com/etherzero/go-etherzero/p2p/discover"
 )
 
 var (
 	statsReportInterval = 10 * time.Second // Time interval to report vote pool stats
+
+	errMasternodeNotStarted    = errors.New("masternode manager not started")
+	errMasternodeNotRegistered = errors.New("node is not registered as a masternode")
+	errMasternodeNoPubkey      = errors.New("external masternode signer requires the masternode public key")
 )
 
 type MasternodeManager struct {
@@ -47,6 +54,7 @@ type MasternodeManager struct {
 
 	devoteDB *devotedb.DevoteDB
 	active   *masternode.ActiveMasternode
+	signer   masternode.Signer // Masternode key, the p2p node key is used if nil
 	mu       sync.Mutex
 	// channels for fetcher, syncer, txsyncLoop
 	newPeerCh    chan *peer
@@ -62,9 +70,18 @@ type MasternodeManager struct {
 	txPool *core.TxPool
 
 	downloader *downloader.Downloader
+	pingQuit   chan struct{} // Quit channel of the running ping loop, nil if stopped
+
+	db        ethdb.Database                          // Database persisting the ping in flight and masternode snapshots
+	gasPrice  func(context.Context) (*big.Int, error) // Gas price oracle for pings, the pool minimum if nil
+	pingLock  sync.RWMutex                            // Protects the ping schedule below
+	pending   *pendingPing                            // Ping waiting to be included, nil if none
+	pingState masternode.PingState                    // Latest ping schedule of the local masternode
+	pingWarn  time.Time                               // Time of the last drop warning
+	pingFeed  event.Feed                              // Feed announcing ping scheduler events
 }
 
-func NewMasternodeManager(dp *devotedb.DevoteDB, blockchain *core.BlockChain, contract *contract.Contract, txPool *core.TxPool) *MasternodeManager {
+func NewMasternodeManager(dp *devotedb.DevoteDB, blockchain *core.BlockChain, contract *contract.Contract, txPool *core.TxPool, db ethdb.Database) *MasternodeManager {
 
 	// Create the masternode manager with its initial settings
 	manager := &MasternodeManager{
@@ -74,6 +91,7 @@ func NewMasternodeManager(dp *devotedb.DevoteDB, blockchain *core.BlockChain, co
 		Lifetime:   30 * time.Second,
 		contract:   contract,
 		txPool:     txPool,
+		db:         db,
 	}
 	return manager
 }
@@ -88,17 +106,91 @@ func (self *MasternodeManager) Start(srvr *p2p.Server, peers *peerSet, downloade
 	self.srvr = srvr
 	self.downloader = downloader
 	log.Trace("MasternodeManqager start ")
-	self.active = masternode.NewActiveMasternode(srvr)
+	if self.signer == nil {
+		log.Warn("Signing masternode blocks with the p2p node key, use a dedicated masternode key instead")
+		self.signer = masternode.NewKeySigner(srvr.PrivateKey)
+	}
+	self.active = masternode.NewActiveMasternode(self.signer)
+	log.Info("Masternode signer ready", "id", self.active.ID, "account", self.active.NodeAccount)
 	go self.masternodeLoop()
+	self.startPing()
 }
 
 func (self *MasternodeManager) Stop() {
+	self.stopPing()
+}
+
+// StartMasternode resumes pinging the masternode contract on behalf of the local
+// node. It fails if the local node is not registered in the contract.
+func (self *MasternodeManager) StartMasternode() error {
+	if self.active == nil {
+		return errMasternodeNotStarted
+	}
+	has, err := self.contract.Has(nil, self.active.X8())
+	if err != nil {
+		return err
+	}
+	if !has {
+		return errMasternodeNotRegistered
+	}
+	atomic.StoreUint32(&self.IsMasternode, 1)
+	self.updateActiveMasternode(true)
+	self.startPing()
+	return nil
+}
+
+// StopMasternode stops pinging the masternode contract. The node stays registered
+// but will be dropped from the witness candidates once its last ping times out.
+func (self *MasternodeManager) StopMasternode() error {
+	if self.active == nil {
+		return errMasternodeNotStarted
+	}
+	self.stopPing()
+	self.updateActiveMasternode(false)
+	return nil
+}
 
+// Active returns the local masternode, once the manager has been started.
+func (self *MasternodeManager) Active() (*masternode.ActiveMasternode, error) {
+	if self.active == nil {
+		return nil, errMasternodeNotStarted
+	}
+	return self.active, nil
+}
+
+// Pinging reports whether the ping loop is currently running.
+func (self *MasternodeManager) Pinging() bool {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	return self.pingQuit != nil
+}
+
+func (self *MasternodeManager) startPing() {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.pingQuit != nil {
+		return
+	}
+	self.pingQuit = make(chan struct{})
+	go self.pingLoop(self.pingQuit)
+}
+
+func (self *MasternodeManager) stopPing() {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.pingQuit == nil {
+		return
+	}
+	close(self.pingQuit)
+	self.pingQuit = nil
 }
 
 func (mm *MasternodeManager) masternodeLoop() {
-	xy := mm.srvr.Self().XY()
-	has, err := mm.contract.Has(nil, mm.srvr.Self().X8())
+	xy := mm.active.NodeID
+	has, err := mm.contract.Has(nil, mm.active.X8())
 	if err != nil {
 		log.Error("contract.Has", "error", err)
 	}
@@ -108,8 +200,7 @@ func (mm *MasternodeManager) masternodeLoop() {
 		mm.updateActiveMasternode(true)
 	} else if mm.srvr.IsMasternode {
 		mm.updateActiveMasternode(false)
-		data := "0x2f926732" + common.Bytes2Hex(xy[:])
-		fmt.Printf("### Masternode Transaction Data: %s\n", data)
+		log.Warn("Masternode not registered, deposit with `geth masternode register <owner>`", "id", mm.active.ID)
 	}
 
 	joinCh := make(chan *contract.ContractJoin, 32)
@@ -125,11 +216,8 @@ func (mm *MasternodeManager) masternodeLoop() {
 		return
 	}
 
-	ping := time.NewTimer(masternode.MASTERNODE_PING_INTERVAL)
-	defer ping.Stop()
 	ntp := time.NewTimer(time.Second)
 	defer ntp.Stop()
-	minPower := big.NewInt(20e+14)
 
 	report := time.NewTicker(statsReportInterval)
 	defer report.Stop()
@@ -158,46 +246,31 @@ func (mm *MasternodeManager) masternodeLoop() {
 		case <-ntp.C:
 			ntp.Reset(10 * time.Minute)
 			go discover.CheckClockDrift()
-		case <-ping.C:
-			ping.Reset(masternode.MASTERNODE_PING_INTERVAL)
-			if mm.active.State() != masternode.ACTIVE_MASTERNODE_STARTED {
-				break
-			}
-			if mm.downloader.Synchronising() {
-				break
-			}
-
-			address := mm.active.NodeAccount
-			stateDB, _ := mm.blockchain.State()
-			if stateDB.GetBalance(address).Cmp(big.NewInt(1e+16)) < 0 {
-				fmt.Println("Failed to deposit 0.01 etz to ", address.String())
-				break
-			}
-			if stateDB.GetPower(address, mm.blockchain.CurrentBlock().Number()).Cmp(minPower) < 0 {
-				fmt.Println("Insufficient power for ping transaction.", address.Hex(), mm.blockchain.CurrentBlock().Number().String(), stateDB.GetPower(address, mm.blockchain.CurrentBlock().Number()).String())
-				break
-			}
-			tx := types.NewTransaction(
-				mm.txPool.State().GetNonce(address),
-				params.MasterndeContractAddress,
-				big.NewInt(0),
-				90000,
-				big.NewInt(20e+9),
-				nil,
-			)
-			signed, err := types.SignTx(tx, types.NewEIP155Signer(mm.blockchain.Config().ChainID), mm.active.PrivateKey)
-			if err != nil {
-				fmt.Println("SignTx error:", err)
-				break
-			}
+		}
+	}
+}
 
-			if err := mm.txPool.AddLocal(signed); err != nil {
-				fmt.Println("send ping to txpool error:", err)
-				break
-			}
-			fmt.Println("Send ping message ...")
+// makeMasternodeSigner creates the masternode signer configured for the node, or
+// nil if the masternode should sign with the p2p node key.
+func makeMasternodeSigner(config *Config) (masternode.Signer, error) {
+	switch {
+	case config.MasternodeSigner != "":
+		if config.MasternodePubkey == "" {
+			return nil, errMasternodeNoPubkey
 		}
+		blob, err := hexutil.Decode(config.MasternodePubkey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid masternode public key: %v", err)
+		}
+		pubkey, err := crypto.UnmarshalPubkey(blob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid masternode public key: %v", err)
+		}
+		return masternode.NewExternalSigner(config.MasternodeSigner, pubkey)
+	case config.MasternodeKey != nil:
+		return masternode.NewKeySigner(config.MasternodeKey), nil
 	}
+	return nil, nil
 }
 
 func (mm *MasternodeManager) updateActiveMasternode(isMasternode bool) {
@@ -212,9 +285,40 @@ func (mm *MasternodeManager) updateActiveMasternode(isMasternode bool) {
 }
 
 func (self *MasternodeManager) MasternodeList(number *big.Int) ([]string, error) {
-	return masternode.GetIdsByBlockNumber(self.contract, number)
+	return masternode.GetIds(self.db, self.contract, self.blockchain.Config().Devote, number)
+}
+
+// snapshot retrieves the indexed masternode snapshot of the canonical block with
+// the given number, or nil if the indexer did not store it.
+func (self *MasternodeManager) snapshot(number *big.Int) *masternode.Snapshot {
+	return masternode.LoadCanonicalSnapshot(self.db, number)
 }
 
 func (self *MasternodeManager) GetGovernanceContractAddress(number *big.Int) (common.Address, error) {
 	return masternode.GetGovernanceAddress(self.contract, number)
-}
\ No newline at end of file
+}
+
+// Masternodes returns all masternodes registered in the contract at the given block.
+func (self *MasternodeManager) Masternodes(number *big.Int) ([]*masternode.Masternode, error) {
+	if snapshot := self.snapshot(number); snapshot != nil {
+		return snapshot.Masternodes(), nil
+	}
+	return masternode.GetMasternodes(self.contract, number)
+}
+
+// Governance returns the governance address and proposals of the contract at
+// the given block.
+func (self *MasternodeManager) Governance(number *big.Int) (*masternode.Governance, error) {
+	return masternode.GetGovernance(self.contract, number)
+}
+
+// HasVoted reports whether the voter voted for the governance proposal up to
+// the given block.
+func (self *MasternodeManager) HasVoted(proposal, voter common.Address, number *big.Int) (bool, error) {
+	return masternode.HasVoted(self.contract, proposal, voter, number)
+}
+
+// MasternodeInfo returns the contract record of a single masternode at the given block.
+func (self *MasternodeManager) MasternodeInfo(id [8]byte, number *big.Int) (*masternode.Masternode, error) {
+	return masternode.GetMasternode(self.contract, id, number)
+}
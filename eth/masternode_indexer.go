@@ -0,0 +1,167 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/contracts/masternode/contract"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/rawdb"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/p2p/discv5"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// masternodeSnapshotRetain is the number of recent blocks the masternode
+// snapshots are kept for. Older blocks are served from the contract state.
+var masternodeSnapshotRetain = uint64(8192)
+
+// MasternodeIndexer implements a core.ChainIndexer, following the logs of the
+// masternode contract to store a snapshot of the registered masternodes for
+// every canonical block, sparing the devote engine and the RPC the walk of the
+// contract's linked list in the EVM.
+type MasternodeIndexer struct {
+	db       ethdb.Database       // database instance to write the snapshots into
+	contract *contract.Contract   // masternode contract to seed the snapshots from
+	snapshot *masternode.Snapshot // snapshot of the last header processed, nil to seed
+}
+
+// NewMasternodeIndexer returns a chain indexer that generates masternode
+// snapshots for the canonical chain, one block per section.
+func NewMasternodeIndexer(db ethdb.Database, contract *contract.Contract) *core.ChainIndexer {
+	backend := &MasternodeIndexer{
+		db:       db,
+		contract: contract,
+	}
+	table := ethdb.NewTable(db, string(rawdb.MasternodeIndexPrefix))
+
+	return core.NewChainIndexer(db, table, backend, 1, 0, 0, "masternodes")
+}
+
+// Reset implements core.ChainIndexerBackend, loading the snapshot of the parent
+// block. If it is missing, the snapshot is seeded from the contract state of the
+// next block instead.
+func (m *MasternodeIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
+	m.snapshot = nil
+	if section > 0 {
+		m.snapshot, _ = masternode.LoadSnapshot(m.db, lastSectionHead)
+	}
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, applying the masternode contract
+// logs of a new header to the snapshot.
+func (m *MasternodeIndexer) Process(ctx context.Context, header *types.Header) error {
+	if m.snapshot == nil {
+		nodes, err := masternode.GetMasternodes(m.contract, header.Number)
+		if err != nil {
+			return err
+		}
+		m.snapshot = masternode.NewSnapshot(header.Number.Uint64(), header.Hash(), nodes)
+		return nil
+	}
+	hash, number := header.Hash(), header.Number.Uint64()
+
+	body := rawdb.ReadBody(m.db, hash, number)
+	if body == nil {
+		return fmt.Errorf("block #%d [%x…] body not found", number, hash[:4])
+	}
+	receipts := rawdb.ReadReceipts(m.db, hash, number)
+	if len(receipts) != len(body.Transactions) {
+		return fmt.Errorf("block #%d [%x…] receipts not found", number, hash[:4])
+	}
+	var (
+		logs []*types.Log
+		txs  = make(map[*types.Log]*types.Transaction)
+	)
+	for i, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			logs = append(logs, log)
+			txs[log] = body.Transactions[i]
+		}
+	}
+	// Joining masternodes are registered by their owner calling the contract,
+	// anything else needs a contract call in the state of the block.
+	nodeID := func(log *types.Log, id [8]byte) (discv5.NodeID, error) {
+		if tx := txs[log]; tx.To() != nil && *tx.To() == params.MasterndeContractAddress {
+			if nodeID, ok := masternode.RegisterNodeID(tx.Data()); ok && bytes.Equal(nodeID[:8], id[:]) {
+				return nodeID, nil
+			}
+		}
+		node, err := masternode.GetMasternode(m.contract, id, new(big.Int).SetUint64(number))
+		if err == masternode.ErrUnknownMasternode {
+			// Joined and quit within the same block
+			return discv5.NodeID{}, nil
+		}
+		if err != nil {
+			return discv5.NodeID{}, err
+		}
+		return node.NodeID, nil
+	}
+	snapshot, err := m.snapshot.Apply(header, logs, nodeID)
+	if err != nil {
+		return err
+	}
+	m.snapshot = snapshot
+	return nil
+}
+
+// Commit implements core.ChainIndexerBackend, storing the snapshot of the block
+// and dropping the ones falling out of the retention window. Reorgs leave the
+// snapshots of the replaced blocks behind, so the hashes of all blocks with a
+// snapshot are tracked per number and pruned together with the canonical one.
+func (m *MasternodeIndexer) Commit() error {
+	batch := m.db.NewBatch()
+	if err := m.snapshot.Store(batch); err != nil {
+		return err
+	}
+	hashes := masternode.ReadSnapshotHashes(m.db, m.snapshot.Number)
+	if !containsHash(hashes, m.snapshot.Hash) {
+		if err := masternode.WriteSnapshotHashes(batch, m.snapshot.Number, append(hashes, m.snapshot.Hash)); err != nil {
+			return err
+		}
+	}
+	if m.snapshot.Number >= masternodeSnapshotRetain {
+		number := m.snapshot.Number - masternodeSnapshotRetain
+		for _, hash := range masternode.ReadSnapshotHashes(m.db, number) {
+			if err := masternode.DeleteSnapshot(batch, hash); err != nil {
+				return err
+			}
+		}
+		if err := masternode.DeleteSnapshotHashes(batch, number); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// containsHash reports whether hash is among the given hashes.
+func containsHash(hashes []common.Hash, hash common.Hash) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/contracts/masternode/contract"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
+	"github.com/etherzero/go-etherzero/core/vm"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/p2p/enode"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// Tests that the masternode indexer snapshots the contract for every processed
+// block, and prunes the snapshots of both canonical and reorged blocks once they
+// fall out of the retention window.
+func TestMasternodeIndexer(t *testing.T) {
+	defer func(retain uint64) { masternodeSnapshotRetain = retain }(masternodeSnapshotRetain)
+	masternodeSnapshotRetain = 4
+
+	var (
+		witnesses     = []string{"0123456789abcdef"}
+		genesisKey, _ = crypto.GenerateKey()
+		ownerKey, _   = crypto.GenerateKey()
+		nodeKey, _    = crypto.GenerateKey()
+		owner         = crypto.PubkeyToAddress(ownerKey.PublicKey)
+		url           = enode.NewV4(&genesisKey.PublicKey, net.ParseIP("127.0.0.1"), 30303, 30303).String()
+		balance       = new(big.Int).Mul(big.NewInt(50000), big.NewInt(params.Ether))
+		config        = *params.AllEthashProtocolChanges
+		signer        = types.NewEIP155Signer(config.ChainID)
+		db            = ethdb.NewMemDatabase()
+		engine        = devote.NewFaker(witnesses, db)
+		nodeID        [64]byte
+	)
+	config.Ethash = nil
+	config.Devote = &params.DevoteConfig{Witnesses: witnesses}
+
+	gspec := &core.Genesis{
+		Config:     &config,
+		Difficulty: big.NewInt(1),
+		Alloc: core.GenesisAlloc{
+			params.MasterndeContractAddress: core.MasternodeContractAccount([]string{url}, common.Address{}),
+			owner:                           {Balance: balance},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	// Register a second masternode in block 2
+	copy(nodeID[:], crypto.FromECDSAPub(&nodeKey.PublicKey)[1:])
+	input, _ := masternode.RegisterInput(nodeID)
+	registration, _ := types.SignTx(types.NewTransaction(0, params.MasterndeContractAddress, masternode.DepositAmount, 500000, big.NewInt(params.GWei), input), signer, ownerKey)
+
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, engine, db, 6, func(i int, block *core.BlockGen) {
+		if i == 1 {
+			block.AddTx(registration)
+		}
+	})
+	masternodes, err := contract.NewContract(params.MasterndeContractAddress, NewContractBackend(&Ethereum{chainDb: db, blockchain: chain, chainConfig: gspec.Config}))
+	if err != nil {
+		t.Fatalf("failed to bind masternode contract: %v", err)
+	}
+	indexer := &MasternodeIndexer{db: db, contract: masternodes}
+
+	// index processes the given blocks the way the chain indexer does with a
+	// section size of one block
+	index := func(blocks []*types.Block) {
+		for _, block := range blocks {
+			number := block.NumberU64()
+			if err := indexer.Reset(context.Background(), number, block.ParentHash()); err != nil {
+				t.Fatalf("block %d: failed to reset indexer: %v", number, err)
+			}
+			if err := indexer.Process(context.Background(), block.Header()); err != nil {
+				t.Fatalf("block %d: failed to process: %v", number, err)
+			}
+			if err := indexer.Commit(); err != nil {
+				t.Fatalf("block %d: failed to commit: %v", number, err)
+			}
+		}
+	}
+	// stored checks the presence of the snapshots of the given blocks
+	stored := func(stage string, want bool, blocks ...*types.Block) {
+		for _, block := range blocks {
+			_, err := masternode.LoadSnapshot(db, block.Hash())
+			if have := err == nil; have != want {
+				t.Errorf("%s: block %d [%x…] snapshot presence mismatch: have %v, want %v", stage, block.NumberU64(), block.Hash().Bytes()[:4], have, want)
+			}
+		}
+	}
+	// Index the original chain from the genesis block on, checking the snapshots
+	// against the contract
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	index(append([]*types.Block{genesis}, blocks...))
+
+	for _, block := range blocks[2:] {
+		snapshot, err := masternode.LoadSnapshot(db, block.Hash())
+		if err != nil {
+			t.Errorf("block %d: snapshot missing: %v", block.NumberU64(), err)
+			continue
+		}
+		nodes, err := masternode.GetMasternodes(masternodes, block.Number())
+		if err != nil {
+			t.Fatalf("block %d: failed to list contract masternodes: %v", block.NumberU64(), err)
+		}
+		if have, want := masternodeOwners(snapshot.Masternodes()), masternodeOwners(nodes); len(have) != 2 || !reflect.DeepEqual(have, want) {
+			t.Errorf("block %d: masternodes mismatch: have %v, want %v", block.NumberU64(), have, want)
+		}
+	}
+	stored("original chain", false, append([]*types.Block{genesis}, blocks[:2]...)...)
+	stored("original chain", true, blocks[2:]...)
+
+	// Reorg onto a longer fork from block 2, leaving the snapshots of the replaced
+	// blocks behind
+	forks, _ := core.GenerateChain(gspec.Config, blocks[1], engine, db, 8, func(i int, block *core.BlockGen) {
+		if i == 0 {
+			tx, _ := types.SignTx(types.NewTransaction(1, common.Address{1}, common.Big1, params.TxGas, big.NewInt(params.GWei), nil), signer, ownerKey)
+			block.AddTx(tx)
+		}
+	})
+	if _, err := chain.InsertChain(forks); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	index(forks[:6])
+	stored("fork to #8", false, blocks[2:4]...)
+	stored("fork to #8", false, forks[:2]...)
+	stored("fork to #8", true, blocks[4:]...)
+	stored("fork to #8", true, forks[2:6]...)
+
+	// Pruning the fork drops the replaced blocks along with the canonical ones
+	index(forks[6:])
+	stored("fork to #10", false, blocks[2:]...)
+	stored("fork to #10", false, forks[:4]...)
+	stored("fork to #10", true, forks[4:]...)
+
+	for number := uint64(0); number <= 6; number++ {
+		if hashes := masternode.ReadSnapshotHashes(db, number); len(hashes) != 0 {
+			t.Errorf("block %d: pruned snapshot hashes still tracked: %x", number, hashes)
+		}
+	}
+	if hashes := masternode.ReadSnapshotHashes(db, 7); !reflect.DeepEqual(hashes, []common.Hash{forks[4].Hash()}) {
+		t.Errorf("tracked snapshot hashes mismatch: have %x, want %x", hashes, forks[4].Hash())
+	}
+}
+
+// masternodeOwners renders the ids and owners of masternodes for comparison.
+func masternodeOwners(nodes []*masternode.Masternode) []string {
+	var owners []string
+	for _, node := range nodes {
+		owners = append(owners, fmt.Sprintf("%s %x", node.ID, node.Account))
+	}
+	return owners
+}
@@ -0,0 +1,368 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/common/hexutil"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/state"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
+	"github.com/etherzero/go-etherzero/core/vm"
+	"github.com/etherzero/go-etherzero/event"
+	"github.com/etherzero/go-etherzero/log"
+	"github.com/etherzero/go-etherzero/metrics"
+	"github.com/etherzero/go-etherzero/params"
+	"github.com/etherzero/go-etherzero/rlp"
+)
+
+const (
+	pingCheckInterval = 15 * time.Second // Interval to re-evaluate the ping schedule
+	pingWarnInterval  = time.Minute      // Minimum time between two drop warnings
+	pingRetryBlocks   = 60               // Blocks to wait for a ping to be included before replacing it
+	pingPriceBump     = 25               // Gas price increase of a replacement ping, in percent
+	pingGasFallback   = 90000            // Gas allowance of a ping that cannot be estimated
+)
+
+// Kinds of ping scheduler events.
+const (
+	PingSent      = "sent"
+	PingReplaced  = "replaced"
+	PingConfirmed = "confirmed"
+	PingFailed    = "failed"
+)
+
+var (
+	pingSentMeter      = metrics.NewRegisteredMeter("masternode/ping/sent", nil)
+	pingReplacedMeter  = metrics.NewRegisteredMeter("masternode/ping/replaced", nil)
+	pingConfirmedMeter = metrics.NewRegisteredMeter("masternode/ping/confirmed", nil)
+	pingFailedMeter    = metrics.NewRegisteredMeter("masternode/ping/failed", nil)
+	pingLastGauge      = metrics.NewRegisteredGauge("masternode/ping/last", nil)
+	pingDeadlineGauge  = metrics.NewRegisteredGauge("masternode/ping/deadline", nil)
+)
+
+// pingKey is the database key the ping in flight is stored under, so that it is
+// tracked and replaced across restarts.
+var pingKey = []byte("masternode-ping")
+
+var (
+	errPingBalance = errors.New("masternode account holds less than 0.01 etz")
+	errPingFailed  = errors.New("ping transaction was included without being recorded")
+)
+
+// PingEvent is posted whenever the ping scheduler sends, replaces, confirms or
+// fails to send a ping.
+type PingEvent struct {
+	Kind  string
+	Tx    common.Hash
+	State masternode.PingState
+	Err   error
+}
+
+// pendingPing is a ping transaction waiting to be included.
+type pendingPing struct {
+	Hash     common.Hash
+	Nonce    uint64
+	GasPrice *big.Int
+	Block    uint64 // Head block when the ping was sent
+}
+
+// PingState returns the current ping schedule of the local masternode.
+func (mm *MasternodeManager) PingState() masternode.PingState {
+	mm.pingLock.RLock()
+	defer mm.pingLock.RUnlock()
+
+	return mm.pingState
+}
+
+// SubscribePingEvent registers a subscription of PingEvent.
+func (mm *MasternodeManager) SubscribePingEvent(ch chan<- PingEvent) event.Subscription {
+	return mm.scope.Track(mm.pingFeed.Subscribe(ch))
+}
+
+// pingLoop re-evaluates the ping schedule of the local masternode until quit
+// is closed.
+func (mm *MasternodeManager) pingLoop(quit chan struct{}) {
+	mm.loadPendingPing()
+
+	check := time.NewTicker(pingCheckInterval)
+	defer check.Stop()
+
+	for {
+		select {
+		case <-quit:
+			return
+		case <-check.C:
+			mm.checkPing()
+		}
+	}
+}
+
+// checkPing pings the masternode contract once a third of the ping timeout has
+// passed since the last recorded ping, and replaces pings which got stuck.
+func (mm *MasternodeManager) checkPing() {
+	if mm.active.State() != masternode.ACTIVE_MASTERNODE_STARTED || mm.downloader.Synchronising() {
+		return
+	}
+	head := mm.blockchain.CurrentBlock()
+	number := head.NumberU64()
+
+	node, err := masternode.GetMasternode(mm.contract, mm.active.X8(), head.Number())
+	if err != nil {
+		mm.pingFailed(masternode.PingState{}, err)
+		return
+	}
+	last := node.BlockLastPing.Uint64()
+	if last == 0 {
+		last = node.OriginBlock.Uint64()
+	}
+	timeout := mm.blockchain.Config().Devote.Params(head.Number()).PingTimeout
+	schedule := masternode.PingState{
+		LastPing: hexutil.Uint64(last),
+		NextPing: hexutil.Uint64(last + timeout/3),
+		Deadline: hexutil.Uint64(last + timeout),
+	}
+	pingLastGauge.Update(int64(last))
+	pingDeadlineGauge.Update(int64(schedule.Deadline) - int64(number))
+
+	mm.pingLock.Lock()
+	pending := mm.pending
+	mm.pingLock.Unlock()
+
+	if pending != nil {
+		statedb, err := mm.blockchain.State()
+		if err != nil {
+			mm.pingFailed(schedule, err)
+			return
+		}
+		switch {
+		case node.BlockLastPing.Uint64() > pending.Block:
+			mm.setPendingPing(nil)
+			pingConfirmedMeter.Mark(1)
+			log.Info("Masternode ping confirmed", "tx", pending.Hash, "block", last, "deadline", schedule.Deadline)
+			mm.setPingState(schedule, "")
+			mm.pingFeed.Send(PingEvent{Kind: PingConfirmed, Tx: pending.Hash, State: schedule})
+
+		case statedb.GetNonce(mm.active.NodeAccount) > pending.Nonce:
+			mm.setPendingPing(nil)
+			mm.pingFailed(schedule, errPingFailed)
+
+		case number < pending.Block+pingRetryBlocks:
+			schedule.Pending = &pending.Hash
+			mm.setPingState(schedule, "")
+			mm.warnPingDeadline(schedule, number)
+			return
+
+		default:
+			log.Warn("Masternode ping not included, replacing", "tx", pending.Hash, "sent", pending.Block, "deadline", schedule.Deadline)
+			mm.warnPingDeadline(schedule, number)
+			mm.sendPing(head, schedule, pending)
+			return
+		}
+	}
+	mm.warnPingDeadline(schedule, number)
+	if number < uint64(schedule.NextPing) {
+		mm.setPingState(schedule, "")
+		return
+	}
+	mm.sendPing(head, schedule, nil)
+}
+
+// sendPing signs and submits a ping transaction, replacing the given stuck ping
+// with a higher priced one if not nil.
+func (mm *MasternodeManager) sendPing(head *types.Block, schedule masternode.PingState, replace *pendingPing) {
+	account := mm.active.NodeAccount
+
+	statedb, err := mm.blockchain.State()
+	if err != nil {
+		mm.pingFailed(schedule, err)
+		return
+	}
+	balance := statedb.GetBalance(account)
+	if balance.Cmp(big.NewInt(1e+16)) < 0 {
+		mm.pingFailed(schedule, errPingBalance)
+		return
+	}
+	gasPrice := mm.pingGasPrice()
+	nonce := mm.txPool.State().GetNonce(account)
+	if replace != nil {
+		bumped := new(big.Int).Mul(replace.GasPrice, big.NewInt(100+pingPriceBump))
+		bumped.Div(bumped, big.NewInt(100))
+		if bumped.Cmp(gasPrice) > 0 {
+			gasPrice = bumped
+		}
+		nonce = replace.Nonce
+	}
+	gas := mm.estimatePingGas(statedb, head.Header())
+
+	// Make sure there is enough power for the ping, or tell when there will be
+	required := new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice)
+	if power := statedb.GetPower(account, head.Number()); power.Cmp(required) < 0 {
+		blocks, ok := state.PowerForecast(power, required, balance)
+		switch {
+		case !ok:
+			err = fmt.Errorf("balance too low to ever regenerate %v power for a ping", required)
+		case head.NumberU64()+blocks > uint64(schedule.Deadline):
+			err = fmt.Errorf("insufficient power for a ping before the deadline, %d blocks to regenerate", blocks)
+		default:
+			err = fmt.Errorf("insufficient power for a ping, %d blocks to regenerate", blocks)
+		}
+		mm.pingFailed(schedule, err)
+		return
+	}
+	tx := types.NewTransaction(nonce, params.MasterndeContractAddress, new(big.Int), gas, gasPrice, nil)
+	signed, err := mm.active.SignTx(tx, mm.blockchain.Config().ChainID)
+	if err != nil {
+		mm.pingFailed(schedule, err)
+		return
+	}
+	if err := mm.txPool.AddLocal(signed); err != nil {
+		mm.pingFailed(schedule, err)
+		return
+	}
+	mm.setPendingPing(&pendingPing{
+		Hash:     signed.Hash(),
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Block:    head.NumberU64(),
+	})
+	hash := signed.Hash()
+	schedule.Pending = &hash
+	mm.setPingState(schedule, "")
+
+	kind := PingSent
+	if replace != nil {
+		kind = PingReplaced
+		pingReplacedMeter.Mark(1)
+	} else {
+		pingSentMeter.Mark(1)
+	}
+	log.Info("Sent masternode ping", "tx", hash, "nonce", nonce, "gas", gas, "price", gasPrice, "deadline", schedule.Deadline)
+	mm.pingFeed.Send(PingEvent{Kind: kind, Tx: hash, State: schedule})
+}
+
+// pingGasPrice returns the gas price to ping with, never below the minimum
+// accepted by the local transaction pool.
+func (mm *MasternodeManager) pingGasPrice() *big.Int {
+	price := mm.txPool.GasPrice()
+	if mm.gasPrice != nil {
+		if suggested, err := mm.gasPrice(context.Background()); err == nil && suggested.Cmp(price) > 0 {
+			price = suggested
+		}
+	}
+	return price
+}
+
+// estimatePingGas executes a ping against the given state and returns its gas
+// usage with a margin for state changes until it is included.
+func (mm *MasternodeManager) estimatePingGas(statedb *state.StateDB, header *types.Header) uint64 {
+	msg := types.NewMessage(mm.active.NodeAccount, &params.MasterndeContractAddress, 0, new(big.Int), pingGasFallback, new(big.Int), nil, false)
+	context := core.NewEVMContext(msg, header, mm.blockchain, nil)
+	evm := vm.NewEVM(context, statedb.Copy(), mm.blockchain.Config(), vm.Config{})
+
+	_, gas, failed, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+	if err != nil || failed {
+		return pingGasFallback
+	}
+	return gas + gas/2
+}
+
+// warnPingDeadline warns once the last quarter of the ping timeout is entered,
+// and when the masternode missed its deadline.
+func (mm *MasternodeManager) warnPingDeadline(schedule masternode.PingState, number uint64) {
+	deadline := uint64(schedule.Deadline)
+	if number+(deadline-uint64(schedule.LastPing))/4 < deadline {
+		return
+	}
+	mm.pingLock.Lock()
+	if time.Since(mm.pingWarn) < pingWarnInterval {
+		mm.pingLock.Unlock()
+		return
+	}
+	mm.pingWarn = time.Now()
+	mm.pingLock.Unlock()
+
+	if number >= deadline {
+		log.Error("Masternode missed its ping deadline, dropped from the witnesses", "lastping", schedule.LastPing, "deadline", schedule.Deadline)
+		return
+	}
+	log.Warn("Masternode close to its ping deadline", "lastping", schedule.LastPing, "deadline", schedule.Deadline, "left", deadline-number)
+}
+
+// pingFailed records and announces a failure to ping.
+func (mm *MasternodeManager) pingFailed(schedule masternode.PingState, err error) {
+	pingFailedMeter.Mark(1)
+	log.Warn("Failed to ping masternode contract", "deadline", schedule.Deadline, "err", err)
+
+	mm.setPingState(schedule, err.Error())
+	mm.pingFeed.Send(PingEvent{Kind: PingFailed, State: schedule, Err: err})
+}
+
+func (mm *MasternodeManager) setPingState(schedule masternode.PingState, failure string) {
+	mm.pingLock.Lock()
+	defer mm.pingLock.Unlock()
+
+	schedule.Error = failure
+	mm.pingState = schedule
+}
+
+// setPendingPing tracks a ping in flight and persists it.
+func (mm *MasternodeManager) setPendingPing(ping *pendingPing) {
+	mm.pingLock.Lock()
+	defer mm.pingLock.Unlock()
+
+	mm.pending = ping
+	if ping == nil {
+		if err := mm.db.Delete(pingKey); err != nil {
+			log.Warn("Failed to delete masternode ping", "err", err)
+		}
+		return
+	}
+	blob, err := rlp.EncodeToBytes(ping)
+	if err != nil {
+		log.Crit("Failed to encode masternode ping", "err", err)
+	}
+	if err := mm.db.Put(pingKey, blob); err != nil {
+		log.Warn("Failed to store masternode ping", "err", err)
+	}
+}
+
+// loadPendingPing resumes tracking the ping in flight before a restart.
+func (mm *MasternodeManager) loadPendingPing() {
+	blob, err := mm.db.Get(pingKey)
+	if err != nil {
+		return
+	}
+	ping := new(pendingPing)
+	if err := rlp.DecodeBytes(blob, ping); err != nil {
+		log.Warn("Failed to decode masternode ping", "err", err)
+		return
+	}
+	mm.pingLock.Lock()
+	mm.pending = ping
+	mm.pingLock.Unlock()
+
+	log.Info("Tracking masternode ping", "tx", ping.Hash, "nonce", ping.Nonce, "sent", ping.Block)
+}
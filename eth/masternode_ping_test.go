@@ -0,0 +1,327 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/contracts/masternode/contract"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
+	"github.com/etherzero/go-etherzero/core/vm"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/eth/downloader"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/event"
+	"github.com/etherzero/go-etherzero/p2p/enode"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// Tests that the ping in flight survives a restart of the masternode manager,
+// so a stuck ping is still replaced afterwards.
+func TestPendingPingPersistence(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+
+	ping := &pendingPing{
+		Hash:     common.HexToHash("0x01"),
+		Nonce:    7,
+		GasPrice: big.NewInt(1e9),
+		Block:    1234,
+	}
+	NewMasternodeManager(nil, nil, nil, nil, db).setPendingPing(ping)
+
+	restarted := NewMasternodeManager(nil, nil, nil, nil, db)
+	restarted.loadPendingPing()
+	if !reflect.DeepEqual(restarted.pending, ping) {
+		t.Fatalf("restored ping mismatch: have %+v, want %+v", restarted.pending, ping)
+	}
+	restarted.setPendingPing(nil)
+
+	cleared := NewMasternodeManager(nil, nil, nil, nil, db)
+	cleared.loadPendingPing()
+	if cleared.pending != nil {
+		t.Fatalf("confirmed ping restored: %+v", cleared.pending)
+	}
+}
+
+// Tests that ping failures are exposed through the ping state and announced.
+func TestPingFailureEvent(t *testing.T) {
+	manager := NewMasternodeManager(nil, nil, nil, nil, ethdb.NewMemDatabase())
+
+	events := make(chan PingEvent, 1)
+	sub := manager.SubscribePingEvent(events)
+	defer sub.Unsubscribe()
+
+	schedule := masternode.PingState{LastPing: 100, NextPing: 1300, Deadline: 3700}
+	manager.pingFailed(schedule, errPingBalance)
+
+	if state := manager.PingState(); state.Deadline != schedule.Deadline || state.Error != errPingBalance.Error() {
+		t.Errorf("ping state mismatch: have %+v", state)
+	}
+	select {
+	case ev := <-events:
+		if ev.Kind != PingFailed || ev.Err != errPingBalance {
+			t.Errorf("ping event mismatch: have %+v", ev)
+		}
+	default:
+		t.Errorf("ping failure not announced")
+	}
+}
+
+// pingTester drives the ping scheduler of a masternode registered in the genesis
+// masternode contract over a simulated devote chain.
+type pingTester struct {
+	db      ethdb.Database
+	engine  *devote.Devote
+	chain   *core.BlockChain
+	pool    *core.TxPool
+	manager *MasternodeManager
+	key     *ecdsa.PrivateKey
+	events  chan PingEvent
+}
+
+// newPingTester creates a chain with a ping timeout of timeout blocks, on which
+// the local masternode account holds balance and pings at the given gas price.
+func newPingTester(t *testing.T, timeout uint64, balance, price *big.Int) *pingTester {
+	witnesses := []string{"0123456789abcdef"}
+
+	key, _ := crypto.GenerateKey()
+	url := enode.NewV4(&key.PublicKey, net.ParseIP("127.0.0.1"), 30303, 30303).String()
+
+	schedule := *params.DefaultDevoteParams
+	schedule.PingTimeout = timeout
+
+	config := *params.AllEthashProtocolChanges
+	config.Ethash = nil
+	config.Devote = &params.DevoteConfig{Witnesses: witnesses, Schedule: []*params.DevoteParams{&schedule}}
+
+	gspec := &core.Genesis{
+		Config:     &config,
+		Difficulty: big.NewInt(1),
+		Alloc: core.GenesisAlloc{
+			params.MasterndeContractAddress:       core.MasternodeContractAccount([]string{url}, common.Address{}),
+			crypto.PubkeyToAddress(key.PublicKey): {Balance: balance},
+		},
+	}
+	db := ethdb.NewMemDatabase()
+	gspec.MustCommit(db)
+
+	engine := devote.NewFaker(witnesses, db)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	poolConfig := core.DefaultTxPoolConfig
+	poolConfig.Journal = ""
+	pool := core.NewTxPool(poolConfig, gspec.Config, chain)
+
+	eth := &Ethereum{chainDb: db, blockchain: chain, chainConfig: gspec.Config, txPool: pool}
+	masternodes, err := contract.NewContract(params.MasterndeContractAddress, NewContractBackend(eth))
+	if err != nil {
+		t.Fatalf("failed to bind masternode contract: %v", err)
+	}
+	manager := NewMasternodeManager(nil, chain, masternodes, pool, db)
+	manager.downloader = downloader.New(downloader.FullSync, db, new(event.TypeMux), chain, nil, func(string) {})
+	manager.gasPrice = func(context.Context) (*big.Int, error) { return price, nil }
+	manager.active = masternode.NewActiveMasternode(masternode.NewKeySigner(key))
+	manager.active.SetState(masternode.ACTIVE_MASTERNODE_STARTED)
+
+	events := make(chan PingEvent, 16)
+	manager.SubscribePingEvent(events)
+
+	return &pingTester{db: db, engine: engine, chain: chain, pool: pool, manager: manager, key: key, events: events}
+}
+
+// close terminates the background goroutines of the tester.
+func (pt *pingTester) close() {
+	pt.manager.downloader.Terminate()
+	pt.manager.scope.Close()
+	pt.pool.Stop()
+	pt.chain.Stop()
+}
+
+// mine extends the chain by n empty blocks, the first of which includes the
+// given transactions.
+func (pt *pingTester) mine(t *testing.T, n int, txs []*types.Transaction) {
+	blocks, _ := core.GenerateChain(pt.chain.Config(), pt.chain.CurrentBlock(), pt.engine, pt.db, n, func(i int, block *core.BlockGen) {
+		if i == 0 {
+			for _, tx := range txs {
+				block.AddTx(tx)
+			}
+		}
+	})
+	if _, err := pt.chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+}
+
+// pendingPings returns the transactions of the masternode account in the pool.
+func (pt *pingTester) pendingPings() []*types.Transaction {
+	pending, _ := pt.pool.Pending()
+	return pending[pt.manager.active.NodeAccount]
+}
+
+// pingStep is a number of blocks mined before re-evaluating the ping schedule,
+// along with the expected outcome of the evaluation.
+type pingStep struct {
+	mine     int      // Blocks to mine before the evaluation
+	include  bool     // Whether the first mined block includes the pings in the pool
+	conflict bool     // Whether the first mined block includes a transfer using the nonce of the ping
+	events   []string // Kinds of the scheduler events expected, in order
+	err      string   // Substring of the expected failure, if any
+	lastPing uint64   // Block of the last ping expected in the schedule
+	pending  bool     // Whether a ping is expected in flight afterwards
+	nonce    uint64   // Nonce of the ping in flight
+	price    *big.Int // Gas price of the ping in flight
+	warned   bool     // Whether a deadline warning is expected
+}
+
+// Tests that the ping scheduler pings the contract in time, tracks the pings
+// until the contract records them and replaces pings which got stuck.
+func TestPingScheduler(t *testing.T) {
+	var (
+		gwei  = big.NewInt(params.GWei)
+		rich  = new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+		min   = big.NewInt(1e16)
+		bumpd = big.NewInt(1.25e9)
+	)
+	// The ping timeout is 120 blocks: pings are due after 40 blocks, a drop
+	// warning is issued after 90 and stuck pings are replaced after 60.
+	tests := []struct {
+		name    string
+		balance *big.Int
+		price   *big.Int
+		steps   []pingStep
+	}{
+		{
+			name: "not due", balance: rich, price: gwei,
+			steps: []pingStep{{mine: 39}},
+		},
+		{
+			name: "sent and confirmed", balance: rich, price: gwei,
+			steps: []pingStep{
+				{mine: 40, events: []string{PingSent}, pending: true, price: gwei},
+				{mine: 1, include: true, events: []string{PingConfirmed}, lastPing: 41},
+				{mine: 39, lastPing: 41},
+				{mine: 1, events: []string{PingSent}, lastPing: 41, pending: true, nonce: 1, price: gwei},
+			},
+		},
+		{
+			name: "stuck and replaced", balance: rich, price: gwei,
+			steps: []pingStep{
+				{mine: 40, events: []string{PingSent}, pending: true, price: gwei},
+				{mine: 59, pending: true, price: gwei, warned: true},
+				{mine: 1, events: []string{PingReplaced}, pending: true, price: bumpd, warned: true},
+				{mine: 1, include: true, events: []string{PingConfirmed}, lastPing: 101},
+			},
+		},
+		{
+			name: "nonce taken by another transaction", balance: rich, price: gwei,
+			steps: []pingStep{
+				{mine: 40, events: []string{PingSent}, pending: true, price: gwei},
+				{mine: 1, conflict: true, events: []string{PingFailed, PingSent}, err: errPingFailed.Error(), pending: true, nonce: 1, price: gwei},
+			},
+		},
+		{
+			name: "past the deadline", balance: rich, price: gwei,
+			steps: []pingStep{{mine: 130, events: []string{PingSent}, pending: true, price: gwei, warned: true}},
+		},
+		{
+			name: "balance too low", balance: new(big.Int).Sub(min, common.Big1), price: gwei,
+			steps: []pingStep{{mine: 40, events: []string{PingFailed}, err: errPingBalance.Error()}},
+		},
+		{
+			name: "power never sufficient", balance: min, price: big.NewInt(100 * params.GWei),
+			steps: []pingStep{{mine: 40, events: []string{PingFailed}, err: "ever regenerate"}},
+		},
+		{
+			name: "power not yet sufficient", balance: min, price: big.NewInt(50 * params.GWei),
+			steps: []pingStep{{mine: 40, events: []string{PingFailed}, err: "insufficient power"}},
+		},
+	}
+	for _, tt := range tests {
+		pt := newPingTester(t, 120, tt.balance, tt.price)
+
+		for i, step := range tt.steps {
+			var txs []*types.Transaction
+			if step.include {
+				txs = pt.pendingPings()
+			}
+			if step.conflict {
+				tx, _ := types.SignTx(types.NewTransaction(0, common.Address{1}, common.Big1, params.TxGas, tt.price, nil), types.NewEIP155Signer(pt.chain.Config().ChainID), pt.key)
+				txs = append(txs, tx)
+			}
+			pt.mine(t, step.mine, txs)
+
+			pt.manager.pingWarn = time.Time{}
+			pt.manager.checkPing()
+
+			// Check the announced events and the reported schedule
+			var kinds []string
+			var failure error
+			for len(pt.events) > 0 {
+				ev := <-pt.events
+				kinds = append(kinds, ev.Kind)
+				if ev.Err != nil {
+					failure = ev.Err
+				}
+			}
+			if !reflect.DeepEqual(kinds, step.events) {
+				t.Errorf("%s, step %d: events mismatch: have %v, want %v", tt.name, i, kinds, step.events)
+			}
+			if step.err != "" && (failure == nil || !strings.Contains(failure.Error(), step.err)) {
+				t.Errorf("%s, step %d: failure mismatch: have %v, want %q", tt.name, i, failure, step.err)
+			}
+			state := pt.manager.PingState()
+			if uint64(state.LastPing) != step.lastPing || uint64(state.NextPing) != step.lastPing+40 || uint64(state.Deadline) != step.lastPing+120 {
+				t.Errorf("%s, step %d: schedule mismatch: have %+v, want last ping %d", tt.name, i, state, step.lastPing)
+			}
+			if warned := !pt.manager.pingWarn.IsZero(); warned != step.warned {
+				t.Errorf("%s, step %d: warning mismatch: have %v, want %v", tt.name, i, warned, step.warned)
+			}
+			// Check the ping in flight against the transaction pool
+			pending := pt.manager.pending
+			if (pending != nil) != step.pending {
+				t.Errorf("%s, step %d: pending ping mismatch: have %+v, want %v", tt.name, i, pending, step.pending)
+				continue
+			}
+			if pending == nil {
+				continue
+			}
+			if state.Pending == nil || *state.Pending != pending.Hash {
+				t.Errorf("%s, step %d: reported ping mismatch: have %x, want %x", tt.name, i, state.Pending, pending.Hash)
+			}
+			if pending.Nonce != step.nonce || pending.GasPrice.Cmp(step.price) != 0 {
+				t.Errorf("%s, step %d: ping mismatch: have nonce %d price %v, want nonce %d price %v", tt.name, i, pending.Nonce, pending.GasPrice, step.nonce, step.price)
+			}
+			if tx := pt.pool.Get(pending.Hash); tx == nil || *tx.To() != params.MasterndeContractAddress || tx.Value().Sign() != 0 {
+				t.Errorf("%s, step %d: ping not in the pool: %v", tt.name, i, tx)
+			}
+		}
+		pt.close()
+	}
+}
@@ -170,6 +170,17 @@ func (ec *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.H
 	return head, err
 }
 
+// FinalizedHeader returns the header of the last block finalized by the consensus
+// engine. Finalized blocks can no longer be reorganised out of the chain.
+func (ec *Client) FinalizedHeader(ctx context.Context) (*types.Header, error) {
+	var head *types.Header
+	err := ec.c.CallContext(ctx, &head, "eth_getBlockByNumber", "finalized", false)
+	if err == nil && head == nil {
+		err = ethereum.NotFound
+	}
+	return head, err
+}
+
 type rpcTransaction struct {
 	tx *types.Transaction
 	txExtraInfo
@@ -313,6 +324,12 @@ func (ec *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header)
 	return ec.c.EthSubscribe(ctx, ch, "newHeads")
 }
 
+// SubscribeFinalizedHead subscribes to notifications about blocks finalized by
+// the consensus engine on the given channel.
+func (ec *Client) SubscribeFinalizedHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return ec.c.EthSubscribe(ctx, ch, "newFinalizedHeads")
+}
+
 // State Access
 
 // NetworkID returns the network ID (also known as the chain ID) for this chain.
@@ -336,6 +353,50 @@ func (ec *Client) BalanceAt(ctx context.Context, account common.Address, blockNu
 	return (*big.Int)(&result), err
 }
 
+// PowerAt returns the power the given account can spend on gas.
+// The block number can be nil, in which case the power is taken from the latest known block.
+func (ec *Client) PowerAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	// Unlike balances, power is reported as a plain decimal number
+	var result big.Int
+	err := ec.c.CallContext(ctx, &result, "eth_getPower", account, toBlockNumArg(blockNumber))
+	return &result, err
+}
+
+type rpcPowerForecast struct {
+	Power            *hexutil.Big   `json:"power"`
+	Required         *hexutil.Big   `json:"required"`
+	MaxPower         *hexutil.Big   `json:"maxPower"`
+	RegenerationRate *hexutil.Big   `json:"regenerationRate"`
+	Reachable        bool           `json:"reachable"`
+	Blocks           hexutil.Uint64 `json:"blocks"`
+	BlockNumber      *hexutil.Big   `json:"blockNumber"`
+}
+
+// PowerForecastAt predicts when the given account will have regenerated enough power
+// to pay for gas at gasPrice. The block number can be nil, in which case the forecast
+// starts from the latest known block.
+func (ec *Client) PowerForecastAt(ctx context.Context, account common.Address, gas uint64, gasPrice *big.Int, blockNumber *big.Int) (*ethereum.PowerForecast, error) {
+	var forecast *rpcPowerForecast
+	if err := ec.c.CallContext(ctx, &forecast, "eth_getPowerForecast", account, hexutil.Uint64(gas), (*hexutil.Big)(gasPrice), toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	if forecast == nil {
+		return nil, ethereum.NotFound
+	}
+	result := &ethereum.PowerForecast{
+		Power:            (*big.Int)(forecast.Power),
+		Required:         (*big.Int)(forecast.Required),
+		MaxPower:         (*big.Int)(forecast.MaxPower),
+		RegenerationRate: (*big.Int)(forecast.RegenerationRate),
+		Reachable:        forecast.Reachable,
+		Blocks:           uint64(forecast.Blocks),
+	}
+	if forecast.BlockNumber != nil {
+		result.BlockNumber = (*big.Int)(forecast.BlockNumber)
+	}
+	return result, nil
+}
+
 // StorageAt returns the value of key in the contract storage of the given account.
 // The block number can be nil, in which case the value is taken from the latest known block.
 func (ec *Client) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
@@ -412,6 +473,13 @@ func (ec *Client) PendingBalanceAt(ctx context.Context, account common.Address)
 	return (*big.Int)(&result), err
 }
 
+// PendingPowerAt returns the power the given account can spend on gas in the pending state.
+func (ec *Client) PendingPowerAt(ctx context.Context, account common.Address) (*big.Int, error) {
+	var result big.Int
+	err := ec.c.CallContext(ctx, &result, "eth_getPower", account, "pending")
+	return &result, err
+}
+
 // PendingStorageAt returns the value of key in the contract storage of the given account in the pending state.
 func (ec *Client) PendingStorageAt(ctx context.Context, account common.Address, key common.Hash) ([]byte, error) {
 	var result hexutil.Bytes
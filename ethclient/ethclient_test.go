@@ -17,6 +17,7 @@
 package ethclient
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -24,6 +25,16 @@ import (
 
 	"github.com/etherzero/go-etherzero"
 	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/consensus/ethash"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/state"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/vm"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/internal/ethapi"
+	"github.com/etherzero/go-etherzero/params"
+	"github.com/etherzero/go-etherzero/rpc"
 )
 
 // Verify that Client implements the ethereum interfaces.
@@ -150,3 +161,128 @@ func TestToFilterArg(t *testing.T) {
 		})
 	}
 }
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testBalance = new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+)
+
+// testBackend serves the chain state to the blockchain API, with the pending
+// state being the one of the head block.
+type testBackend struct {
+	ethapi.Backend
+	chain *core.BlockChain
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
+	if blockNr == rpc.LatestBlockNumber || blockNr == rpc.PendingBlockNumber {
+		return b.chain.CurrentHeader(), nil
+	}
+	return b.chain.GetHeaderByNumber(uint64(blockNr)), nil
+}
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	header, _ := b.HeaderByNumber(ctx, blockNr)
+	if header == nil {
+		return nil, nil, nil
+	}
+	statedb, err := b.chain.StateAt(header.Root)
+	return statedb, header, err
+}
+
+// newTestClient creates a client connected in-process to the blockchain API of
+// a chain with n blocks on top of a genesis funding the test account.
+func newTestClient(t *testing.T, n int) (*Client, *core.BlockChain) {
+	var (
+		db    = ethdb.NewMemDatabase()
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{testAddr: {Balance: testBalance}},
+		}
+		genesis = gspec.MustCommit(db)
+	)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, n, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", ethapi.NewPublicBlockChainAPI(&testBackend{chain: chain})); err != nil {
+		t.Fatalf("failed to register API: %v", err)
+	}
+	return NewClient(rpc.DialInProc(server)), chain
+}
+
+// Tests that the power of an account is retrieved for historical, latest and
+// pending blocks.
+func TestPowerAt(t *testing.T) {
+	client, chain := newTestClient(t, 4)
+	defer client.Close()
+
+	for _, number := range []*big.Int{big.NewInt(2), nil} {
+		header := chain.CurrentHeader()
+		if number != nil {
+			header = chain.GetHeaderByNumber(number.Uint64())
+		}
+		statedb, _ := chain.StateAt(header.Root)
+		want := statedb.GetPower(testAddr, header.Number)
+
+		power, err := client.PowerAt(context.Background(), testAddr, number)
+		if err != nil {
+			t.Fatalf("block %v: failed to retrieve power: %v", number, err)
+		}
+		if power.Cmp(want) != 0 || power.Sign() == 0 {
+			t.Errorf("block %v: power mismatch: have %v, want %v", number, power, want)
+		}
+	}
+	latest, _ := client.PowerAt(context.Background(), testAddr, nil)
+	pending, err := client.PendingPowerAt(context.Background(), testAddr)
+	if err != nil {
+		t.Fatalf("failed to retrieve pending power: %v", err)
+	}
+	if pending.Cmp(latest) != 0 {
+		t.Errorf("pending power mismatch: have %v, want %v", pending, latest)
+	}
+}
+
+// Tests that power forecasts are decoded from the API, both reachable and
+// unreachable ones.
+func TestPowerForecastAt(t *testing.T) {
+	client, chain := newTestClient(t, 2)
+	defer client.Close()
+
+	var (
+		gasPrice = big.NewInt(params.GWei)
+		max      = state.MaxPower(testBalance)
+		gas      = new(big.Int).Div(max, gasPrice).Uint64()
+		head     = chain.CurrentBlock().Number()
+	)
+	power, _ := client.PowerAt(context.Background(), testAddr, nil)
+	blocks, _ := state.PowerForecast(power, new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice), testBalance)
+
+	forecast, err := client.PowerForecastAt(context.Background(), testAddr, gas, gasPrice, nil)
+	if err != nil {
+		t.Fatalf("failed to forecast power: %v", err)
+	}
+	if forecast.Power.Cmp(power) != 0 || forecast.MaxPower.Cmp(max) != 0 || forecast.RegenerationRate.Cmp(state.PowerRate(testBalance)) != 0 {
+		t.Errorf("forecast state mismatch: power %v, max %v, rate %v", forecast.Power, forecast.MaxPower, forecast.RegenerationRate)
+	}
+	if !forecast.Reachable || forecast.Blocks != blocks || blocks == 0 {
+		t.Errorf("forecast mismatch: reachable %v, blocks %d, want %d", forecast.Reachable, forecast.Blocks, blocks)
+	}
+	if want := new(big.Int).Add(head, new(big.Int).SetUint64(blocks)); forecast.BlockNumber == nil || forecast.BlockNumber.Cmp(want) != 0 {
+		t.Errorf("forecast block mismatch: have %v, want %v", forecast.BlockNumber, want)
+	}
+	// More than the max power can never be regenerated
+	forecast, err = client.PowerForecastAt(context.Background(), testAddr, 2*gas+1, gasPrice, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("failed to forecast power: %v", err)
+	}
+	if forecast.Reachable || forecast.BlockNumber != nil {
+		t.Errorf("unreachable forecast mismatch: reachable %v, number %v", forecast.Reachable, forecast.BlockNumber)
+	}
+}
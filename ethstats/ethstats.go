@@ -28,18 +28,22 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/common/mclock"
 	"github.com/etherzero/go-etherzero/consensus"
+	"github.com/etherzero/go-etherzero/consensus/devote"
 	"github.com/etherzero/go-etherzero/core"
 	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
 	"github.com/etherzero/go-etherzero/eth"
 	"github.com/etherzero/go-etherzero/event"
 	"github.com/etherzero/go-etherzero/les"
 	"github.com/etherzero/go-etherzero/log"
 	"github.com/etherzero/go-etherzero/p2p"
+	"github.com/etherzero/go-etherzero/params"
 	"github.com/etherzero/go-etherzero/rpc"
 	"golang.org/x/net/websocket"
 )
@@ -473,6 +477,7 @@ type blockStats struct {
 	ParentHash common.Hash    `json:"parentHash"`
 	Timestamp  *big.Int       `json:"timestamp"`
 	Miner      common.Address `json:"miner"`
+	Witness    string         `json:"witness"`
 	GasUsed    uint64         `json:"gasUsed"`
 	GasLimit   uint64         `json:"gasLimit"`
 	Diff       string         `json:"difficulty"`
@@ -559,6 +564,7 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 		ParentHash: header.ParentHash,
 		Timestamp:  header.Time,
 		Miner:      author,
+		Witness:    header.Witness,
 		GasUsed:    header.GasUsed,
 		GasLimit:   header.GasLimit,
 		Diff:       header.Difficulty.String(),
@@ -663,13 +669,28 @@ func (s *Service) reportPending(conn *websocket.Conn) error {
 
 // nodeStats is the information to report about the local node.
 type nodeStats struct {
-	Active   bool `json:"active"`
-	Syncing  bool `json:"syncing"`
-	Mining   bool `json:"mining"`
-	Hashrate int  `json:"hashrate"`
-	Peers    int  `json:"peers"`
-	GasPrice int  `json:"gasPrice"`
-	Uptime   int  `json:"uptime"`
+	Active   bool         `json:"active"`
+	Syncing  bool         `json:"syncing"`
+	Mining   bool         `json:"mining"`
+	Hashrate int          `json:"hashrate"`
+	Peers    int          `json:"peers"`
+	GasPrice int          `json:"gasPrice"`
+	Uptime   int          `json:"uptime"`
+	Devote   *devoteStats `json:"devote,omitempty"`
+}
+
+// devoteStats is the information to report about the witness health of the local
+// node on a devote network.
+type devoteStats struct {
+	Masternode  bool           `json:"masternode"`  // Whether the node is an active masternode
+	Witness     string         `json:"witness"`     // Witness id of the local masternode
+	Account     common.Address `json:"account"`     // Account of the local masternode
+	Balance     *big.Int       `json:"balance"`     // Balance of the masternode account
+	Power       *big.Int       `json:"power"`       // Power of the masternode account
+	Cycle       uint64         `json:"cycle"`       // Cycle of the current chain head
+	Slot        int            `json:"slot"`        // Position of the witness in the slot order, -1 if not elected
+	MissedSlots []uint64       `json:"missedSlots"` // Timestamps of the slots the witness left empty in the cycle
+	Confirmed   *big.Int       `json:"confirmed"`   // Number of the latest confirmed block
 }
 
 // reportPending retrieves various stats about the node at the networking and
@@ -681,6 +702,8 @@ func (s *Service) reportStats(conn *websocket.Conn) error {
 		hashrate int
 		syncing  bool
 		gasprice int
+
+		witness *devoteStats
 	)
 	if s.eth != nil {
 		mining = s.eth.Miner().Mining()
@@ -691,6 +714,8 @@ func (s *Service) reportStats(conn *websocket.Conn) error {
 
 		price, _ := s.eth.APIBackend.SuggestPrice(context.Background())
 		gasprice = int(price.Uint64())
+
+		witness = s.assembleDevoteStats()
 	} else {
 		sync := s.les.Downloader().Progress()
 		syncing = s.les.BlockChain().CurrentHeader().Number.Uint64() >= sync.HighestBlock
@@ -708,6 +733,7 @@ func (s *Service) reportStats(conn *websocket.Conn) error {
 			GasPrice: gasprice,
 			Syncing:  syncing,
 			Uptime:   100,
+			Devote:   witness,
 		},
 	}
 	report := map[string][]interface{}{
@@ -715,3 +741,54 @@ func (s *Service) reportStats(conn *websocket.Conn) error {
 	}
 	return websocket.JSON.Send(conn, report)
 }
+
+// assembleDevoteStats retrieves the witness state of the local masternode from
+// the devote engine and the chain head. It returns nil if the node doesn't run
+// the devote engine.
+func (s *Service) assembleDevoteStats() *devoteStats {
+	engine, ok := s.engine.(*devote.Devote)
+	if !ok {
+		return nil
+	}
+	manager := s.eth.MasternodeManager()
+	active, _ := manager.Active()
+
+	return witnessStats(s.eth.BlockChain(), engine, active, atomic.LoadUint32(&manager.IsMasternode) == 1)
+}
+
+// witnessStats assembles the witness state of a masternode at the head of the
+// chain. The active masternode is nil if the node has no masternode identity.
+func witnessStats(chain *core.BlockChain, engine *devote.Devote, active *masternode.ActiveMasternode, started bool) *devoteStats {
+	head := chain.CurrentBlock()
+
+	stats := &devoteStats{
+		Masternode:  started,
+		Cycle:       head.Time().Uint64() / params.CycleInterval,
+		Slot:        -1,
+		MissedSlots: []uint64{},
+	}
+	if confirmed := engine.ConfirmedBlockHeader(chain); confirmed != nil {
+		stats.Confirmed = confirmed.Number
+	}
+	if active == nil {
+		return stats
+	}
+	stats.Witness, stats.Account = active.ID, active.NodeAccount
+
+	if statedb, err := chain.State(); err == nil {
+		stats.Balance = statedb.GetBalance(active.NodeAccount)
+		stats.Power = statedb.GetPower(active.NodeAccount, head.Number())
+	}
+	// The cycle has no schedule yet if its first block is still to be sealed
+	info, err := devote.NewAPI(chain, engine).GetCycle(&stats.Cycle)
+	if err != nil {
+		return stats
+	}
+	for _, witness := range info.Witnesses {
+		if witness.Witness == active.ID {
+			stats.Slot, stats.MissedSlots = witness.Slot, witness.MissedSlots
+			break
+		}
+	}
+	return stats
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethstats
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
+	"github.com/etherzero/go-etherzero/core/vm"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/params"
+)
+
+// Tests that the witness health reported for the local masternode contains the
+// slot and the missed slots of the witness in the current cycle.
+func TestWitnessStats(t *testing.T) {
+	// Create masternodes elected in both slots and one never elected
+	var nodes []*masternode.ActiveMasternode
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		nodes = append(nodes, masternode.NewActiveMasternode(masternode.NewKeySigner(key)))
+	}
+	witnesses := []string{nodes[0].ID, nodes[1].ID}
+
+	config := *params.TestChainConfig
+	config.Ethash = nil
+	config.Devote = &params.DevoteConfig{Witnesses: witnesses}
+
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	gspec := &core.Genesis{
+		Config:     &config,
+		Difficulty: big.NewInt(1),
+		Alloc:      core.GenesisAlloc{nodes[0].NodeAccount: {Balance: balance}},
+	}
+	db := ethdb.NewMemDatabase()
+	genesis := gspec.MustCommit(db)
+
+	// Blocks are generated every 10 seconds into the even slots of the first witness
+	engine := devote.NewFaker(witnesses, db)
+	blocks, _ := core.GenerateChain(&config, genesis, engine, db, 30, nil)
+
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	var (
+		head      = chain.CurrentBlock().Number()
+		confirmed = engine.ConfirmedBlockHeader(chain).Number
+	)
+	state, _ := chain.State()
+	slots := func(parity uint64) []uint64 {
+		list := []uint64{}
+		for slot := uint64(11); slot <= 300; slot++ {
+			if slot%2 == parity && slot%10 != 0 {
+				list = append(list, slot)
+			}
+		}
+		return list
+	}
+	tests := []struct {
+		active  *masternode.ActiveMasternode
+		started bool
+		slot    int
+		missed  []uint64
+	}{
+		{active: nodes[0], started: true, slot: 0, missed: slots(0)},
+		{active: nodes[1], started: true, slot: 1, missed: slots(1)},
+		{active: nodes[2], started: true, slot: -1, missed: []uint64{}},
+		{active: nil, started: false, slot: -1, missed: []uint64{}},
+	}
+	for i, tt := range tests {
+		stats := witnessStats(chain, engine, tt.active, tt.started)
+
+		if stats.Masternode != tt.started || stats.Cycle != 0 || stats.Confirmed.Cmp(confirmed) != 0 {
+			t.Errorf("test %d: chain state mismatch: masternode %v, cycle %d, confirmed %v", i, stats.Masternode, stats.Cycle, stats.Confirmed)
+		}
+		if stats.Slot != tt.slot || !reflect.DeepEqual(stats.MissedSlots, tt.missed) {
+			t.Errorf("test %d: schedule mismatch: have slot %d, missed %v; want slot %d, missed %v", i, stats.Slot, stats.MissedSlots, tt.slot, tt.missed)
+		}
+		if tt.active == nil {
+			if stats.Witness != "" || stats.Balance != nil || stats.Power != nil {
+				t.Errorf("test %d: masternode reported without identity: %+v", i, stats)
+			}
+			continue
+		}
+		if stats.Witness != tt.active.ID || stats.Account != tt.active.NodeAccount {
+			t.Errorf("test %d: identity mismatch: have %s %x, want %s %x", i, stats.Witness, stats.Account, tt.active.ID, tt.active.NodeAccount)
+		}
+		if want := state.GetBalance(tt.active.NodeAccount); stats.Balance.Cmp(want) != 0 {
+			t.Errorf("test %d: balance mismatch: have %v, want %v", i, stats.Balance, want)
+		}
+		if want := state.GetPower(tt.active.NodeAccount, head); stats.Power.Cmp(want) != 0 {
+			t.Errorf("test %d: power mismatch: have %v, want %v", i, stats.Power, want)
+		}
+	}
+	// Masternodes which missed no slot report an empty list rather than null
+	blob, err := json.Marshal(witnessStats(chain, engine, nodes[2], true))
+	if err != nil {
+		t.Fatalf("failed to encode stats: %v", err)
+	}
+	if !strings.Contains(string(blob), `"missedSlots":[]`) || !strings.Contains(string(blob), `"slot":-1`) {
+		t.Errorf("unelected witness payload mismatch: %s", blob)
+	}
+}
@@ -0,0 +1,1062 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package graphql provides a GraphQL interface to Etherzero node data.
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	ethereum "github.com/etherzero/go-etherzero"
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/common/hexutil"
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/core/rawdb"
+	"github.com/etherzero/go-etherzero/core/state"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/eth/filters"
+	"github.com/etherzero/go-etherzero/internal/ethapi"
+	"github.com/etherzero/go-etherzero/params"
+	"github.com/etherzero/go-etherzero/rpc"
+)
+
+var (
+	errBlockInvariant = errors.New("block objects must be instantiated with at least one of num or hash")
+	errUnknownBlock   = errors.New("unknown block")
+)
+
+// Account represents an Etherzero account at a particular block.
+type Account struct {
+	r           *Resolver
+	address     common.Address
+	blockNumber rpc.BlockNumber
+}
+
+// getState fetches the state and header of the block the account is viewed at.
+func (a *Account) getState(ctx context.Context) (*state.StateDB, *types.Header, error) {
+	statedb, header, err := a.r.backend.StateAndHeaderByNumber(ctx, a.blockNumber)
+	if statedb == nil && err == nil {
+		err = errUnknownBlock
+	}
+	return statedb, header, err
+}
+
+func (a *Account) Address(ctx context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *Account) Balance(ctx context.Context) (hexutil.Big, error) {
+	statedb, _, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*statedb.GetBalance(a.address)), statedb.Error()
+}
+
+func (a *Account) TransactionCount(ctx context.Context) (hexutil.Uint64, error) {
+	statedb, _, err := a.getState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(statedb.GetNonce(a.address)), statedb.Error()
+}
+
+func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	statedb, _, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(statedb.GetCode(a.address)), statedb.Error()
+}
+
+func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	statedb, _, err := a.getState(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return statedb.GetState(a.address, args.Slot), statedb.Error()
+}
+
+func (a *Account) Power(ctx context.Context) (hexutil.Big, error) {
+	statedb, header, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*statedb.GetPower(a.address, header.Number)), statedb.Error()
+}
+
+func (a *Account) MaxPower(ctx context.Context) (hexutil.Big, error) {
+	statedb, _, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*state.MaxPower(statedb.GetBalance(a.address))), statedb.Error()
+}
+
+// Log represents an individual log message. All arguments are mandatory.
+type Log struct {
+	r           *Resolver
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) *Transaction {
+	return l.transaction
+}
+
+func (l *Log) Account(ctx context.Context, args BlockNumberArgs) *Account {
+	return &Account{
+		r:           l.r,
+		address:     l.log.Address,
+		blockNumber: args.Number(),
+	}
+}
+
+func (l *Log) Index(ctx context.Context) int32 {
+	return int32(l.log.Index)
+}
+
+func (l *Log) Topics(ctx context.Context) []common.Hash {
+	return l.log.Topics
+}
+
+func (l *Log) Data(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(l.log.Data)
+}
+
+// Transaction represents an Etherzero transaction.
+// r and hash are mandatory; all others will be fetched when required.
+type Transaction struct {
+	r     *Resolver
+	hash  common.Hash
+	tx    *types.Transaction
+	block *Block
+	index uint64
+}
+
+// resolve returns the internal transaction object, fetching it if needed.
+func (t *Transaction) resolve(ctx context.Context) (*types.Transaction, error) {
+	if t.tx == nil {
+		tx, blockHash, _, index := rawdb.ReadTransaction(t.r.backend.ChainDb(), t.hash)
+		if tx != nil {
+			t.tx = tx
+			t.block = &Block{
+				r:    t.r,
+				hash: blockHash,
+			}
+			t.index = index
+		} else {
+			t.tx = t.r.backend.GetPoolTransaction(t.hash)
+		}
+	}
+	return t.tx, nil
+}
+
+func (t *Transaction) Hash(ctx context.Context) common.Hash {
+	return t.hash
+}
+
+func (t *Transaction) InputData(ctx context.Context) (hexutil.Bytes, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(tx.Data()), nil
+}
+
+func (t *Transaction) Gas(ctx context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(tx.Gas()), nil
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.GasPrice()), nil
+}
+
+func (t *Transaction) Value(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.Value()), nil
+}
+
+func (t *Transaction) Nonce(ctx context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(tx.Nonce()), nil
+}
+
+func (t *Transaction) To(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	to := tx.To()
+	if to == nil {
+		return nil, nil
+	}
+	return &Account{
+		r:           t.r,
+		address:     *to,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (t *Transaction) From(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	from, _ := types.Sender(signer, tx)
+
+	return &Account{
+		r:           t.r,
+		address:     from,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (t *Transaction) Block(ctx context.Context) (*Block, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	return t.block, nil
+}
+
+func (t *Transaction) Index(ctx context.Context) (*int32, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	if t.block == nil {
+		return nil, nil
+	}
+	index := int32(t.index)
+	return &index, nil
+}
+
+// getReceipt returns the receipt associated with this transaction, if any.
+func (t *Transaction) getReceipt(ctx context.Context) (*types.Receipt, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	if t.block == nil {
+		return nil, nil
+	}
+	receipts, err := t.block.resolveReceipts(ctx)
+	if err != nil || uint64(len(receipts)) <= t.index {
+		return nil, err
+	}
+	return receipts[t.index], nil
+}
+
+func (t *Transaction) Status(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	status := hexutil.Uint64(receipt.Status)
+	return &status, nil
+}
+
+func (t *Transaction) GasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	used := hexutil.Uint64(receipt.GasUsed)
+	return &used, nil
+}
+
+func (t *Transaction) CumulativeGasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	used := hexutil.Uint64(receipt.CumulativeGasUsed)
+	return &used, nil
+}
+
+func (t *Transaction) CreatedContract(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil || receipt.ContractAddress == (common.Address{}) {
+		return nil, err
+	}
+	return &Account{
+		r:           t.r,
+		address:     receipt.ContractAddress,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (t *Transaction) Logs(ctx context.Context) (*[]*Log, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		ret = append(ret, &Log{
+			r:           t.r,
+			transaction: t,
+			log:         log,
+		})
+	}
+	return &ret, nil
+}
+
+// Block represents an Etherzero block.
+// r, and either num or hash are mandatory. All other fields are lazily fetched
+// when required.
+type Block struct {
+	r        *Resolver
+	num      *rpc.BlockNumber
+	hash     common.Hash
+	header   *types.Header
+	block    *types.Block
+	receipts []*types.Receipt
+}
+
+// resolve returns the internal Block object representing this block, fetching
+// it if necessary.
+func (b *Block) resolve(ctx context.Context) (*types.Block, error) {
+	if b.block != nil {
+		return b.block, nil
+	}
+	var err error
+	if b.hash != (common.Hash{}) {
+		b.block, err = b.r.backend.GetBlock(ctx, b.hash)
+	} else {
+		b.block, err = b.r.backend.BlockByNumber(ctx, *b.num)
+	}
+	if b.block != nil {
+		b.header = b.block.Header()
+	}
+	return b.block, err
+}
+
+// resolveHeader returns the internal Header object for this block, fetching it
+// if necessary. Call this function instead of `resolve` unless you need the
+// additional data (transactions and uncles).
+func (b *Block) resolveHeader(ctx context.Context) (*types.Header, error) {
+	if b.num == nil && b.hash == (common.Hash{}) {
+		return nil, errBlockInvariant
+	}
+	if b.header == nil {
+		var err error
+		if b.hash != (common.Hash{}) {
+			b.header, err = b.r.backend.HeaderByHash(ctx, b.hash)
+		} else {
+			b.header, err = b.r.backend.HeaderByNumber(ctx, *b.num)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b.header == nil {
+			return nil, errUnknownBlock
+		}
+	}
+	return b.header, nil
+}
+
+// resolveReceipts returns the list of receipts for this block, fetching them
+// if necessary.
+func (b *Block) resolveReceipts(ctx context.Context) ([]*types.Receipt, error) {
+	if b.receipts == nil {
+		hash, err := b.Hash(ctx)
+		if err != nil {
+			return nil, err
+		}
+		receipts, err := b.r.backend.GetReceipts(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		b.receipts = []*types.Receipt(receipts)
+	}
+	return b.receipts, nil
+}
+
+// blockNumber returns the number of the block as an RPC block number, used to
+// view accounts at the state of the block.
+func (b *Block) blockNumber(ctx context.Context) (rpc.BlockNumber, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return rpc.BlockNumber(header.Number.Uint64()), nil
+}
+
+func (b *Block) Number(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.Number.Uint64()), nil
+}
+
+func (b *Block) Hash(ctx context.Context) (common.Hash, error) {
+	if b.hash == (common.Hash{}) {
+		header, err := b.resolveHeader(ctx)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		b.hash = header.Hash()
+	}
+	return b.hash, nil
+}
+
+func (b *Block) GasLimit(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.GasLimit), nil
+}
+
+func (b *Block) GasUsed(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.GasUsed), nil
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if header.Number.Sign() == 0 {
+		return nil, nil
+	}
+	num := rpc.BlockNumber(header.Number.Uint64() - 1)
+	return &Block{
+		r:    b.r,
+		num:  &num,
+		hash: header.ParentHash,
+	}, nil
+}
+
+func (b *Block) Difficulty(ctx context.Context) (hexutil.Big, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*header.Difficulty), nil
+}
+
+func (b *Block) Timestamp(ctx context.Context) (hexutil.Big, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*header.Time), nil
+}
+
+func (b *Block) Nonce(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(header.Nonce[:]), nil
+}
+
+func (b *Block) MixHash(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.MixDigest, nil
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.TxHash, nil
+}
+
+func (b *Block) StateRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.Root, nil
+}
+
+func (b *Block) ReceiptsRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.ReceiptHash, nil
+}
+
+func (b *Block) Witness(ctx context.Context) (string, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return "", err
+	}
+	return header.Witness, nil
+}
+
+func (b *Block) Miner(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		r:           b.r,
+		address:     header.Coinbase,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (b *Block) ExtraData(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(header.Extra), nil
+}
+
+func (b *Block) LogsBloom(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(header.Bloom.Bytes()), nil
+}
+
+func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
+	hash, err := b.Hash(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	td := b.r.backend.GetTd(hash)
+	if td == nil {
+		return hexutil.Big{}, errUnknownBlock
+	}
+	return hexutil.Big(*td), nil
+}
+
+func (b *Block) TransactionCount(ctx context.Context) (*int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	count := int32(len(block.Transactions()))
+	return &count, err
+}
+
+func (b *Block) Transactions(ctx context.Context) (*[]*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		ret = append(ret, &Transaction{
+			r:     b.r,
+			hash:  tx.Hash(),
+			tx:    tx,
+			block: b,
+			index: uint64(i),
+		})
+	}
+	return &ret, nil
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) (*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil, nil
+	}
+	tx := txs[args.Index]
+	return &Transaction{
+		r:     b.r,
+		hash:  tx.Hash(),
+		tx:    tx,
+		block: b,
+		index: uint64(args.Index),
+	}, nil
+}
+
+// BlockFilterCriteria encapsulates criteria passed to a `logs` accessor inside
+// a block.
+type BlockFilterCriteria struct {
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+
+	// The Topic list restricts matches to particular event topics. Each event has a list
+	// of topics. Topics matches a prefix of that list. An empty element slice matches any
+	// topic. Non-empty elements represent an alternative that matches any of the
+	// contained topics.
+	//
+	// Examples:
+	// {} or nil          matches any topic list
+	// {{A}}              matches topic A in first position
+	// {{}, {B}}          matches any topic in first position, B in second position
+	// {{A}, {B}}         matches topic A in first position, B in second position
+	// {{A, B}}, {C, D}}  matches topic (A OR B) in first position, (C OR D) in second position
+	Topics *[][]common.Hash
+}
+
+// runFilter runs a filter and wraps the matching logs into resolvers.
+func runFilter(ctx context.Context, r *Resolver, filter *filters.Filter) ([]*Log, error) {
+	logs, err := filter.Logs(ctx)
+	if err != nil || logs == nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(logs))
+	for _, log := range logs {
+		ret = append(ret, &Log{
+			r:           r,
+			transaction: &Transaction{r: r, hash: log.TxHash},
+			log:         log,
+		})
+	}
+	return ret, nil
+}
+
+func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) ([]*Log, error) {
+	var addresses []common.Address
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	var topics [][]common.Hash
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+	hash, err := b.Hash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Construct the range filter
+	filter := filters.NewBlockFilter(b.r.backend, hash, addresses, topics)
+
+	// Run the filter and return all the logs
+	return runFilter(ctx, b.r, filter)
+}
+
+func (b *Block) Account(ctx context.Context, args struct{ Address common.Address }) (*Account, error) {
+	number, err := b.blockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		r:           b.r,
+		address:     args.Address,
+		blockNumber: number,
+	}, nil
+}
+
+func (b *Block) Call(ctx context.Context, args struct{ Data CallData }) (hexutil.Bytes, error) {
+	number, err := b.blockNumber(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return ethapi.NewPublicBlockChainAPI(b.r.backend).Call(ctx, args.Data.callArgs(), number, nil, nil)
+}
+
+func (b *Block) Cycle(ctx context.Context) (*Cycle, error) {
+	if b.r.devote == nil {
+		return nil, nil
+	}
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cycle := header.Time.Uint64() / params.CycleInterval
+
+	info, err := b.r.devote.GetCycle(&cycle)
+	if err != nil {
+		return nil, err
+	}
+	return &Cycle{info: info}, nil
+}
+
+// CallData encapsulates arguments to `call` or `estimateGas`.
+// All arguments are optional.
+type CallData struct {
+	From     *common.Address // The Etherzero address the call is from.
+	To       *common.Address // The Etherzero address the call is to.
+	Gas      *hexutil.Uint64 // The amount of gas provided for the call.
+	GasPrice *hexutil.Big    // The price of each unit of gas, in wei.
+	Value    *hexutil.Big    // The value sent along with the call.
+	Data     *hexutil.Bytes  // Any data sent with the call.
+}
+
+// callArgs converts the optional GraphQL call fields into the call arguments of
+// the JSON-RPC API, leaving the missing ones to their defaults.
+func (c CallData) callArgs() ethapi.CallArgs {
+	args := ethapi.CallArgs{To: c.To}
+	if c.From != nil {
+		args.From = *c.From
+	}
+	if c.Gas != nil {
+		args.Gas = *c.Gas
+	}
+	if c.GasPrice != nil {
+		args.GasPrice = *c.GasPrice
+	}
+	if c.Value != nil {
+		args.Value = *c.Value
+	}
+	if c.Data != nil {
+		args.Data = *c.Data
+	}
+	return args
+}
+
+// Witness represents the production record of an elected witness in a cycle.
+type Witness struct {
+	stats *devote.WitnessStats
+}
+
+func (w *Witness) ID(ctx context.Context) string {
+	return w.stats.Witness
+}
+
+func (w *Witness) Slot(ctx context.Context) int32 {
+	return int32(w.stats.Slot)
+}
+
+func (w *Witness) Blocks(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(w.stats.Blocks)
+}
+
+func (w *Witness) MissedSlots(ctx context.Context) []hexutil.Uint64 {
+	slots := make([]hexutil.Uint64, len(w.stats.MissedSlots))
+	for i, slot := range w.stats.MissedSlots {
+		slots[i] = hexutil.Uint64(slot)
+	}
+	return slots
+}
+
+// Cycle represents the witness schedule of a devote cycle.
+type Cycle struct {
+	info *devote.CycleInfo
+}
+
+func (c *Cycle) Number(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(c.info.Cycle)
+}
+
+func (c *Cycle) FirstSlot(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(c.info.FirstSlot)
+}
+
+func (c *Cycle) LastSlot(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(c.info.LastSlot)
+}
+
+func (c *Cycle) Witnesses(ctx context.Context) []*Witness {
+	witnesses := make([]*Witness, len(c.info.Witnesses))
+	for i, stats := range c.info.Witnesses {
+		witnesses[i] = &Witness{stats: stats}
+	}
+	return witnesses
+}
+
+// BlockNumberArgs encapsulates arguments to accessors that specify a block number.
+type BlockNumberArgs struct {
+	Block *hexutil.Uint64
+}
+
+// Number returns the provided block number, or rpc.LatestBlockNumber if none
+// was provided.
+func (a BlockNumberArgs) Number() rpc.BlockNumber {
+	if a.Block != nil {
+		return rpc.BlockNumber(*a.Block)
+	}
+	return rpc.LatestBlockNumber
+}
+
+// Pending represents the current pending state.
+type Pending struct {
+	r *Resolver
+}
+
+func (p *Pending) TransactionCount(ctx context.Context) (int32, error) {
+	txs, err := p.r.backend.GetPoolTransactions()
+	return int32(len(txs)), err
+}
+
+func (p *Pending) Transactions(ctx context.Context) (*[]*Transaction, error) {
+	txs, err := p.r.backend.GetPoolTransactions()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, len(txs))
+	for _, tx := range txs {
+		ret = append(ret, &Transaction{
+			r:    p.r,
+			hash: tx.Hash(),
+			tx:   tx,
+		})
+	}
+	return &ret, nil
+}
+
+func (p *Pending) Account(ctx context.Context, args struct{ Address common.Address }) *Account {
+	return &Account{
+		r:           p.r,
+		address:     args.Address,
+		blockNumber: rpc.PendingBlockNumber,
+	}
+}
+
+func (p *Pending) Call(ctx context.Context, args struct{ Data CallData }) (hexutil.Bytes, error) {
+	return ethapi.NewPublicBlockChainAPI(p.r.backend).Call(ctx, args.Data.callArgs(), rpc.PendingBlockNumber, nil, nil)
+}
+
+func (p *Pending) EstimateGas(ctx context.Context, args struct{ Data CallData }) (hexutil.Uint64, error) {
+	return ethapi.NewPublicBlockChainAPI(p.r.backend).EstimateGas(ctx, args.Data.callArgs(), nil, nil)
+}
+
+// Resolver is the root resolver of the schema, serving the chain data of an
+// Etherzero backend along with the devote schedule of its consensus engine.
+type Resolver struct {
+	backend ethapi.Backend
+	devote  *devote.API // Devote consensus API, nil if the engine state isn't available (light client)
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *hexutil.Uint64
+	Hash   *common.Hash
+}) (*Block, error) {
+	var block *Block
+	if args.Number != nil {
+		num := rpc.BlockNumber(uint64(*args.Number))
+		block = &Block{
+			r:   r,
+			num: &num,
+		}
+	} else if args.Hash != nil {
+		block = &Block{
+			r:    r,
+			hash: *args.Hash,
+		}
+	} else {
+		num := rpc.LatestBlockNumber
+		block = &Block{
+			r:   r,
+			num: &num,
+		}
+	}
+	// Resolve the header, return nil if it doesn't exist
+	if _, err := block.resolveHeader(ctx); err == errUnknownBlock {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From hexutil.Uint64
+	To   *hexutil.Uint64
+}) ([]*Block, error) {
+	from := rpc.BlockNumber(args.From)
+
+	var to rpc.BlockNumber
+	if args.To != nil {
+		to = rpc.BlockNumber(*args.To)
+	} else {
+		to = rpc.BlockNumber(r.backend.CurrentBlock().Number().Int64())
+	}
+	if to < from {
+		return []*Block{}, nil
+	}
+	ret := make([]*Block, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		num := i
+		ret = append(ret, &Block{
+			r:   r,
+			num: &num,
+		})
+	}
+	return ret, nil
+}
+
+func (r *Resolver) Pending(ctx context.Context) *Pending {
+	return &Pending{r}
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	tx := &Transaction{
+		r:    r,
+		hash: args.Hash,
+	}
+	// Resolve the transaction; if it doesn't exist, return nil.
+	t, err := tx.resolve(ctx)
+	if err != nil {
+		return nil, err
+	} else if t == nil {
+		return nil, nil
+	}
+	return tx, nil
+}
+
+func (r *Resolver) SendRawTransaction(ctx context.Context, args struct{ Data hexutil.Bytes }) (common.Hash, error) {
+	return ethapi.NewPublicTransactionPoolAPI(r.backend, new(ethapi.AddrLocker)).SendRawTransaction(ctx, args.Data)
+}
+
+// FilterCriteria encapsulates the arguments to `logs` on the root resolver object.
+type FilterCriteria struct {
+	FromBlock *hexutil.Uint64   // beginning of the queried range, nil means genesis block
+	ToBlock   *hexutil.Uint64   // end of the range, nil means latest block
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+
+	// The Topic list restricts matches to particular event topics. Each event has a list
+	// of topics. Topics matches a prefix of that list. An empty element slice matches any
+	// topic. Non-empty elements represent an alternative that matches any of the
+	// contained topics.
+	//
+	// Examples:
+	// {} or nil          matches any topic list
+	// {{A}}              matches topic A in first position
+	// {{}, {B}}          matches any topic in first position, B in second position
+	// {{A}, {B}}         matches topic A in first position, B in second position
+	// {{A, B}}, {C, D}}  matches topic (A OR B) in first position, (C OR D) in second position
+	Topics *[][]common.Hash
+}
+
+func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria }) ([]*Log, error) {
+	// Convert the RPC block numbers into internal representations
+	begin := rpc.LatestBlockNumber.Int64()
+	if args.Filter.FromBlock != nil {
+		begin = int64(*args.Filter.FromBlock)
+	}
+	end := rpc.LatestBlockNumber.Int64()
+	if args.Filter.ToBlock != nil {
+		end = int64(*args.Filter.ToBlock)
+	}
+	var addresses []common.Address
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	var topics [][]common.Hash
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+	// Construct the range filter
+	filter := filters.NewRangeFilter(r.backend, begin, end, addresses, topics)
+	return runFilter(ctx, r, filter)
+}
+
+func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	price, err := r.backend.SuggestPrice(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*price), nil
+}
+
+func (r *Resolver) ProtocolVersion(ctx context.Context) (int32, error) {
+	return int32(r.backend.ProtocolVersion()), nil
+}
+
+// SyncState represents the synchronisation status returned from the `syncing`
+// accessor.
+type SyncState struct {
+	progress ethereum.SyncProgress
+}
+
+func (s *SyncState) StartingBlock() hexutil.Uint64 {
+	return hexutil.Uint64(s.progress.StartingBlock)
+}
+
+func (s *SyncState) CurrentBlock() hexutil.Uint64 {
+	return hexutil.Uint64(s.progress.CurrentBlock)
+}
+
+func (s *SyncState) HighestBlock() hexutil.Uint64 {
+	return hexutil.Uint64(s.progress.HighestBlock)
+}
+
+func (s *SyncState) PulledStates() *hexutil.Uint64 {
+	ret := hexutil.Uint64(s.progress.PulledStates)
+	return &ret
+}
+
+func (s *SyncState) KnownStates() *hexutil.Uint64 {
+	ret := hexutil.Uint64(s.progress.KnownStates)
+	return &ret
+}
+
+// Syncing returns false in case the node is currently not syncing with the network. It can be up to date or has not
+// yet received the latest block headers from its pears. In case it is synchronizing:
+// - startingBlock: block number this node started to synchronise from
+// - currentBlock:  block number this node is currently importing
+// - highestBlock:  block number of the highest block header this node has received from peers
+// - pulledStates:  number of state entries processed until now
+// - knownStates:   number of known state entries that still need to be pulled
+func (r *Resolver) Syncing() (*SyncState, error) {
+	progress := r.backend.Downloader().Progress()
+
+	// Return not syncing if the synchronisation already completed
+	if progress.CurrentBlock >= progress.HighestBlock {
+		return nil, nil
+	}
+	// Otherwise gather the block sync stats
+	return &SyncState{progress}, nil
+}
+
+func (r *Resolver) Cycle(ctx context.Context, args struct{ Number *hexutil.Uint64 }) (*Cycle, error) {
+	if r.devote == nil {
+		return nil, nil
+	}
+	info, err := r.devote.GetCycle((*uint64)(args.Number))
+	if err != nil {
+		return nil, err
+	}
+	return &Cycle{info: info}, nil
+}
+
+func (r *Resolver) ConfirmedBlock(ctx context.Context) (*Block, error) {
+	if r.devote == nil {
+		return nil, nil
+	}
+	number, err := r.devote.GetConfirmedBlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	num := rpc.BlockNumber(number.Uint64())
+	return &Block{
+		r:   r,
+		num: &num,
+	}, nil
+}
@@ -0,0 +1,358 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/common/hexutil"
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/state"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/vm"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/internal/ethapi"
+	"github.com/etherzero/go-etherzero/params"
+	"github.com/etherzero/go-etherzero/rpc"
+)
+
+// testBackend is a backend serving a fixed chain of headers, any other method
+// of the embedded interface panics.
+type testBackend struct {
+	ethapi.Backend
+	headers []*types.Header
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number == rpc.LatestBlockNumber {
+		number = rpc.BlockNumber(len(b.headers) - 1)
+	}
+	if number < 0 || int(number) >= len(b.headers) {
+		return nil, nil
+	}
+	return b.headers[number], nil
+}
+
+func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	for _, header := range b.headers {
+		if header.Hash() == hash {
+			return header, nil
+		}
+	}
+	return nil, nil
+}
+
+func newTestBackend(n int) *testBackend {
+	backend := new(testBackend)
+	for i := 0; i < n; i++ {
+		header := &types.Header{
+			Number:     big.NewInt(int64(i)),
+			Time:       big.NewInt(int64(10 * i)),
+			Difficulty: big.NewInt(1),
+			Witness:    fmt.Sprintf("witness%d", i),
+		}
+		if i > 0 {
+			header.ParentHash = backend.headers[i-1].Hash()
+		}
+		backend.headers = append(backend.headers, header)
+	}
+	return backend
+}
+
+// Tests that the schema matches the resolvers.
+func TestBuildSchema(t *testing.T) {
+	if _, err := newHandler(nil, nil); err != nil {
+		t.Fatalf("could not create GraphQL handler: %v", err)
+	}
+}
+
+// Tests that nested block data can be fetched with a single query.
+func TestQueryBlocks(t *testing.T) {
+	backend := newTestBackend(3)
+
+	handler, err := newHandler(backend, nil)
+	if err != nil {
+		t.Fatalf("could not create GraphQL handler: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	query := `{"query": "{ block { number witness timestamp parent { number witness parent { hash } } cycle { number } } missing: block(number: 5) { number } }"}`
+	res, err := http.Post(server.URL+"/graphql", "application/json", strings.NewReader(query))
+	if err != nil {
+		t.Fatalf("failed to post query: %v", err)
+	}
+	defer res.Body.Close()
+
+	var result struct {
+		Data struct {
+			Block struct {
+				Number    string
+				Witness   string
+				Timestamp string
+				Parent    struct {
+					Number  string
+					Witness string
+					Parent  struct {
+						Hash common.Hash
+					}
+				}
+				Cycle *struct{}
+			}
+			Missing *struct{}
+		}
+		Errors []interface{}
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("query failed: %v", result.Errors)
+	}
+	block := result.Data.Block
+	if block.Number != "0x2" || block.Witness != "witness2" || block.Timestamp != "0x14" {
+		t.Errorf("head mismatch: have number %s, witness %s, time %s; want 0x2, witness2, 0x14", block.Number, block.Witness, block.Timestamp)
+	}
+	if block.Parent.Number != "0x1" || block.Parent.Witness != "witness1" {
+		t.Errorf("parent mismatch: have number %s, witness %s; want 0x1, witness1", block.Parent.Number, block.Parent.Witness)
+	}
+	if hash := backend.headers[0].Hash(); block.Parent.Parent.Hash != hash {
+		t.Errorf("grandparent hash mismatch: have %x, want %x", block.Parent.Parent.Hash, hash)
+	}
+	if block.Cycle != nil {
+		t.Errorf("cycle reported without devote data")
+	}
+	if result.Data.Missing != nil {
+		t.Errorf("unknown block reported")
+	}
+}
+
+// chainBackend is a backend serving the headers and states of a real chain, any
+// other method of the embedded interface panics.
+type chainBackend struct {
+	ethapi.Backend
+	chain *core.BlockChain
+}
+
+func (b *chainBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+		return b.chain.CurrentHeader(), nil
+	}
+	return b.chain.GetHeaderByNumber(uint64(number)), nil
+}
+
+func (b *chainBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.chain.GetHeaderByHash(hash), nil
+}
+
+func (b *chainBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	header, _ := b.HeaderByNumber(ctx, number)
+	if header == nil {
+		return nil, nil, nil
+	}
+	statedb, err := b.chain.StateAt(header.Root)
+	return statedb, header, err
+}
+
+// query posts a GraphQL query to the server and decodes its data into result.
+func query(t *testing.T, server *httptest.Server, query string, result interface{}) {
+	body, _ := json.Marshal(map[string]string{"query": query})
+	res, err := http.Post(server.URL+"/graphql", "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("failed to post query: %v", err)
+	}
+	defer res.Body.Close()
+
+	var response struct {
+		Data   json.RawMessage
+		Errors []interface{}
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Errors) > 0 {
+		t.Fatalf("query %q failed: %v", query, response.Errors)
+	}
+	if err := json.Unmarshal(response.Data, result); err != nil {
+		t.Fatalf("failed to decode data: %v", err)
+	}
+}
+
+// cycleResult is the GraphQL representation of a devote cycle.
+type cycleResult struct {
+	Number    hexutil.Uint64
+	FirstSlot hexutil.Uint64
+	LastSlot  hexutil.Uint64
+	Witnesses []struct {
+		ID          string
+		Slot        int
+		Blocks      hexutil.Uint64
+		MissedSlots []hexutil.Uint64
+	}
+}
+
+const cycleFields = "number firstSlot lastSlot witnesses { id slot blocks missedSlots }"
+
+// checkCycle compares a cycle served over GraphQL with the one of the devote API.
+func checkCycle(t *testing.T, name string, have *cycleResult, want *devote.CycleInfo) {
+	if have == nil {
+		t.Errorf("%s: cycle missing", name)
+		return
+	}
+	if uint64(have.Number) != want.Cycle || uint64(have.FirstSlot) != want.FirstSlot || uint64(have.LastSlot) != want.LastSlot {
+		t.Errorf("%s: cycle mismatch: have %d [%d, %d], want %d [%d, %d]", name, have.Number, have.FirstSlot, have.LastSlot, want.Cycle, want.FirstSlot, want.LastSlot)
+	}
+	if len(have.Witnesses) != len(want.Witnesses) {
+		t.Errorf("%s: witness count mismatch: have %d, want %d", name, len(have.Witnesses), len(want.Witnesses))
+		return
+	}
+	for i, witness := range have.Witnesses {
+		stats := want.Witnesses[i]
+		if witness.ID != stats.Witness || witness.Slot != stats.Slot || uint64(witness.Blocks) != stats.Blocks || len(witness.MissedSlots) != len(stats.MissedSlots) {
+			t.Errorf("%s, witness %d: stats mismatch: have %+v, want %+v", name, i, witness, stats)
+			continue
+		}
+		for j, slot := range witness.MissedSlots {
+			if uint64(slot) != stats.MissedSlots[j] {
+				t.Errorf("%s, witness %d: missed slot %d mismatch: have %d, want %d", name, i, j, slot, stats.MissedSlots[j])
+			}
+		}
+	}
+}
+
+// Tests that account power and the devote schedule are served from the state of
+// the chain and the devote API.
+func TestQueryDevote(t *testing.T) {
+	witnesses := []string{"0123456789abcdef", "fedcba9876543210"}
+
+	config := *params.TestChainConfig
+	config.Ethash = nil
+	config.Devote = &params.DevoteConfig{Witnesses: witnesses}
+
+	var (
+		db      = ethdb.NewMemDatabase()
+		account = common.HexToAddress("0xdeadbeef")
+		balance = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+		gspec   = &core.Genesis{
+			Config:     &config,
+			Difficulty: big.NewInt(1),
+			Alloc:      core.GenesisAlloc{account: {Balance: balance}},
+		}
+		genesis = gspec.MustCommit(db)
+		engine  = devote.NewFaker(witnesses, db)
+	)
+	// Generate blocks into the second cycle
+	blocks, _ := core.GenerateChain(&config, genesis, engine, db, 65, nil)
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	api := devote.NewAPI(chain, engine)
+
+	handler, err := newHandler(&chainBackend{chain: chain}, api)
+	if err != nil {
+		t.Fatalf("could not create GraphQL handler: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	// Power is reported as regenerated up to the viewed block
+	var accounts struct {
+		Old, Head struct {
+			Account struct {
+				Power    hexutil.Big
+				MaxPower hexutil.Big
+			}
+		}
+	}
+	query(t, server, fmt.Sprintf(`{ old: block(number: 30) { account(address: "%[1]s") { power maxPower } } head: block { account(address: "%[1]s") { power maxPower } } }`, account.Hex()), &accounts)
+
+	for _, tt := range []struct {
+		number uint64
+		have   hexutil.Big
+		max    hexutil.Big
+	}{
+		{30, accounts.Old.Account.Power, accounts.Old.Account.MaxPower},
+		{65, accounts.Head.Account.Power, accounts.Head.Account.MaxPower},
+	} {
+		header := chain.GetHeaderByNumber(tt.number)
+		statedb, _ := chain.StateAt(header.Root)
+		if want := statedb.GetPower(account, header.Number); tt.have.ToInt().Cmp(want) != 0 {
+			t.Errorf("block %d: power mismatch: have %v, want %v", tt.number, tt.have.ToInt(), want)
+		}
+		if want := state.MaxPower(balance); tt.max.ToInt().Cmp(want) != 0 {
+			t.Errorf("block %d: max power mismatch: have %v, want %v", tt.number, tt.max.ToInt(), want)
+		}
+	}
+	if accounts.Old.Account.Power.ToInt().Cmp(accounts.Head.Account.Power.ToInt()) >= 0 {
+		t.Errorf("power not regenerated: %v at block 30, %v at the head", accounts.Old.Account.Power.ToInt(), accounts.Head.Account.Power.ToInt())
+	}
+	// Cycles are served by number, by default the current one, and per block
+	var cycles struct {
+		First, Current *cycleResult
+		Old, Head      struct {
+			Cycle *cycleResult
+		}
+	}
+	query(t, server, `{
+		first: cycle(number: 0) { `+cycleFields+` }
+		current: cycle { `+cycleFields+` }
+		old: block(number: 30) { cycle { `+cycleFields+` } }
+		head: block { cycle { `+cycleFields+` } }
+	}`, &cycles)
+
+	first, _ := api.GetCycle(new(uint64))
+	current, _ := api.GetCycle(nil)
+
+	checkCycle(t, "cycle 0", cycles.First, first)
+	checkCycle(t, "current cycle", cycles.Current, current)
+	checkCycle(t, "block 30", cycles.Old.Cycle, first)
+	checkCycle(t, "head block", cycles.Head.Cycle, current)
+
+	if current.Cycle != 1 {
+		t.Errorf("chain not extending into the second cycle: current cycle %d", current.Cycle)
+	}
+	// The confirmed block is the one tracked by the engine
+	var confirmed struct {
+		ConfirmedBlock *struct {
+			Number hexutil.Uint64
+			Hash   common.Hash
+		}
+	}
+	query(t, server, `{ confirmedBlock { number hash } }`, &confirmed)
+
+	header := engine.ConfirmedBlockHeader(chain)
+	if confirmed.ConfirmedBlock == nil {
+		t.Fatalf("confirmed block missing")
+	}
+	if uint64(confirmed.ConfirmedBlock.Number) != header.Number.Uint64() || confirmed.ConfirmedBlock.Hash != header.Hash() {
+		t.Errorf("confirmed block mismatch: have #%d [%x], want #%d [%x]", confirmed.ConfirmedBlock.Number, confirmed.ConfirmedBlock.Hash, header.Number, header.Hash())
+	}
+}
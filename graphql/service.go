@@ -0,0 +1,109 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/internal/ethapi"
+	"github.com/etherzero/go-etherzero/log"
+	"github.com/etherzero/go-etherzero/p2p"
+	"github.com/etherzero/go-etherzero/rpc"
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+// Service encapsulates a GraphQL service.
+type Service struct {
+	endpoint string           // The host:port endpoint for this service.
+	cors     []string         // Allowed CORS domains
+	vhosts   []string         // Recognised vhosts
+	timeouts rpc.HTTPTimeouts // Timeout settings for HTTP requests.
+	backend  ethapi.Backend   // The backend that queries will operate on.
+	devote   *devote.API      // The devote API serving the witness schedule, nil if unavailable.
+	handler  http.Handler     // The `http.Handler` used to answer queries.
+	server   *http.Server     // The HTTP server serving the queries.
+}
+
+// New constructs a new GraphQL service instance.
+func New(backend ethapi.Backend, devote *devote.API, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts) (*Service, error) {
+	return &Service{
+		endpoint: endpoint,
+		cors:     cors,
+		vhosts:   vhosts,
+		timeouts: timeouts,
+		backend:  backend,
+		devote:   devote,
+	}, nil
+}
+
+// Protocols returns the list of protocols exported by this service.
+func (s *Service) Protocols() []p2p.Protocol { return nil }
+
+// APIs returns the list of APIs exported by this service.
+func (s *Service) APIs() []rpc.API { return nil }
+
+// Start is called after all services have been constructed and the networking
+// layer was also initialized to spawn any goroutines required by the service.
+func (s *Service) Start(server *p2p.Server) error {
+	var err error
+	s.handler, err = newHandler(s.backend, s.devote)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", s.endpoint)
+	if err != nil {
+		return err
+	}
+	s.server = rpc.NewHTTPServer(s.cors, s.vhosts, s.timeouts, s.handler)
+	go s.server.Serve(listener)
+
+	log.Info("GraphQL endpoint opened", "url", fmt.Sprintf("http://%s/graphql", listener.Addr()))
+	return nil
+}
+
+// newHandler returns a new `http.Handler` that will answer GraphQL queries
+// posted to the /graphql endpoint.
+func newHandler(backend ethapi.Backend, devote *devote.API) (http.Handler, error) {
+	q := &Resolver{backend: backend, devote: devote}
+
+	s, err := graphqlgo.ParseSchema(schema, q)
+	if err != nil {
+		return nil, err
+	}
+	h := &relay.Handler{Schema: s}
+
+	mux := http.NewServeMux()
+	mux.Handle("/graphql", h)
+	mux.Handle("/graphql/", h)
+	return mux, nil
+}
+
+// Stop terminates all goroutines belonging to the service, blocking until they
+// are all terminated.
+func (s *Service) Stop() error {
+	if s.server != nil {
+		s.server.Close() // Closes the listener too
+		s.server = nil
+
+		log.Info("GraphQL endpoint closed", "url", fmt.Sprintf("http://%s/graphql", s.endpoint))
+	}
+	return nil
+}
@@ -95,6 +95,18 @@ type ChainStateReader interface {
 	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
 }
 
+// PowerForecast predicts when an account will have regenerated enough power to
+// pay for the gas of a transaction.
+type PowerForecast struct {
+	Power            *big.Int // Power available at the queried block
+	Required         *big.Int // Power needed to pay for gas * price
+	MaxPower         *big.Int // Upper bound of the power for the current balance
+	RegenerationRate *big.Int // Power regenerated per block
+	Reachable        bool     // Whether the balance can ever regenerate the required power
+	Blocks           uint64   // Blocks to wait until the required power is available
+	BlockNumber      *big.Int // First block with enough power, nil if unreachable
+}
+
 // SyncProgress gives progress indications when the node is synchronising with
 // the Ethereum network.
 type SyncProgress struct {
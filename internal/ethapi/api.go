@@ -34,7 +34,9 @@ import (
 	"github.com/etherzero/go-etherzero/consensus/ethash"
 	"github.com/etherzero/go-etherzero/core"
 	"github.com/etherzero/go-etherzero/core/rawdb"
+	"github.com/etherzero/go-etherzero/core/state"
 	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
 	"github.com/etherzero/go-etherzero/core/vm"
 	"github.com/etherzero/go-etherzero/crypto"
 	"github.com/etherzero/go-etherzero/log"
@@ -72,39 +74,86 @@ func (s *PublicEthereumAPI) ProtocolVersion() hexutil.Uint {
 	return hexutil.Uint(s.b.ProtocolVersion())
 }
 
-// Masternodes return masternode info
-func (s *PublicEthereumAPI) Masternodes() []string {
-	return nil
-	//return s.ms.AllNodes()
+// Masternodes returns all masternodes registered in the masternode contract at
+// the given block, or at the latest block if none is specified.
+func (s *PublicEthereumAPI) Masternodes(ctx context.Context, blockNr *rpc.BlockNumber) ([]map[string]interface{}, error) {
+	number := rpc.LatestBlockNumber
+	if blockNr != nil {
+		number = *blockNr
+	}
+	nodes, err := s.b.Masternodes(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]map[string]interface{}, len(nodes))
+	for i, node := range nodes {
+		fields[i] = RPCMarshalMasternode(node)
+	}
+	return fields, nil
 }
 
 // Data return masternode contract node data
-func (s *PublicEthereumAPI) Data() string {
-	return ""
+func (s *PublicEthereumAPI) Data() (string, error) {
+	return s.b.Data()
 }
 
 func (s *PublicEthereumAPI) Ns() int64 {
-	return 0
+	return s.b.Ns()
 }
 
 // GetInfo return related info in masternode contract
-func (s *PublicEthereumAPI) GetInfo(nodeid string) string {
-	return ""
+func (s *PublicEthereumAPI) GetInfo(ctx context.Context, nodeid string, blockNr *rpc.BlockNumber) (map[string]interface{}, error) {
+	number := rpc.LatestBlockNumber
+	if blockNr != nil {
+		number = *blockNr
+	}
+	node, err := s.b.GetInfo(ctx, nodeid, number)
+	if err != nil {
+		return nil, err
+	}
+	return RPCMarshalMasternode(node), nil
 }
 
-// Start return the masternodewinner info
-func (s *PublicEthereumAPI) StartMasternode() bool {
-	return false
+// StartMasternode starts pinging the masternode contract for the local node.
+func (s *PublicEthereumAPI) StartMasternode() (bool, error) {
+	if err := s.b.StartMasternode(); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// Stop return the masternodewinner info
-func (s *PublicEthereumAPI) StopMasternode() bool {
-	return false
+// StopMasternode stops pinging the masternode contract for the local node.
+func (s *PublicEthereumAPI) StopMasternode() (bool, error) {
+	if err := s.b.StopMasternode(); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// join nodeid from genesis block to witness
-func (b *PublicEthereumAPI) JoinMasternode(nodeid string) bool {
-	return true
+// JoinMasternode reports whether the given node has joined the masternode contract.
+func (s *PublicEthereumAPI) JoinMasternode(ctx context.Context, nodeid string) (bool, error) {
+	_, err := s.b.GetInfo(ctx, nodeid, rpc.LatestBlockNumber)
+	if err == masternode.ErrUnknownMasternode {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// RPCMarshalMasternode converts the contract record of a masternode into the
+// RPC output format.
+func RPCMarshalMasternode(node *masternode.Masternode) map[string]interface{} {
+	fields := map[string]interface{}{
+		"id":             node.ID,
+		"nodeId":         node.NodeID.String(),
+		"account":        node.Account,
+		"originBlock":    (*hexutil.Big)(node.OriginBlock),
+		"blockOnlineAcc": (*hexutil.Big)(node.BlockOnlineAcc),
+		"blockLastPing":  (*hexutil.Big)(node.BlockLastPing),
+	}
+	if node.ENode != nil {
+		fields["enode"] = node.ENode.String()
+	}
+	return fields
 }
 
 // Syncing returns false in case the node is currently not syncing with the network. It can be up to date or has not
@@ -185,13 +234,21 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 		"queued":  make(map[string]map[string]string),
 	}
 	pending, queue := s.b.TxPoolContent()
+	waiting := s.b.TxPoolWaiting()
 
 	// Define a formatter to flatten a transaction into a string
 	var format = func(tx *types.Transaction) string {
+		var summary string
 		if to := tx.To(); to != nil {
-			return fmt.Sprintf("%s: %v wei + %v gas × %v wei", tx.To().Hex(), tx.Value(), tx.Gas(), tx.GasPrice())
+			summary = fmt.Sprintf("%s: %v wei + %v gas × %v wei", tx.To().Hex(), tx.Value(), tx.Gas(), tx.GasPrice())
+		} else {
+			summary = fmt.Sprintf("contract creation: %v wei + %v gas × %v wei", tx.Value(), tx.Gas(), tx.GasPrice())
+		}
+		// Transactions waiting for power report the block they are powered at
+		if number, ok := waiting[tx.Hash()]; ok {
+			summary = fmt.Sprintf("%s, waiting for power until block %d", summary, number)
 		}
-		return fmt.Sprintf("contract creation: %v wei + %v gas × %v wei", tx.Value(), tx.Gas(), tx.GasPrice())
+		return summary
 	}
 	// Flatten the pending transactions
 	for account, txs := range pending {
@@ -263,13 +320,21 @@ func (s *PrivateAccountAPI) ListAccounts() []common.Address {
 	return addresses
 }
 
-// Masternodes will return a list master nodes messages.
-func (s *PrivateAccountAPI) List() []string {
-	return s.b.Masternodes()
+// List returns all masternodes registered in the masternode contract.
+func (s *PrivateAccountAPI) List(ctx context.Context) ([]map[string]interface{}, error) {
+	nodes, err := s.b.Masternodes(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]map[string]interface{}, len(nodes))
+	for i, node := range nodes {
+		fields[i] = RPCMarshalMasternode(node)
+	}
+	return fields, nil
 }
 
 // Masternodes will return a list master nodes messages.
-func (s *PrivateAccountAPI) Data() string {
+func (s *PrivateAccountAPI) Data() (string, error) {
 	return s.b.Data()
 }
 
@@ -278,18 +343,28 @@ func (s *PrivateAccountAPI) Ns() int64 {
 }
 
 // GetInfo return related info in masternode contract
-func (s *PrivateAccountAPI) GetInfo(nodeid string) string {
-	return s.b.GetInfo(nodeid)
+func (s *PrivateAccountAPI) GetInfo(ctx context.Context, nodeid string) (map[string]interface{}, error) {
+	node, err := s.b.GetInfo(ctx, nodeid, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return RPCMarshalMasternode(node), nil
 }
 
-// Start  the masternodewinner info
-func (s *PrivateAccountAPI) StartMasternode() bool {
-	return s.b.StartMasternode()
+// StartMasternode starts pinging the masternode contract for the local node.
+func (s *PrivateAccountAPI) StartMasternode() (bool, error) {
+	if err := s.b.StartMasternode(); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// Stop return the masternodewinner info
-func (s *PrivateAccountAPI) StopMasternode() bool {
-	return s.b.StopMasternode()
+// StopMasternode stops pinging the masternode contract for the local node.
+func (s *PrivateAccountAPI) StopMasternode() (bool, error) {
+	if err := s.b.StopMasternode(); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // rawWallet is a JSON representation of an accounts.Wallet interface, with its
@@ -544,14 +619,56 @@ func (s *PrivateAccountAPI) SignAndSendTransaction(ctx context.Context, args Sen
 	return s.SendTransaction(ctx, args, passwd)
 }
 
+// GetPower returns the amount of power the given address can spend on gas in the
+// state of the given block number. The rpc.LatestBlockNumber and rpc.PendingBlockNumber
+// meta block numbers are also allowed.
 func (s *PublicBlockChainAPI) GetPower(ctx context.Context, address common.Address, blockNr rpc.BlockNumber) (*big.Int, error) {
-	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
 	if state == nil || err != nil {
 		return nil, err
 	}
-	header, _ := s.b.HeaderByNumber(context.Background(), rpc.LatestBlockNumber) // latest header should always be available
-	b := state.GetPower(address, header.Number)
-	return b, state.Error()
+	return state.GetPower(address, header.Number), state.Error()
+}
+
+// PowerForecast is the result of a power regeneration forecast.
+type PowerForecast struct {
+	Power            *hexutil.Big   `json:"power"`            // Power available at the queried block
+	Required         *hexutil.Big   `json:"required"`         // Power needed to pay for gas * price
+	MaxPower         *hexutil.Big   `json:"maxPower"`         // Upper bound of the power for the current balance
+	RegenerationRate *hexutil.Big   `json:"regenerationRate"` // Power regenerated per block
+	Reachable        bool           `json:"reachable"`        // Whether the balance can ever regenerate the required power
+	Blocks           hexutil.Uint64 `json:"blocks"`           // Blocks to wait until the required power is available
+	BlockNumber      *hexutil.Big   `json:"blockNumber"`      // First block with enough power, nil if unreachable
+}
+
+// GetPowerForecast predicts when the given address will have regenerated enough
+// power to pay for a transaction with the given gas and gas price, starting from
+// the state of the given block number.
+func (s *PublicBlockChainAPI) GetPowerForecast(ctx context.Context, address common.Address, gas hexutil.Uint64, gasPrice hexutil.Big, blockNr rpc.BlockNumber) (*PowerForecast, error) {
+	statedb, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	var (
+		balance  = statedb.GetBalance(address)
+		power    = statedb.GetPower(address, header.Number)
+		required = new(big.Int).Mul(new(big.Int).SetUint64(uint64(gas)), gasPrice.ToInt())
+	)
+	if err := statedb.Error(); err != nil {
+		return nil, err
+	}
+	forecast := &PowerForecast{
+		Power:            (*hexutil.Big)(power),
+		Required:         (*hexutil.Big)(required),
+		MaxPower:         (*hexutil.Big)(state.MaxPower(balance)),
+		RegenerationRate: (*hexutil.Big)(state.PowerRate(balance)),
+	}
+	if blocks, ok := state.PowerForecast(power, required, balance); ok {
+		forecast.Reachable = true
+		forecast.Blocks = hexutil.Uint64(blocks)
+		forecast.BlockNumber = (*hexutil.Big)(new(big.Int).Add(header.Number, new(big.Int).SetUint64(blocks)))
+	}
+	return forecast, nil
 }
 
 // PublicBlockChainAPI provides an API to access the Ethereum blockchain.
@@ -757,33 +874,117 @@ type CallArgs struct {
 	Data     hexutil.Bytes   `json:"data"`
 }
 
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
-	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
-
-	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
-	if state == nil || err != nil {
-		return nil, 0, false, err
-	}
+// ToMessage converts the call arguments into the message executed by the EVM.
+// A missing sender defaults to the first account of the account manager, a
+// missing gas allowance and gas price to the given defaults.
+func (args *CallArgs) ToMessage(am *accounts.Manager, gas uint64, gasPrice *big.Int) types.Message {
 	// Set sender address or use a default if none specified
 	addr := args.From
 	if addr == (common.Address{}) {
-		if wallets := s.b.AccountManager().Wallets(); len(wallets) > 0 {
+		if wallets := am.Wallets(); len(wallets) > 0 {
 			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
 				addr = accounts[0].Address
 			}
 		}
 	}
 	// Set default gas & gas price if none were set
-	gas, gasPrice := uint64(args.Gas), args.GasPrice.ToInt()
-	if gas == 0 {
-		gas = math.MaxUint64 / 2
+	if args.Gas != 0 {
+		gas = uint64(args.Gas)
+	}
+	if args.GasPrice.ToInt().Sign() != 0 {
+		gasPrice = args.GasPrice.ToInt()
+	}
+	return types.NewMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+}
+
+// OverrideAccount specifies the fields of an account to replace in the state a
+// call is executed on. State replaces the entire storage of the account, whereas
+// StateDiff only replaces the given slots, so at most one of them may be set.
+type OverrideAccount struct {
+	Nonce     *hexutil.Uint64              `json:"nonce"`
+	Code      *hexutil.Bytes               `json:"code"`
+	Balance   *hexutil.Big                 `json:"balance"`
+	Power     *hexutil.Big                 `json:"power"`
+	State     *map[common.Hash]common.Hash `json:"state"`
+	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
+}
+
+// StateOverride is the set of accounts to override in the state of a call.
+type StateOverride map[common.Address]OverrideAccount
+
+// Apply overrides the accounts in the given state. The power of an account is
+// set as regenerated up to the given block, so it's exactly the power the call
+// can spend on gas.
+func (diff *StateOverride) Apply(state *state.StateDB, blockNumber *big.Int) error {
+	if diff == nil {
+		return nil
+	}
+	for addr, account := range *diff {
+		if account.State != nil && account.StateDiff != nil {
+			return fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
+		}
+		if account.Nonce != nil {
+			state.SetNonce(addr, uint64(*account.Nonce))
+		}
+		if account.Code != nil {
+			state.SetCode(addr, *account.Code)
+		}
+		if account.Balance != nil {
+			state.SetBalance(addr, new(big.Int).Set(account.Balance.ToInt()), blockNumber)
+		}
+		if account.Power != nil {
+			state.UpdatePower(addr, blockNumber)
+			state.SetPower(addr, new(big.Int).Set(account.Power.ToInt()))
+		}
+		if account.State != nil {
+			state.SetStorage(addr, *account.State)
+		}
+		if account.StateDiff != nil {
+			for key, value := range *account.StateDiff {
+				state.SetState(addr, key, value)
+			}
+		}
+	}
+	return nil
+}
+
+// BlockOverrides is the set of header fields to override in the block a call is
+// executed on.
+type BlockOverrides struct {
+	Number   *hexutil.Big    `json:"number"`
+	Time     *hexutil.Big    `json:"time"`
+	GasLimit *hexutil.Uint64 `json:"gasLimit"`
+}
+
+// Apply returns a copy of the header with the overridden fields replaced.
+func (diff *BlockOverrides) Apply(header *types.Header) *types.Header {
+	if diff == nil {
+		return header
+	}
+	header = types.CopyHeader(header)
+	if diff.Number != nil {
+		header.Number = new(big.Int).Set(diff.Number.ToInt())
 	}
-	if gasPrice.Sign() == 0 {
-		gasPrice = new(big.Int).SetUint64(defaultGasPrice)
+	if diff.Time != nil {
+		header.Time = new(big.Int).Set(diff.Time.ToInt())
 	}
+	if diff.GasLimit != nil {
+		header.GasLimit = uint64(*diff.GasLimit)
+	}
+	return header
+}
+
+func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride, blockOverrides *BlockOverrides, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
+
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, 0, false, err
+	}
+	header = blockOverrides.Apply(header)
 
 	// Create new call message
-	msg := types.NewMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+	msg := args.ToMessage(s.b.AccountManager(), math.MaxUint64/2, new(big.Int).SetUint64(defaultGasPrice))
 
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
@@ -802,6 +1003,11 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	if err != nil {
 		return nil, 0, false, err
 	}
+	// Override the accounts only after the backend funded the sender, so that an
+	// overridden sender balance or power is honoured by the call
+	if err := overrides.Apply(state, header.Number); err != nil {
+		return nil, 0, false, err
+	}
 	// Wait for the context to be done and cancel the evm. Even if the
 	// EVM has finished, cancelling may be done (repeatedly)
 	go func() {
@@ -821,14 +1027,17 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 
 // Call executes the given transaction on the state for the given block number.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
-	result, _, _, err := s.doCall(ctx, args, blockNr, vm.Config{}, 5*time.Second)
+// The optional overrides replace accounts of the state and fields of the block
+// header for this call only.
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride, blockOverrides *BlockOverrides) (hexutil.Bytes, error) {
+	result, _, _, err := s.doCall(ctx, args, blockNr, overrides, blockOverrides, vm.Config{}, 5*time.Second)
 	return (hexutil.Bytes)(result), err
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
-// given transaction against the current pending block.
-func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (hexutil.Uint64, error) {
+// given transaction against the current pending block, with the optional state
+// and block overrides applied.
+func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs, overrides *StateOverride, blockOverrides *BlockOverrides) (hexutil.Uint64, error) {
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
 		lo  uint64 = params.TxGas - 1
@@ -838,12 +1047,13 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	if uint64(args.Gas) >= params.TxGas {
 		hi = uint64(args.Gas)
 	} else {
-		// Retrieve the current pending block to act as the gas ceiling
+		// Retrieve the current pending block to act as the gas ceiling, as
+		// overridden for the calls below
 		block, err := s.b.BlockByNumber(ctx, rpc.PendingBlockNumber)
 		if err != nil {
 			return 0, err
 		}
-		hi = block.GasLimit()
+		hi = blockOverrides.Apply(block.Header()).GasLimit
 	}
 	cap = hi
 
@@ -851,7 +1061,7 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	executable := func(gas uint64) bool {
 		args.Gas = hexutil.Uint64(gas)
 
-		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, vm.Config{}, 0)
+		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, overrides, blockOverrides, vm.Config{}, 0)
 		if err != nil || failed {
 			return false
 		}
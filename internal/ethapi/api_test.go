@@ -0,0 +1,483 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/etherzero/go-etherzero/accounts"
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/common/hexutil"
+	"github.com/etherzero/go-etherzero/common/math"
+	"github.com/etherzero/go-etherzero/consensus/ethash"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/state"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/vm"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/ethdb"
+	"github.com/etherzero/go-etherzero/params"
+	"github.com/etherzero/go-etherzero/rpc"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testBalance = new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+)
+
+// testBackend is a chain backed implementation of the API backend, serving the
+// pending block and state from the head of the chain. Methods not needed by the
+// tests are left to the embedded nil interface.
+type testBackend struct {
+	Backend
+
+	db    ethdb.Database
+	chain *core.BlockChain
+	am    *accounts.Manager
+}
+
+// newTestBackend creates a backend with a chain of n blocks on top of a genesis
+// funding the test account.
+func newTestBackend(t *testing.T, n int, generator func(int, *core.BlockGen)) *testBackend {
+	return newTestBackendWithAlloc(t, nil, n, generator)
+}
+
+// newTestBackendWithAlloc creates a backend with a chain of n blocks on top of a
+// genesis funding the test account along with the given accounts.
+func newTestBackendWithAlloc(t *testing.T, alloc core.GenesisAlloc, n int, generator func(int, *core.BlockGen)) *testBackend {
+	genesisAlloc := core.GenesisAlloc{testAddr: {Balance: testBalance}}
+	for addr, account := range alloc {
+		genesisAlloc[addr] = account
+	}
+	var (
+		db      = ethdb.NewMemDatabase()
+		gspec   = &core.Genesis{Config: params.TestChainConfig, Alloc: genesisAlloc}
+		genesis = gspec.MustCommit(db)
+	)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, n, generator)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return &testBackend{db: db, chain: chain, am: accounts.NewManager()}
+}
+
+func (b *testBackend) ChainConfig() *params.ChainConfig  { return b.chain.Config() }
+func (b *testBackend) ChainDb() ethdb.Database           { return b.db }
+func (b *testBackend) CurrentBlock() *types.Block        { return b.chain.CurrentBlock() }
+func (b *testBackend) AccountManager() *accounts.Manager { return b.am }
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
+	if blockNr == rpc.LatestBlockNumber || blockNr == rpc.PendingBlockNumber {
+		return b.chain.CurrentHeader(), nil
+	}
+	return b.chain.GetHeaderByNumber(uint64(blockNr)), nil
+}
+
+func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.chain.GetHeaderByHash(hash), nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error) {
+	if blockNr == rpc.LatestBlockNumber || blockNr == rpc.PendingBlockNumber {
+		return b.chain.CurrentBlock(), nil
+	}
+	return b.chain.GetBlockByNumber(uint64(blockNr)), nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return b.chain.GetBlockByHash(hash), nil
+}
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	header, err := b.HeaderByNumber(ctx, blockNr)
+	if header == nil || err != nil {
+		return nil, nil, err
+	}
+	statedb, err := b.chain.StateAt(header.Root)
+	return statedb, header, err
+}
+
+// GetEVM funds the sender the same way the full node backend does.
+func (b *testBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
+	state.SetBalance(msg.From(), math.MaxBig256, header.Number)
+	state.SetPower(msg.From(), math.MaxBig256)
+	vmError := func() error { return nil }
+
+	context := core.NewEVMContext(msg, header, b.chain, nil)
+	return vm.NewEVM(context, state, b.chain.Config(), vmCfg), vmError, nil
+}
+
+// Tests that the power of an account is reported as regenerated up to the
+// queried block.
+func TestGetPower(t *testing.T) {
+	backend := newTestBackend(t, 4, nil)
+	api := NewPublicBlockChainAPI(backend)
+
+	for _, number := range []rpc.BlockNumber{0, 2, rpc.LatestBlockNumber} {
+		statedb, header, _ := backend.StateAndHeaderByNumber(context.Background(), number)
+		want := statedb.GetPower(testAddr, header.Number)
+
+		power, err := api.GetPower(context.Background(), testAddr, number)
+		if err != nil {
+			t.Fatalf("block %d: failed to retrieve power: %v", number, err)
+		}
+		if power.Cmp(want) != 0 {
+			t.Errorf("block %d: power mismatch: have %v, want %v", number, power, want)
+		}
+	}
+	// Power regenerates with every block, so the head must hold more than block 2
+	early, _ := api.GetPower(context.Background(), testAddr, 2)
+	head, _ := api.GetPower(context.Background(), testAddr, rpc.LatestBlockNumber)
+	if head.Cmp(early) <= 0 {
+		t.Errorf("power not regenerated: block 2 %v, head %v", early, head)
+	}
+}
+
+// Tests that power forecasts are derived from the state of the queried block.
+func TestGetPowerForecast(t *testing.T) {
+	backend := newTestBackend(t, 2, nil)
+	api := NewPublicBlockChainAPI(backend)
+
+	var (
+		poor     = common.HexToAddress("0xdeadbeef")
+		gasPrice = hexutil.Big(*big.NewInt(params.GWei))
+		max      = state.MaxPower(testBalance)
+		rate     = state.PowerRate(testBalance)
+		head     = backend.CurrentBlock().Number()
+	)
+	power, _ := api.GetPower(context.Background(), testAddr, rpc.LatestBlockNumber)
+
+	// A transaction affordable right away needs no blocks to wait
+	forecast, err := api.GetPowerForecast(context.Background(), testAddr, hexutil.Uint64(params.TxGas), gasPrice, rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatalf("failed to forecast power: %v", err)
+	}
+	if !forecast.Reachable || forecast.Blocks != 0 || forecast.BlockNumber.ToInt().Cmp(head) != 0 {
+		t.Errorf("affordable forecast mismatch: reachable %v, blocks %d, number %v", forecast.Reachable, forecast.Blocks, forecast.BlockNumber)
+	}
+	if forecast.Power.ToInt().Cmp(power) != 0 || forecast.MaxPower.ToInt().Cmp(max) != 0 || forecast.RegenerationRate.ToInt().Cmp(rate) != 0 {
+		t.Errorf("forecast state mismatch: power %v, max %v, rate %v", forecast.Power, forecast.MaxPower, forecast.RegenerationRate)
+	}
+	// A transaction worth the whole max power needs to wait for regeneration
+	gas := new(big.Int).Div(max, gasPrice.ToInt()).Uint64()
+	forecast, err = api.GetPowerForecast(context.Background(), testAddr, hexutil.Uint64(gas), gasPrice, rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatalf("failed to forecast power: %v", err)
+	}
+	blocks, _ := state.PowerForecast(power, new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice.ToInt()), testBalance)
+	if !forecast.Reachable || uint64(forecast.Blocks) != blocks || blocks == 0 {
+		t.Errorf("regeneration forecast mismatch: reachable %v, blocks %d, want %d", forecast.Reachable, forecast.Blocks, blocks)
+	}
+	if want := new(big.Int).Add(head, new(big.Int).SetUint64(blocks)); forecast.BlockNumber.ToInt().Cmp(want) != 0 {
+		t.Errorf("regeneration block mismatch: have %v, want %v", forecast.BlockNumber, want)
+	}
+	// Accounts without balance never regenerate any power
+	forecast, err = api.GetPowerForecast(context.Background(), poor, hexutil.Uint64(params.TxGas), gasPrice, rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatalf("failed to forecast power: %v", err)
+	}
+	if forecast.Reachable || forecast.BlockNumber != nil || forecast.RegenerationRate.ToInt().Sign() != 0 {
+		t.Errorf("unreachable forecast mismatch: reachable %v, number %v, rate %v", forecast.Reachable, forecast.BlockNumber, forecast.RegenerationRate)
+	}
+}
+
+// Contracts executed by the call tests.
+var (
+	storageAddr = common.HexToAddress("0x1000") // Returns storage slots 0 and 1
+	createAddr  = common.HexToAddress("0x2000") // Creates an empty contract and returns its address
+	envAddr     = common.HexToAddress("0x3000") // Returns the block number and time
+	balanceAddr = common.HexToAddress("0x4000") // Returns the balance of the caller
+	guardAddr   = common.HexToAddress("0x5000") // Fails unless storage slot 0 is set, empty in the genesis
+
+	storageCode = common.FromHex("0x60005460005260015460205260406000f3")
+	createCode  = common.FromHex("0x600060006000f060005260206000f3")
+	envCode     = common.FromHex("0x436000524260205260406000f3")
+	balanceCode = common.FromHex("0x333160005260206000f3")
+	guardCode   = common.FromHex("0x600054600757fe5b00")
+
+	callAlloc = core.GenesisAlloc{
+		storageAddr: {Balance: common.Big0, Code: storageCode, Storage: map[common.Hash]common.Hash{
+			common.BigToHash(common.Big0): common.BigToHash(common.Big1),
+			common.BigToHash(common.Big1): common.BigToHash(common.Big2),
+		}},
+		createAddr:  {Balance: common.Big0, Code: createCode, Nonce: 1},
+		envAddr:     {Balance: common.Big0, Code: envCode},
+		balanceAddr: {Balance: common.Big0, Code: balanceCode},
+	}
+)
+
+// words concatenates the given numbers as 32 byte words.
+func words(values ...*big.Int) []byte {
+	var out []byte
+	for _, v := range values {
+		out = append(out, common.LeftPadBytes(v.Bytes(), 32)...)
+	}
+	return out
+}
+
+// Tests that calls execute against the state and block header with the given
+// overrides applied.
+func TestCallOverrides(t *testing.T) {
+	backend := newTestBackendWithAlloc(t, callAlloc, 2, nil)
+	api := NewPublicBlockChainAPI(backend)
+
+	var (
+		sender = common.HexToAddress("0xdeadbeef")
+		gas    = hexutil.Uint64(50000)
+		price  = hexutil.Big(*big.NewInt(params.GWei))
+		cost   = new(big.Int).Mul(big.NewInt(50000), big.NewInt(params.GWei))
+		head   = backend.CurrentBlock()
+	)
+	slot := func(n int64) common.Hash { return common.BigToHash(big.NewInt(n)) }
+	nonce := func(n uint64) *hexutil.Uint64 { return (*hexutil.Uint64)(&n) }
+	code := func(c []byte) *hexutil.Bytes { return (*hexutil.Bytes)(&c) }
+	amount := func(v *big.Int) *hexutil.Big { return (*hexutil.Big)(v) }
+	storage := func(kv ...int64) *map[common.Hash]common.Hash {
+		m := make(map[common.Hash]common.Hash)
+		for i := 0; i < len(kv); i += 2 {
+			m[slot(kv[i])] = slot(kv[i+1])
+		}
+		return &m
+	}
+	tests := []struct {
+		name      string
+		args      CallArgs
+		overrides *StateOverride
+		block     *BlockOverrides
+		want      []byte
+		err       string
+	}{
+		// State overrides
+		{
+			name: "no overrides",
+			args: CallArgs{From: sender, To: &storageAddr},
+			want: words(common.Big1, common.Big2),
+		},
+		{
+			name:      "full storage",
+			args:      CallArgs{From: sender, To: &storageAddr},
+			overrides: &StateOverride{storageAddr: {State: storage(1, 5)}},
+			want:      words(common.Big0, big.NewInt(5)),
+		},
+		{
+			name:      "storage diff",
+			args:      CallArgs{From: sender, To: &storageAddr},
+			overrides: &StateOverride{storageAddr: {StateDiff: storage(1, 5)}},
+			want:      words(common.Big1, big.NewInt(5)),
+		},
+		{
+			name:      "storage and diff",
+			args:      CallArgs{From: sender, To: &storageAddr},
+			overrides: &StateOverride{storageAddr: {State: storage(1, 5), StateDiff: storage(0, 5)}},
+			err:       "both 'state' and 'stateDiff'",
+		},
+		{
+			name:      "code",
+			args:      CallArgs{From: sender, To: &guardAddr},
+			overrides: &StateOverride{guardAddr: {Code: code(storageCode), StateDiff: storage(0, 7)}},
+			want:      words(big.NewInt(7), common.Big0),
+		},
+		{
+			name: "nonce",
+			args: CallArgs{From: sender, To: &createAddr},
+			want: common.LeftPadBytes(crypto.CreateAddress(createAddr, 1).Bytes(), 32),
+		},
+		{
+			name:      "nonce overridden",
+			args:      CallArgs{From: sender, To: &createAddr},
+			overrides: &StateOverride{createAddr: {Nonce: nonce(42)}},
+			want:      common.LeftPadBytes(crypto.CreateAddress(createAddr, 42).Bytes(), 32),
+		},
+		{
+			name: "sender funded by the backend",
+			args: CallArgs{From: sender, To: &balanceAddr},
+			want: words(math.MaxBig256),
+		},
+		{
+			name:      "sender balance",
+			args:      CallArgs{From: sender, To: &balanceAddr},
+			overrides: &StateOverride{sender: {Balance: amount(testBalance)}},
+			want:      words(testBalance),
+		},
+		{
+			name:      "sender balance without power",
+			args:      CallArgs{From: sender, To: &balanceAddr},
+			overrides: &StateOverride{sender: {Balance: amount(big.NewInt(12345))}},
+			err:       "insufficient power",
+		},
+		// Power overrides, which must not be regenerated on top
+		{
+			name:      "power sufficient",
+			args:      CallArgs{From: sender, To: &storageAddr, Gas: gas, GasPrice: price},
+			overrides: &StateOverride{sender: {Power: amount(cost)}},
+			want:      words(common.Big1, common.Big2),
+		},
+		{
+			name:      "power starved",
+			args:      CallArgs{From: sender, To: &storageAddr, Gas: gas, GasPrice: price},
+			overrides: &StateOverride{sender: {Power: amount(new(big.Int).Sub(cost, common.Big1))}},
+			err:       "insufficient power",
+		},
+		{
+			name:      "power starved with balance",
+			args:      CallArgs{From: sender, To: &storageAddr, Gas: gas, GasPrice: price},
+			overrides: &StateOverride{sender: {Balance: amount(testBalance), Power: amount(common.Big0)}},
+			err:       "insufficient power",
+		},
+		// Block overrides
+		{
+			name: "block context",
+			args: CallArgs{From: sender, To: &envAddr},
+			want: words(head.Number(), head.Time()),
+		},
+		{
+			name:  "block context overridden",
+			args:  CallArgs{From: sender, To: &envAddr},
+			block: &BlockOverrides{Number: amount(big.NewInt(1000)), Time: amount(big.NewInt(12345))},
+			want:  words(big.NewInt(1000), big.NewInt(12345)),
+		},
+	}
+	for _, tt := range tests {
+		result, err := api.Call(context.Background(), tt.args, rpc.LatestBlockNumber, tt.overrides, tt.block)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: error mismatch: have %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: call failed: %v", tt.name, err)
+			continue
+		}
+		if !bytes.Equal(result, tt.want) {
+			t.Errorf("%s: result mismatch: have %x, want %x", tt.name, result, tt.want)
+		}
+	}
+	// Overrides only apply to the call, never to the chain state
+	statedb, _ := backend.chain.State()
+	if value := statedb.GetState(storageAddr, slot(1)); value != slot(2) {
+		t.Errorf("storage override leaked into the chain: %x", value)
+	}
+}
+
+// Tests that calls without a gas allowance or gas price run with the defaults of
+// the call API, and that explicit ones take precedence.
+func TestCallDefaults(t *testing.T) {
+	// Returns the remaining gas and the gas price of the call
+	gasAddr := common.HexToAddress("0x6000")
+	alloc := core.GenesisAlloc{gasAddr: {Balance: common.Big0, Code: common.FromHex("0x5a6000523a60205260406000f3")}}
+
+	api := NewPublicBlockChainAPI(newTestBackendWithAlloc(t, alloc, 1, nil))
+	sender := common.HexToAddress("0xdeadbeef")
+
+	tests := []struct {
+		args  CallArgs
+		gas   uint64
+		price *big.Int
+	}{
+		{CallArgs{From: sender, To: &gasAddr}, math.MaxUint64 / 2, new(big.Int).SetUint64(defaultGasPrice)},
+		{CallArgs{From: sender, To: &gasAddr, Gas: 50000, GasPrice: hexutil.Big(*big.NewInt(7))}, 50000, big.NewInt(7)},
+	}
+	for i, tt := range tests {
+		res, _, failed, err := api.doCall(context.Background(), tt.args, rpc.LatestBlockNumber, nil, nil, vm.Config{}, 0)
+		if err != nil || failed {
+			t.Errorf("test %d: call failed: %v", i, err)
+			continue
+		}
+		// The intrinsic gas and the GAS opcode itself are spent before reading it
+		left := new(big.Int).SetUint64(tt.gas - params.TxGas - 2)
+		if want := words(left, tt.price); !bytes.Equal(res, want) {
+			t.Errorf("test %d: gas and price mismatch: have %x, want %x", i, res, want)
+		}
+	}
+}
+
+// Tests that overridden power is exactly the power available at the call block,
+// without any regeneration since the last update of the account on top.
+func TestStateOverridePower(t *testing.T) {
+	backend := newTestBackend(t, 2, nil)
+
+	statedb, header, _ := backend.StateAndHeaderByNumber(context.Background(), rpc.LatestBlockNumber)
+	number := new(big.Int).Add(header.Number, big.NewInt(100))
+	if statedb.GetPower(testAddr, number).Cmp(statedb.GetPower(testAddr, header.Number)) <= 0 {
+		t.Fatalf("test account not regenerating power")
+	}
+	power := big.NewInt(21000)
+	overrides := &StateOverride{testAddr: {Power: (*hexutil.Big)(power)}}
+	if err := overrides.Apply(statedb, number); err != nil {
+		t.Fatalf("failed to apply overrides: %v", err)
+	}
+	if have := statedb.GetPower(testAddr, number); have.Cmp(power) != 0 {
+		t.Errorf("power mismatch: have %v, want %v", have, power)
+	}
+	if have := statedb.GetBalance(testAddr); have.Cmp(testBalance) != 0 {
+		t.Errorf("balance changed by power override: have %v, want %v", have, testBalance)
+	}
+}
+
+// Tests that gas estimations run with the same overrides as calls, including the
+// gas limit of the pending block they are capped at.
+func TestEstimateGasOverrides(t *testing.T) {
+	backend := newTestBackendWithAlloc(t, callAlloc, 2, nil)
+	api := NewPublicBlockChainAPI(backend)
+
+	var (
+		sender  = common.HexToAddress("0xdeadbeef")
+		guarded = &StateOverride{guardAddr: {Code: (*hexutil.Bytes)(&guardCode), StateDiff: &map[common.Hash]common.Hash{{}: common.BigToHash(common.Big1)}}}
+		limit   = func(gas uint64) *BlockOverrides { return &BlockOverrides{GasLimit: (*hexutil.Uint64)(&gas)} }
+		power   = func(v int64) *StateOverride { return &StateOverride{sender: {Power: (*hexutil.Big)(big.NewInt(v))}} }
+	)
+	tests := []struct {
+		name      string
+		to        common.Address
+		overrides *StateOverride
+		block     *BlockOverrides
+		want      uint64
+	}{
+		{name: "transfer", to: common.Address{1}, want: params.TxGas},
+		{name: "transfer within the overridden gas limit", to: common.Address{1}, block: limit(params.TxGas), want: params.TxGas},
+		{name: "transfer beyond the overridden gas limit", to: common.Address{1}, block: limit(params.TxGas - 1)},
+		{name: "failing contract", to: guardAddr, overrides: &StateOverride{guardAddr: {Code: (*hexutil.Bytes)(&guardCode)}}},
+		{name: "contract enabled by overrides", to: guardAddr, overrides: guarded, want: params.TxGas + 3 + params.GasTableEIP158.SLoad + 3 + 10 + 1},
+		{name: "power starved sender", to: common.Address{1}, overrides: power(0)},
+	}
+	for _, tt := range tests {
+		gas, err := api.EstimateGas(context.Background(), CallArgs{From: sender, To: &tt.to}, tt.overrides, tt.block)
+		if tt.want == 0 {
+			if err == nil {
+				t.Errorf("%s: estimated %d gas for an impossible call", tt.name, gas)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: estimation failed: %v", tt.name, err)
+			continue
+		}
+		if uint64(gas) != tt.want {
+			t.Errorf("%s: gas mismatch: have %d, want %d", tt.name, gas, tt.want)
+		}
+	}
+}
@@ -23,9 +23,12 @@ import (
 
 	"github.com/etherzero/go-etherzero/accounts"
 	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/consensus/devote"
 	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/bloombits"
 	"github.com/etherzero/go-etherzero/core/state"
 	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
 	"github.com/etherzero/go-etherzero/core/vm"
 	"github.com/etherzero/go-etherzero/eth/downloader"
 	"github.com/etherzero/go-etherzero/ethdb"
@@ -46,16 +49,25 @@ type Backend interface {
 	AccountManager() *accounts.Manager
 
 	// masternode control api
-	Masternodes() []string        // masternodes info
-	Data() string                 // return masternode contract nodes data
-	GetInfo(nodeid string) string // return related info in masternode contract
-	StartMasternode() bool        // start the masternode,hash ,srvr means two different parameters
-	StopMasternode() bool         // stop the masternode,hash ,srvr means two different parameters
-	Ns() int64                    // nanoseconds
+	Masternodes(ctx context.Context, blockNr rpc.BlockNumber) ([]*masternode.Masternode, error)          // all masternodes registered in the contract
+	Data() (string, error)                                                                               // return masternode contract nodes data
+	GetInfo(ctx context.Context, nodeid string, blockNr rpc.BlockNumber) (*masternode.Masternode, error) // return related info in masternode contract
+	StartMasternode() error                                                                              // start pinging the masternode contract
+	StopMasternode() error                                                                               // stop pinging the masternode contract
+	ActiveMasternode() (*masternode.ActiveMasternode, error)                                             // identity of the local masternode
+	MasternodePinging() bool                                                                             // whether the local masternode pings the contract
+	MasternodePingState() *masternode.PingState                                                          // ping schedule of the local masternode
+	Ns() int64                                                                                           // nanoseconds
+	DoubleSignEvidence() ([]*devote.DoubleSignEvidence, error)                                           // double signing evidence collected by the devote engine
+
+	// governance api
+	Governance(ctx context.Context, blockNr rpc.BlockNumber) (*masternode.Governance, error)                    // governance address and proposals in the contract
+	GovernanceVoted(ctx context.Context, proposal, voter common.Address, blockNr rpc.BlockNumber) (bool, error) // whether an owner voted for a proposal
 
 	// BlockChain API
 	SetHead(number uint64)
 	HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error)
+	HeaderByHash(ctx context.Context, blockHash common.Hash) (*types.Header, error)
 	BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error)
 	StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error)
 	GetBlock(ctx context.Context, blockHash common.Hash) (*types.Block, error)
@@ -73,8 +85,17 @@ type Backend interface {
 	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
+	TxPoolWaiting() map[common.Hash]uint64
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
 
+	// Filter API
+	GetLogs(ctx context.Context, blockHash common.Hash) ([][]*types.Log, error)
+	BloomStatus() (uint64, uint64)
+	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
+	SubscribeChainFinalizedEvent(ch chan<- core.ChainFinalizedEvent) event.Subscription
+	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
+	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
+
 	ChainConfig() *params.ChainConfig
 	CurrentBlock() *types.Block
 }
@@ -126,6 +147,21 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewPrivateAccountAPI(apiBackend, nonceLock),
 			Public:    false,
+		}, {
+			Namespace: "masternode",
+			Version:   "1.0",
+			Service:   NewPrivateMasternodeAPI(apiBackend, nonceLock),
+			Public:    false,
+		}, {
+			Namespace: "governance",
+			Version:   "1.0",
+			Service:   NewPublicGovernanceAPI(apiBackend),
+			Public:    true,
+		}, {
+			Namespace: "governance",
+			Version:   "1.0",
+			Service:   NewPrivateGovernanceAPI(apiBackend, nonceLock),
+			Public:    false,
 		},
 	}
 }
@@ -0,0 +1,254 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/common/hexutil"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
+	"github.com/etherzero/go-etherzero/params"
+	"github.com/etherzero/go-etherzero/rpc"
+)
+
+var (
+	errProposalExists = errors.New("governance address already proposed")
+	errProposalClosed = errors.New("governance proposal is not open for voting")
+	errAlreadyVoted   = errors.New("already voted for the governance proposal")
+)
+
+// PublicGovernanceAPI provides an API to inspect the governance voting of the
+// masternodes on the address receiving the community reward.
+type PublicGovernanceAPI struct {
+	b Backend
+}
+
+// NewPublicGovernanceAPI creates a new governance API.
+func NewPublicGovernanceAPI(b Backend) *PublicGovernanceAPI {
+	return &PublicGovernanceAPI{b}
+}
+
+// RPCProposal is a governance proposal in the RPC output format.
+type RPCProposal struct {
+	Address    common.Address `json:"address"`
+	Creator    common.Address `json:"creator"`
+	Votes      hexutil.Uint64 `json:"votes"`
+	Quorum     hexutil.Uint64 `json:"quorum"`
+	StartBlock hexutil.Uint64 `json:"startBlock"`
+	StopBlock  hexutil.Uint64 `json:"stopBlock"`
+	Open       bool           `json:"open"`
+	Adopted    bool           `json:"adopted"`
+}
+
+// newRPCProposal converts a proposal at the given block into the RPC output format.
+func newRPCProposal(governance *masternode.Governance, proposal *masternode.Proposal, number *big.Int) *RPCProposal {
+	return &RPCProposal{
+		Address:    proposal.Address,
+		Creator:    proposal.Creator,
+		Votes:      hexutil.Uint64(proposal.VoteCount.Uint64()),
+		Quorum:     hexutil.Uint64(governance.Quorum().Uint64()),
+		StartBlock: hexutil.Uint64(proposal.StartBlock.Uint64()),
+		StopBlock:  hexutil.Uint64(proposal.StopBlock.Uint64()),
+		Open:       proposal.Open(number),
+		Adopted:    proposal.Address == governance.Address,
+	}
+}
+
+// governance retrieves the governance state at the given block, or at the
+// latest block if none is specified.
+func (s *PublicGovernanceAPI) governance(ctx context.Context, blockNr *rpc.BlockNumber) (*masternode.Governance, *big.Int, error) {
+	number := rpc.LatestBlockNumber
+	if blockNr != nil {
+		number = *blockNr
+	}
+	header, err := s.b.HeaderByNumber(ctx, number)
+	if header == nil || err != nil {
+		return nil, nil, err
+	}
+	governance, err := s.b.Governance(ctx, rpc.BlockNumber(header.Number.Int64()))
+	if err != nil {
+		return nil, nil, err
+	}
+	return governance, header.Number, nil
+}
+
+// Address returns the governance address set in the masternode contract at the
+// given block.
+func (s *PublicGovernanceAPI) Address(ctx context.Context, blockNr *rpc.BlockNumber) (common.Address, error) {
+	governance, _, err := s.governance(ctx, blockNr)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return governance.Address, nil
+}
+
+// CommunityAddress returns the address credited with the community reward of
+// the given block. Devote reads it from the contract state of the stable block,
+// the maximum witness size behind the parent, so adopted proposals take effect
+// with that delay.
+func (s *PublicGovernanceAPI) CommunityAddress(ctx context.Context, blockNr rpc.BlockNumber) (common.Address, error) {
+	header, err := s.b.HeaderByNumber(ctx, blockNr)
+	if header == nil || err != nil {
+		return common.Address{}, err
+	}
+	stable := int64(0)
+	if header.Number.Sign() > 0 {
+		rules := s.b.ChainConfig().Devote.Params(header.Number)
+		if stable = header.Number.Int64() - 1 - int64(rules.MaxWitnessSize); stable < 0 {
+			stable = 0
+		}
+	}
+	governance, err := s.b.Governance(ctx, rpc.BlockNumber(stable))
+	if err != nil {
+		return common.Address{}, err
+	}
+	return governance.Address, nil
+}
+
+// Proposals returns all governance proposals with their vote tallies at the
+// given block, from the most recently created one.
+func (s *PublicGovernanceAPI) Proposals(ctx context.Context, blockNr *rpc.BlockNumber) ([]*RPCProposal, error) {
+	governance, number, err := s.governance(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	proposals := make([]*RPCProposal, 0, len(governance.Proposals))
+	for _, proposal := range governance.Proposals {
+		proposals = append(proposals, newRPCProposal(governance, proposal, number))
+	}
+	return proposals, nil
+}
+
+// Proposal returns the governance proposal of the given address with its vote
+// tally at the given block.
+func (s *PublicGovernanceAPI) Proposal(ctx context.Context, addr common.Address, blockNr *rpc.BlockNumber) (*RPCProposal, error) {
+	governance, number, err := s.governance(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	proposal, err := governance.Proposal(addr)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCProposal(governance, proposal, number), nil
+}
+
+// HasVoted reports whether the masternode owner voted for the governance
+// proposal of the given address up to the given block.
+func (s *PublicGovernanceAPI) HasVoted(ctx context.Context, proposal, voter common.Address, blockNr *rpc.BlockNumber) (bool, error) {
+	number := rpc.LatestBlockNumber
+	if blockNr != nil {
+		number = *blockNr
+	}
+	return s.b.GovernanceVoted(ctx, proposal, voter, number)
+}
+
+// PrivateGovernanceAPI provides an API to create and vote for governance
+// proposals from the accounts managed by the node.
+type PrivateGovernanceAPI struct {
+	b        Backend
+	accounts *PrivateAccountAPI
+}
+
+// NewPrivateGovernanceAPI creates a new governance API sending transactions
+// from the accounts of the node.
+func NewPrivateGovernanceAPI(b Backend, nonceLock *AddrLocker) *PrivateGovernanceAPI {
+	return &PrivateGovernanceAPI{
+		b:        b,
+		accounts: NewPrivateAccountAPI(b, nonceLock),
+	}
+}
+
+// Propose creates a proposal to change the governance address to addr, paying
+// the proposal fee from the given account.
+func (s *PrivateGovernanceAPI) Propose(ctx context.Context, from common.Address, addr common.Address, passwd string) (common.Hash, error) {
+	governance, err := s.b.Governance(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if _, err := governance.Proposal(addr); err == nil {
+		return common.Hash{}, errProposalExists
+	}
+	input, err := masternode.ProposeInput(addr)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return s.sendGovernanceTx(ctx, from, masternode.ProposalFee, input, passwd)
+}
+
+// Vote votes for the proposal of the governance address addr from the owner
+// account of a masternode. Only owners of masternodes registered for longer
+// than the proposal period may vote.
+func (s *PrivateGovernanceAPI) Vote(ctx context.Context, from common.Address, addr common.Address, passwd string) (common.Hash, error) {
+	header, err := s.b.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if header == nil || err != nil {
+		return common.Hash{}, err
+	}
+	number := rpc.BlockNumber(header.Number.Int64())
+
+	governance, err := s.b.Governance(ctx, number)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	proposal, err := governance.Proposal(addr)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if !proposal.Open(header.Number) {
+		return common.Hash{}, errProposalClosed
+	}
+	voted, err := s.b.GovernanceVoted(ctx, addr, from, number)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if voted {
+		return common.Hash{}, errAlreadyVoted
+	}
+	input, err := masternode.VoteInput(addr)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return s.sendGovernanceTx(ctx, from, new(big.Int), input, passwd)
+}
+
+// sendGovernanceTx sends a call to the masternode contract, estimating its gas
+// first so that calls the contract rejects are reported instead of sent.
+func (s *PrivateGovernanceAPI) sendGovernanceTx(ctx context.Context, from common.Address, value *big.Int, input []byte, passwd string) (common.Hash, error) {
+	call := CallArgs{
+		From:  from,
+		To:    &params.MasterndeContractAddress,
+		Value: hexutil.Big(*value),
+		Data:  input,
+	}
+	gas, err := NewPublicBlockChainAPI(s.b).EstimateGas(ctx, call, nil, nil)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("masternode contract rejects the governance call: %v", err)
+	}
+	data := hexutil.Bytes(input)
+	args := SendTxArgs{
+		From:  from,
+		To:    &params.MasterndeContractAddress,
+		Gas:   &gas,
+		Value: (*hexutil.Big)(value),
+		Data:  &data,
+	}
+	return s.accounts.SendTransaction(ctx, args, passwd)
+}
@@ -0,0 +1,287 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/common/hexutil"
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
+	"github.com/etherzero/go-etherzero/params"
+	"github.com/etherzero/go-etherzero/rpc"
+)
+
+// masternodeTxTimeout is the time to wait for a registration transaction to be
+// included in the chain, after which it is reported as pending.
+var masternodeTxTimeout = 5 * time.Minute
+
+// States of the local masternode in the masternode contract.
+const (
+	MasternodeRegistered   = "registered"
+	MasternodeUnregistered = "unregistered"
+	MasternodePending      = "pending"
+)
+
+var (
+	errMasternodeRegistered   = errors.New("masternode is already registered")
+	errMasternodeUnregistered = errors.New("masternode is not registered")
+	errMasternodeTxFailed     = errors.New("masternode contract transaction failed")
+	errMasternodeOwnerUnknown = errors.New("masternode owner account unknown")
+	errMasternodeOwnerIsNode  = errors.New("masternode owner can't be the masternode account")
+	errUnknownEvidence        = errors.New("unknown double sign evidence")
+	errDoubleSignUnsupported  = errors.New("masternode contract doesn't accept double sign reports")
+)
+
+// PrivateMasternodeAPI provides an API to register the local masternode in the
+// masternode contract and to unregister it again. The stake is sent from an
+// owner account of the account manager, so the API is private by default.
+type PrivateMasternodeAPI struct {
+	b        Backend
+	accounts *PrivateAccountAPI
+}
+
+// NewPrivateMasternodeAPI creates a new masternode API sending transactions
+// through the account manager.
+func NewPrivateMasternodeAPI(b Backend, nonceLock *AddrLocker) *PrivateMasternodeAPI {
+	return &PrivateMasternodeAPI{
+		b:        b,
+		accounts: NewPrivateAccountAPI(b, nonceLock),
+	}
+}
+
+// MasternodeStatus is the state of the local masternode in the masternode contract.
+type MasternodeStatus struct {
+	ID         string                 `json:"id"`
+	Account    common.Address         `json:"account"`
+	State      string                 `json:"state"`
+	Pinging    bool                   `json:"pinging"`
+	Ping       *masternode.PingState  `json:"ping,omitempty"`
+	TxHash     *common.Hash           `json:"txHash,omitempty"`
+	Masternode map[string]interface{} `json:"masternode,omitempty"`
+}
+
+// Status returns the state of the local masternode in the masternode contract.
+func (s *PrivateMasternodeAPI) Status(ctx context.Context) (*MasternodeStatus, error) {
+	active, err := s.b.ActiveMasternode()
+	if err != nil {
+		return nil, err
+	}
+	return s.masternodeStatus(ctx, active)
+}
+
+// Register deposits the masternode stake from the owner account to register the
+// local masternode, and waits until the contract announces it joined.
+func (s *PrivateMasternodeAPI) Register(ctx context.Context, owner common.Address, passwd string) (*MasternodeStatus, error) {
+	active, err := s.b.ActiveMasternode()
+	if err != nil {
+		return nil, err
+	}
+	// The contract treats plain transactions of the masternode account as pings,
+	// so it could never quit and release the stake
+	if owner == active.NodeAccount {
+		return nil, errMasternodeOwnerIsNode
+	}
+	status, err := s.masternodeStatus(ctx, active)
+	if err != nil {
+		return nil, err
+	}
+	if status.State == MasternodeRegistered {
+		return nil, errMasternodeRegistered
+	}
+	input, err := masternode.RegisterInput(active.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	args := SendTxArgs{
+		From:  owner,
+		To:    &params.MasterndeContractAddress,
+		Value: (*hexutil.Big)(masternode.DepositAmount),
+		Data:  (*hexutil.Bytes)(&input),
+	}
+	return s.sendMasternodeTx(ctx, active, args, passwd, masternode.JoinEvent)
+}
+
+// Quit unregisters the local masternode, refunding the stake to the owner
+// account which registered it, and waits until the contract announces it quit.
+func (s *PrivateMasternodeAPI) Quit(ctx context.Context, owner common.Address, passwd string) (*MasternodeStatus, error) {
+	active, err := s.b.ActiveMasternode()
+	if err != nil {
+		return nil, err
+	}
+	// The contract treats plain transactions of the masternode account as pings,
+	// so it could never quit and release the stake
+	if owner == active.NodeAccount {
+		return nil, errMasternodeOwnerIsNode
+	}
+	status, err := s.masternodeStatus(ctx, active)
+	if err != nil {
+		return nil, err
+	}
+	if status.State != MasternodeRegistered {
+		return nil, errMasternodeUnregistered
+	}
+	registrant, ok := status.Masternode["account"].(common.Address)
+	if !ok {
+		return nil, errMasternodeOwnerUnknown
+	}
+	if owner != registrant {
+		return nil, fmt.Errorf("masternode was registered by %s", registrant.Hex())
+	}
+	args := SendTxArgs{
+		From: owner,
+		To:   &params.MasterndeContractAddress,
+	}
+	return s.sendMasternodeTx(ctx, active, args, passwd, masternode.QuitEvent)
+}
+
+// ReportDoubleSign reports the witness caught sealing two different blocks for
+// the given slot to the masternode contract, sending the collected evidence from
+// the owner account of another masternode. The witness is removed once more than
+// half of the masternode owners reported it.
+func (s *PrivateMasternodeAPI) ReportDoubleSign(ctx context.Context, from common.Address, witness string, slot hexutil.Uint64, passwd string) (common.Hash, error) {
+	evidence, err := s.b.DoubleSignEvidence()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var found *devote.DoubleSignEvidence
+	for _, e := range evidence {
+		if e.Witness == witness && e.Slot == uint64(slot) {
+			found = e
+			break
+		}
+	}
+	if found == nil {
+		return common.Hash{}, errUnknownEvidence
+	}
+	raw, err := hex.DecodeString(witness)
+	if err != nil || len(raw) != 8 {
+		return common.Hash{}, fmt.Errorf("invalid witness id %q", witness)
+	}
+	var id [8]byte
+	copy(id[:], raw)
+
+	// Contracts without the method would quit the masternode of the sender
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return common.Hash{}, err
+	}
+	if !masternode.SupportsDoubleSignReports(state.GetCode(params.MasterndeContractAddress)) {
+		return common.Hash{}, errDoubleSignUnsupported
+	}
+	input, err := masternode.ReportDoubleSignInput(id, found.First, found.Second)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	call := CallArgs{
+		From: from,
+		To:   &params.MasterndeContractAddress,
+		Data: input,
+	}
+	gas, err := NewPublicBlockChainAPI(s.b).EstimateGas(ctx, call, nil, nil)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("masternode contract rejects the double sign report: %v", err)
+	}
+	data := hexutil.Bytes(input)
+	args := SendTxArgs{
+		From: from,
+		To:   &params.MasterndeContractAddress,
+		Gas:  &gas,
+		Data: &data,
+	}
+	return s.accounts.SendTransaction(ctx, args, passwd)
+}
+
+// masternodeStatus assembles the state of the local masternode at the head block.
+func (s *PrivateMasternodeAPI) masternodeStatus(ctx context.Context, active *masternode.ActiveMasternode) (*MasternodeStatus, error) {
+	status := &MasternodeStatus{
+		ID:      active.ID,
+		Account: active.NodeAccount,
+		State:   MasternodeUnregistered,
+		Pinging: s.b.MasternodePinging(),
+	}
+	node, err := s.b.GetInfo(ctx, active.ID, rpc.LatestBlockNumber)
+	switch err {
+	case nil:
+		status.State = MasternodeRegistered
+		status.Ping = s.b.MasternodePingState()
+		status.Masternode = RPCMarshalMasternode(node)
+	case masternode.ErrUnknownMasternode:
+	default:
+		return nil, err
+	}
+	return status, nil
+}
+
+// sendMasternodeTx submits a masternode contract transaction and waits for the
+// contract to emit the expected membership event for the local masternode. If
+// the transaction is not included in time, it is reported as pending.
+func (s *PrivateMasternodeAPI) sendMasternodeTx(ctx context.Context, active *masternode.ActiveMasternode, args SendTxArgs, passwd string, event string) (*MasternodeStatus, error) {
+	// Subscribe before sending, so the inclusion cannot be missed
+	chainCh := make(chan core.ChainEvent, 16)
+	sub := s.b.SubscribeChainEvent(chainCh)
+	defer sub.Unsubscribe()
+
+	hash, err := s.accounts.SendTransaction(ctx, args, passwd)
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.NewTimer(masternodeTxTimeout)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case ev := <-chainCh:
+			if ev.Block.Transaction(hash) == nil {
+				continue
+			}
+			for _, l := range ev.Logs {
+				if l.TxHash != hash {
+					continue
+				}
+				if membership := masternode.ParseMembershipLog(l); membership != nil && membership.Event == event && membership.Id == active.X8() {
+					status, err := s.masternodeStatus(ctx, active)
+					if err != nil {
+						return nil, err
+					}
+					status.TxHash = &hash
+					return status, nil
+				}
+			}
+			// Included without the event, the contract rejected the call
+			return nil, errMasternodeTxFailed
+		case err := <-sub.Err():
+			return nil, err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return &MasternodeStatus{
+				ID:      active.ID,
+				Account: active.NodeAccount,
+				State:   MasternodePending,
+				Pinging: s.b.MasternodePinging(),
+				TxHash:  &hash,
+			}, nil
+		}
+	}
+}
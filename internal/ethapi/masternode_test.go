@@ -0,0 +1,314 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/etherzero/go-etherzero/accounts"
+	"github.com/etherzero/go-etherzero/accounts/keystore"
+	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/common/hexutil"
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
+	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/event"
+	"github.com/etherzero/go-etherzero/params"
+	"github.com/etherzero/go-etherzero/rpc"
+)
+
+// masternodeBackend simulates the masternode contract: transactions sent to it
+// are included in a block emitting the configured membership event.
+type masternodeBackend struct {
+	*testBackend
+
+	active   *masternode.ActiveMasternode
+	emit     func(tx *types.Transaction) []*types.Log // Logs of an included transaction, nil for no inclusion
+	evidence []*devote.DoubleSignEvidence             // Double signing evidence collected by the engine
+	feed     event.Feed
+
+	lock  sync.Mutex
+	owner *common.Address // Owner of the registered masternode, nil if unregistered
+	sent  []*types.Transaction
+}
+
+func (b *masternodeBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(params.GWei), nil
+}
+
+func (b *masternodeBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return uint64(len(b.sent)), nil
+}
+
+func (b *masternodeBackend) ActiveMasternode() (*masternode.ActiveMasternode, error) {
+	return b.active, nil
+}
+
+func (b *masternodeBackend) MasternodePinging() bool                    { return false }
+func (b *masternodeBackend) MasternodePingState() *masternode.PingState { return nil }
+
+func (b *masternodeBackend) DoubleSignEvidence() ([]*devote.DoubleSignEvidence, error) {
+	return b.evidence, nil
+}
+
+func (b *masternodeBackend) GetInfo(ctx context.Context, nodeid string, blockNr rpc.BlockNumber) (*masternode.Masternode, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.owner == nil || nodeid != b.active.ID {
+		return nil, masternode.ErrUnknownMasternode
+	}
+	return &masternode.Masternode{ID: b.active.ID, Account: *b.owner, OriginBlock: common.Big1, BlockOnlineAcc: common.Big0, BlockLastPing: common.Big0}, nil
+}
+
+func (b *masternodeBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
+	return b.feed.Subscribe(ch)
+}
+
+// SendTx includes the transaction in the next block, after an unrelated one,
+// and applies the membership event it emits to the contract state.
+func (b *masternodeBackend) SendTx(ctx context.Context, tx *types.Transaction) error {
+	b.lock.Lock()
+	b.sent = append(b.sent, tx)
+	b.lock.Unlock()
+
+	logs := b.emit(tx)
+	if logs == nil {
+		return nil
+	}
+	go func() {
+		b.feed.Send(core.ChainEvent{Block: types.NewBlockWithHeader(&types.Header{Number: common.Big1})})
+
+		for _, l := range logs {
+			if event := masternode.ParseMembershipLog(l); event != nil && event.Id == b.active.X8() {
+				b.lock.Lock()
+				if event.Event == masternode.JoinEvent {
+					b.owner = &event.Addr
+				} else {
+					b.owner = nil
+				}
+				b.lock.Unlock()
+			}
+		}
+		block := types.NewBlock(&types.Header{Number: common.Big2}, []*types.Transaction{tx}, nil, nil)
+		b.feed.Send(core.ChainEvent{Block: block, Hash: block.Hash(), Logs: logs})
+	}()
+	return nil
+}
+
+// membershipLog creates a masternode contract event of the given masternode.
+func membershipLog(name string, id [8]byte, owner common.Address, tx *types.Transaction) *types.Log {
+	return &types.Log{
+		Address: params.MasterndeContractAddress,
+		Topics:  []common.Hash{crypto.Keccak256Hash([]byte(name + "(bytes8,address)"))},
+		Data:    append(common.RightPadBytes(id[:], 32), common.LeftPadBytes(owner[:], 32)...),
+		TxHash:  tx.Hash(),
+	}
+}
+
+// Tests that masternode registrations and quits are sent from the owner account
+// and tracked until the masternode contract announces the membership change.
+func TestSendMasternodeTx(t *testing.T) {
+	defer func(timeout time.Duration) { masternodeTxTimeout = timeout }(masternodeTxTimeout)
+	masternodeTxTimeout = 250 * time.Millisecond
+
+	dir, err := ioutil.TempDir("", "masternode-api-test")
+	if err != nil {
+		t.Fatalf("failed to create keystore dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	if _, err := ks.ImportECDSA(testKey, "pass"); err != nil {
+		t.Fatalf("failed to import owner key: %v", err)
+	}
+	nodeKey, _ := crypto.GenerateKey()
+	active := masternode.NewActiveMasternode(masternode.NewKeySigner(nodeKey))
+	otherKey, _ := crypto.GenerateKey()
+	other := masternode.NewActiveMasternode(masternode.NewKeySigner(otherKey))
+
+	var (
+		stranger = common.HexToAddress("0xdeadbeef")
+		join     = func(tx *types.Transaction) []*types.Log {
+			return []*types.Log{membershipLog(masternode.JoinEvent, active.X8(), testAddr, tx)}
+		}
+		quit = func(tx *types.Transaction) []*types.Log {
+			return []*types.Log{membershipLog(masternode.QuitEvent, active.X8(), testAddr, tx)}
+		}
+	)
+	tests := []struct {
+		name  string
+		owner *common.Address                       // Owner of the masternode before the call
+		quit  bool                                  // Whether to quit instead of registering
+		node  bool                                  // Whether to send from the masternode account
+		emit  func(*types.Transaction) []*types.Log // Logs of the included transaction
+		state string                                // Expected state after the call
+		err   string                                // Expected error, empty for success
+	}{
+		{name: "register", emit: join, state: MasternodeRegistered},
+		{name: "register pending", emit: func(*types.Transaction) []*types.Log { return nil }, state: MasternodePending},
+		{name: "register rejected", emit: func(*types.Transaction) []*types.Log { return []*types.Log{} }, err: errMasternodeTxFailed.Error()},
+		{name: "register other node", emit: func(tx *types.Transaction) []*types.Log {
+			return []*types.Log{membershipLog(masternode.JoinEvent, other.X8(), testAddr, tx)}
+		}, err: errMasternodeTxFailed.Error()},
+		{name: "register twice", owner: &testAddr, emit: join, err: errMasternodeRegistered.Error()},
+		{name: "quit", owner: &testAddr, quit: true, emit: quit, state: MasternodeUnregistered},
+		{name: "quit unregistered", quit: true, emit: quit, err: errMasternodeUnregistered.Error()},
+		{name: "quit foreign", owner: &stranger, quit: true, emit: quit, err: "masternode was registered by"},
+		{name: "register from node", node: true, emit: join, err: errMasternodeOwnerIsNode.Error()},
+		{name: "quit from node", owner: &active.NodeAccount, node: true, quit: true, emit: quit, err: errMasternodeOwnerIsNode.Error()},
+	}
+	for _, tt := range tests {
+		chain := newTestBackend(t, 0, nil)
+		chain.am = accounts.NewManager(ks)
+
+		backend := &masternodeBackend{
+			testBackend: chain,
+			active:      active,
+			emit:        tt.emit,
+			owner:       tt.owner,
+		}
+		api := NewPrivateMasternodeAPI(backend, new(AddrLocker))
+
+		owner := testAddr
+		if tt.node {
+			owner = active.NodeAccount
+		}
+		var status *MasternodeStatus
+		if tt.quit {
+			status, err = api.Quit(context.Background(), owner, "pass")
+		} else {
+			status, err = api.Register(context.Background(), owner, "pass")
+		}
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: error mismatch: have %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: failed to send transaction: %v", tt.name, err)
+			continue
+		}
+		if len(backend.sent) != 1 {
+			t.Errorf("%s: sent transactions mismatch: have %d, want 1", tt.name, len(backend.sent))
+			continue
+		}
+		tx := backend.sent[0]
+		if status.State != tt.state || status.TxHash == nil || *status.TxHash != tx.Hash() || status.ID != active.ID {
+			t.Errorf("%s: status mismatch: have %+v", tt.name, status)
+		}
+		// Registrations deposit the stake along with the node key, quits are plain transfers
+		from, _ := types.Sender(types.NewEIP155Signer(params.TestChainConfig.ChainID), tx)
+		if from != testAddr || *tx.To() != params.MasterndeContractAddress {
+			t.Errorf("%s: transaction route mismatch: from %x to %x", tt.name, from, tx.To())
+		}
+		if tt.quit {
+			if tx.Value().Sign() != 0 || len(tx.Data()) != 0 {
+				t.Errorf("%s: quit transaction mismatch: value %v, data %x", tt.name, tx.Value(), tx.Data())
+			}
+		} else {
+			input, _ := masternode.RegisterInput(active.NodeID)
+			if tx.Value().Cmp(masternode.DepositAmount) != 0 || !bytes.Equal(tx.Data(), input) {
+				t.Errorf("%s: register transaction mismatch: value %v, data %x", tt.name, tx.Value(), tx.Data())
+			}
+		}
+	}
+}
+
+// Tests that double sign evidence is only reported to masternode contracts which
+// dispatch the report method, and only if the contract accepts the report.
+func TestReportDoubleSign(t *testing.T) {
+	dir, err := ioutil.TempDir("", "masternode-api-test")
+	if err != nil {
+		t.Fatalf("failed to create keystore dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	if _, err := ks.ImportECDSA(testKey, "pass"); err != nil {
+		t.Fatalf("failed to import owner key: %v", err)
+	}
+	var (
+		witness  = "0123456789abcdef"
+		first    = &types.Header{Number: common.Big1, Time: big.NewInt(10), Difficulty: common.Big1, Witness: witness}
+		second   = &types.Header{Number: common.Big1, Time: big.NewInt(10), Difficulty: common.Big1, Witness: witness, Extra: []byte{0x01}}
+		evidence = &devote.DoubleSignEvidence{Witness: witness, Slot: 10, First: first, Second: second}
+
+		// Contracts pushing the selector of the report method, accepting and rejecting it
+		accepting = common.FromHex("0x63c83b0f7f00")
+		rejecting = common.FromHex("0x63c83b0f7f60006000fd")
+	)
+	tests := []struct {
+		name string
+		code []byte         // Code of the masternode contract
+		slot hexutil.Uint64 // Slot of the reported evidence
+		err  string         // Expected error, empty for success
+	}{
+		{name: "report", code: accepting, slot: 10},
+		{name: "unknown evidence", code: accepting, slot: 11, err: errUnknownEvidence.Error()},
+		{name: "unsupported contract", code: common.FromHex("0x6000600055"), slot: 10, err: errDoubleSignUnsupported.Error()},
+		{name: "rejected report", code: rejecting, slot: 10, err: "masternode contract rejects the double sign report"},
+	}
+	for _, tt := range tests {
+		chain := newTestBackendWithAlloc(t, core.GenesisAlloc{params.MasterndeContractAddress: {Code: tt.code, Balance: common.Big0}}, 0, nil)
+		chain.am = accounts.NewManager(ks)
+
+		backend := &masternodeBackend{
+			testBackend: chain,
+			emit:        func(*types.Transaction) []*types.Log { return nil },
+			evidence:    []*devote.DoubleSignEvidence{evidence},
+		}
+		api := NewPrivateMasternodeAPI(backend, new(AddrLocker))
+
+		hash, err := api.ReportDoubleSign(context.Background(), testAddr, witness, tt.slot, "pass")
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: error mismatch: have %v, want %q", tt.name, err, tt.err)
+			}
+			if len(backend.sent) != 0 {
+				t.Errorf("%s: rejected report sent", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: failed to report: %v", tt.name, err)
+			continue
+		}
+		if len(backend.sent) != 1 || backend.sent[0].Hash() != hash {
+			t.Errorf("%s: sent transactions mismatch: have %d, want 1 with hash %x", tt.name, len(backend.sent), hash)
+			continue
+		}
+		tx := backend.sent[0]
+		id := [8]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+		input, _ := masternode.ReportDoubleSignInput(id, first, second)
+		if *tx.To() != params.MasterndeContractAddress || tx.Value().Sign() != 0 || !bytes.Equal(tx.Data(), input) {
+			t.Errorf("%s: report transaction mismatch: to %x, value %v, data %x", tt.name, tx.To(), tx.Value(), tx.Data())
+		}
+	}
+}
@@ -23,7 +23,10 @@ var Modules = map[string]string{
 	"clique":     Clique_JS,
 	"ethash":     Ethash_JS,
 	"debug":      Debug_JS,
+	"devote":     Devote_JS,
 	"eth":        Eth_JS,
+	"governance": Governance_JS,
+	"masternode": Masternode_JS,
 	"miner":      Miner_JS,
 	"net":        Net_JS,
 	"personal":   Personal_JS,
@@ -110,6 +113,36 @@ web3._extend({
 });
 `
 
+const Devote_JS = `
+web3._extend({
+	property: 'devote',
+	methods: [
+		new web3._extend.Method({
+			name: 'getWitnesses',
+			call: 'devote_getWitnesses',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getCycle',
+			call: 'devote_getCycle',
+			params: 1,
+			inputFormatter: [null]
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'confirmedBlockNumber',
+			getter: 'devote_getConfirmedBlockNumber'
+		}),
+		new web3._extend.Property({
+			name: 'doubleSignEvidence',
+			getter: 'devote_getDoubleSignEvidence'
+		}),
+	]
+});
+`
+
 const Ethash_JS = `
 web3._extend({
 	property: 'ethash',
@@ -402,6 +435,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceCall',
+			call: 'debug_traceCall',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter, null]
+		}),
 		new web3._extend.Method({
 			name: 'preimage',
 			call: 'debug_preimage',
@@ -473,6 +512,12 @@ web3._extend({
 			call: 'eth_getRawTransactionByHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getPowerForecast',
+			call: 'eth_getPowerForecast',
+			params: 4,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransactionFromBlock',
 			call: function(args) {
@@ -487,6 +532,18 @@ web3._extend({
 			params: 3,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getMasternodes',
+			call: 'eth_masternodes',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getMasternodeInfo',
+			call: 'eth_getInfo',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -505,6 +562,121 @@ web3._extend({
 });
 `
 
+const Governance_JS = `
+web3._extend({
+	property: 'governance',
+	methods: [
+		new web3._extend.Method({
+			name: 'getAddress',
+			call: 'governance_address',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getCommunityAddress',
+			call: 'governance_communityAddress',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getProposals',
+			call: 'governance_proposals',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getProposal',
+			call: 'governance_proposal',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'hasVoted',
+			call: 'governance_hasVoted',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'propose',
+			call: 'governance_propose',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'vote',
+			call: 'governance_vote',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputAddressFormatter, null]
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'address',
+			getter: 'governance_address'
+		}),
+		new web3._extend.Property({
+			name: 'proposals',
+			getter: 'governance_proposals'
+		}),
+	]
+});
+`
+
+const Masternode_JS = `
+web3._extend({
+	property: 'masternode',
+	methods: [
+		new web3._extend.Method({
+			name: 'getInfo',
+			call: 'masternode_getInfo',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'startMasternode',
+			call: 'masternode_startMasternode',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'stopMasternode',
+			call: 'masternode_stopMasternode',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'register',
+			call: 'masternode_register',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'quit',
+			call: 'masternode_quit',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'reportDoubleSign',
+			call: 'masternode_reportDoubleSign',
+			params: 4,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.utils.fromDecimal, null]
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'list',
+			getter: 'masternode_list'
+		}),
+		new web3._extend.Property({
+			name: 'data',
+			getter: 'masternode_data'
+		}),
+		new web3._extend.Property({
+			name: 'status',
+			getter: 'masternode_status'
+		}),
+	]
+});
+`
+
 const Miner_JS = `
 web3._extend({
 	property: 'miner',
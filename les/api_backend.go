@@ -18,16 +18,19 @@ package les
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
 	"github.com/etherzero/go-etherzero/accounts"
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/common/math"
+	"github.com/etherzero/go-etherzero/consensus/devote"
 	"github.com/etherzero/go-etherzero/core"
 	"github.com/etherzero/go-etherzero/core/bloombits"
 	"github.com/etherzero/go-etherzero/core/rawdb"
 	"github.com/etherzero/go-etherzero/core/state"
 	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/masternode"
 	"github.com/etherzero/go-etherzero/core/vm"
 	"github.com/etherzero/go-etherzero/eth/downloader"
 	"github.com/etherzero/go-etherzero/eth/gasprice"
@@ -38,6 +41,10 @@ import (
 	"github.com/etherzero/go-etherzero/rpc"
 )
 
+// errNotSupported is returned for masternode operations, which need the full
+// chain state and a running masternode manager.
+var errNotSupported = errors.New("not supported in light client mode")
+
 type LesApiBackend struct {
 	eth *LightEthereum
 	gpo *gasprice.Oracle
@@ -60,6 +67,9 @@ func (b *LesApiBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNum
 	if blockNr == rpc.LatestBlockNumber || blockNr == rpc.PendingBlockNumber {
 		return b.eth.blockchain.CurrentHeader(), nil
 	}
+	if blockNr == rpc.FinalizedBlockNumber {
+		return b.eth.blockchain.CurrentFinalizedHeader(), nil
+	}
 	return b.eth.blockchain.GetHeaderByNumberOdr(ctx, uint64(blockNr))
 }
 
@@ -139,6 +149,10 @@ func (b *LesApiBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.eth.txPool.Content()
 }
 
+func (b *LesApiBackend) TxPoolWaiting() map[common.Hash]uint64 {
+	return nil
+}
+
 func (b *LesApiBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.txPool.SubscribeNewTxsEvent(ch)
 }
@@ -147,6 +161,10 @@ func (b *LesApiBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Sub
 	return b.eth.blockchain.SubscribeChainEvent(ch)
 }
 
+func (b *LesApiBackend) SubscribeChainFinalizedEvent(ch chan<- core.ChainFinalizedEvent) event.Subscription {
+	return b.eth.blockchain.SubscribeChainFinalizedEvent(ch)
+}
+
 func (b *LesApiBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
 	return b.eth.blockchain.SubscribeChainHeadEvent(ch)
 }
@@ -203,14 +221,13 @@ func (b *LesApiBackend) ServiceFilter(ctx context.Context, session *bloombits.Ma
 
 
 // Masternodes return masternode info
-// TODO LesApiBackend interface does not implemente this api for now(2018-05-30)
-func (b *LesApiBackend) Masternodes() []string {
-	return nil
+func (b *LesApiBackend) Masternodes(ctx context.Context, blockNr rpc.BlockNumber) ([]*masternode.Masternode, error) {
+	return nil, errNotSupported
 }
 
 // Data return masternode contract data
-func (b *LesApiBackend) Data() string {
-	return ""
+func (b *LesApiBackend) Data() (string, error) {
+	return "", nil
 }
 
 
@@ -219,8 +236,8 @@ func (b *LesApiBackend) Ns() int64 {
 }
 
 // GetInfo return related info in masternode contract
-func (b *LesApiBackend) GetInfo(nodeid string) string {
-	return ""
+func (b *LesApiBackend) GetInfo(ctx context.Context, nodeid string, blockNr rpc.BlockNumber) (*masternode.Masternode, error) {
+	return nil, errNotSupported
 }
 
 // GetEnode return related Enodeinfo in enodeinfo contract
@@ -237,14 +254,44 @@ func (b *LesApiBackend) EnodeCount() (ret uint64) {
 
 
 // Start the masternode insfo
-func (s *LesApiBackend) StartMasternode() bool {
-	return false
+func (s *LesApiBackend) StartMasternode() error {
+	return errNotSupported
 }
 
 // Stop the masternode insfo
-func (s *LesApiBackend) StopMasternode() bool {
+func (s *LesApiBackend) StopMasternode() error {
+	return errNotSupported
+}
+
+// ActiveMasternode is not supported by light clients
+func (s *LesApiBackend) ActiveMasternode() (*masternode.ActiveMasternode, error) {
+	return nil, errNotSupported
+}
+
+// Governance is not supported by light clients
+func (s *LesApiBackend) Governance(ctx context.Context, blockNr rpc.BlockNumber) (*masternode.Governance, error) {
+	return nil, errNotSupported
+}
+
+// GovernanceVoted is not supported by light clients
+func (s *LesApiBackend) GovernanceVoted(ctx context.Context, proposal, voter common.Address, blockNr rpc.BlockNumber) (bool, error) {
+	return false, errNotSupported
+}
+
+// MasternodePinging is always false for light clients
+func (s *LesApiBackend) MasternodePinging() bool {
 	return false
 }
 
+// MasternodePingState is not available on light clients
+func (s *LesApiBackend) MasternodePingState() *masternode.PingState {
+	return nil
+}
+
+// DoubleSignEvidence is not collected by light clients
+func (s *LesApiBackend) DoubleSignEvidence() ([]*devote.DoubleSignEvidence, error) {
+	return nil, errNotSupported
+}
+
 
 
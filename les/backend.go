@@ -155,6 +155,8 @@ func lesTopic(genesisHash common.Hash, protocolVersion uint) discv5.Topic {
 		name = "LES"
 	case lpv2:
 		name = "LES2"
+	case lpv3:
+		name = "LES3"
 	default:
 		panic(nil)
 	}
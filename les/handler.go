@@ -33,6 +33,7 @@ import (
 	"github.com/etherzero/go-etherzero/core/rawdb"
 	"github.com/etherzero/go-etherzero/core/state"
 	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/devotedb"
 	"github.com/etherzero/go-etherzero/eth/downloader"
 	"github.com/etherzero/go-etherzero/ethdb"
 	"github.com/etherzero/go-etherzero/event"
@@ -324,7 +325,7 @@ func (pm *ProtocolManager) handle(p *peer) error {
 	}
 }
 
-var reqList = []uint64{GetBlockHeadersMsg, GetBlockBodiesMsg, GetCodeMsg, GetReceiptsMsg, GetProofsV1Msg, SendTxMsg, SendTxV2Msg, GetTxStatusMsg, GetHeaderProofsMsg, GetProofsV2Msg, GetHelperTrieProofsMsg}
+var reqList = []uint64{GetBlockHeadersMsg, GetBlockBodiesMsg, GetCodeMsg, GetReceiptsMsg, GetProofsV1Msg, SendTxMsg, SendTxV2Msg, GetTxStatusMsg, GetHeaderProofsMsg, GetProofsV2Msg, GetHelperTrieProofsMsg, GetDevoteProofsMsg}
 
 // handleMsg is invoked whenever an inbound message is received from a remote
 // peer. The remote connection is torn down upon returning any error.
@@ -861,6 +862,84 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			Obj:     resp.Data,
 		}
 
+	case GetDevoteProofsMsg:
+		p.Log().Trace("Received devote proofs request")
+		// Decode the retrieval message
+		var req struct {
+			ReqID uint64
+			Reqs  []DevoteProofReq
+		}
+		if err := msg.Decode(&req); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		// Gather devote trie data until the fetch or network limits is reached
+		reqCnt := len(req.Reqs)
+		if reject(uint64(reqCnt), MaxProofsFetch) {
+			return errResp(ErrRequestRejected, "")
+		}
+		nodes := light.NewNodeSet()
+
+		// Recent devote tries are only held in the memory of the full chain
+		devoteCache := devotedb.NewDatabase(pm.chainDb)
+		if chain, ok := pm.blockchain.(*core.BlockChain); ok {
+			devoteCache = chain.DevoteCache()
+		}
+		for _, req := range req.Reqs {
+			// Look up the devote protocol belonging to the request
+			number := rawdb.ReadHeaderNumber(pm.chainDb, req.BHash)
+			if number == nil {
+				continue
+			}
+			header := rawdb.ReadHeader(pm.chainDb, req.BHash, *number)
+			if header == nil || header.Protocol == nil {
+				continue
+			}
+			devoteDB, err := devotedb.NewDevoteByProtocol(devoteCache, header.Protocol)
+			if err != nil {
+				continue
+			}
+			// Pull the cycle or stats trie of the request
+			var trie devotedb.Trie
+			switch req.Trie {
+			case light.DevoteCycleTrie:
+				trie = devoteDB.StorageCycleTrie(header.Protocol.CycleHash)
+			case light.DevoteStatsTrie:
+				trie = devoteDB.StorageStatsTrie(header.Protocol.StatsHash)
+			}
+			if trie == nil {
+				continue
+			}
+			// Prove the user's request from the devote trie
+			trie.Prove(req.Key, req.FromLevel, nodes)
+			if nodes.DataSize() >= softResponseLimit {
+				break
+			}
+		}
+		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
+		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
+		return p.SendDevoteProofs(req.ReqID, bv, nodes.NodeList())
+
+	case DevoteProofsMsg:
+		if pm.odr == nil {
+			return errResp(ErrUnexpectedResponse, "")
+		}
+
+		p.Log().Trace("Received devote proofs response")
+		// A batch of devote trie proofs arrived to one of our previous requests
+		var resp struct {
+			ReqID, BV uint64
+			Data      light.NodeList
+		}
+		if err := msg.Decode(&resp); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		p.fcServer.GotReply(resp.ReqID, resp.BV)
+		deliverMsg = &Msg{
+			MsgType: MsgDevoteProofs,
+			ReqID:   resp.ReqID,
+			Obj:     resp.Data,
+		}
+
 	case GetHeaderProofsMsg:
 		p.Log().Trace("Received headers proof request")
 		// Decode the retrieval message
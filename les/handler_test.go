@@ -17,17 +17,21 @@
 package les
 
 import (
+	"context"
 	"encoding/binary"
 	"math/big"
 	"math/rand"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/consensus/devote"
 	"github.com/etherzero/go-etherzero/consensus/ethash"
 	"github.com/etherzero/go-etherzero/core"
 	"github.com/etherzero/go-etherzero/core/rawdb"
 	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/devotedb"
 	"github.com/etherzero/go-etherzero/crypto"
 	"github.com/etherzero/go-etherzero/eth/downloader"
 	"github.com/etherzero/go-etherzero/ethdb"
@@ -581,3 +585,83 @@ func TestTransactionStatusLes2(t *testing.T) {
 	test(tx1, false, txStatus{Status: core.TxStatusPending})
 	test(tx2, false, txStatus{Status: core.TxStatusPending})
 }
+
+// Tests that a light client verifies the seals of devote headers against the
+// witness lists proven by a LES/3 server's protocol handler.
+func TestDevoteProofsLes3(t *testing.T) {
+	witnesses := []string{"0123456789abcdef", "fedcba9876543210"}
+
+	config := *params.AllEthashProtocolChanges
+	config.Ethash = nil
+	config.Devote = &params.DevoteConfig{Witnesses: witnesses}
+	gspec := &core.Genesis{Config: &config, Difficulty: big.NewInt(1)}
+
+	db, ldb := ethdb.NewMemDatabase(), ethdb.NewMemDatabase()
+	peers, lPeers := newPeerSet(), newPeerSet()
+
+	dist := newRequestDistributor(lPeers, make(chan struct{}))
+	rm := newRetrieveManager(lPeers, dist, nil)
+	odr := NewLesOdr(ldb, light.TestClientIndexerConfig, rm)
+
+	// Cross into a new cycle on the server, so the witnesses of the later headers
+	// are unknown to the client
+	generator := func(i int, block *core.BlockGen) {
+		if i == 4 {
+			block.OffsetTime(int64(params.CycleInterval))
+		}
+	}
+	pm, err := newTestProtocolManagerWithEngine(false, 8, generator, nil, peers, db, gspec, devote.NewFaker(witnesses, db))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	lpm, err := newTestProtocolManagerWithEngine(true, 0, nil, odr, lPeers, ldb, gspec, devote.NewFaker(witnesses, ldb))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err1, lPeer, err2 := newTestPeerPair("peer", lpv3, pm, lpm)
+	select {
+	case <-time.After(100 * time.Millisecond):
+	case err := <-err1:
+		t.Fatalf("server handshake error: %v", err)
+	case err := <-err2:
+		t.Fatalf("client handshake error: %v", err)
+	}
+	if !lPeer.serveDevote {
+		t.Fatalf("server not serving devote proofs")
+	}
+	bc := pm.blockchain.(*core.BlockChain)
+	headers := make([]*types.Header, bc.CurrentBlock().NumberU64())
+	for i := range headers {
+		headers[i] = bc.GetHeaderByNumber(uint64(i + 1))
+	}
+	last := headers[len(headers)-1]
+
+	lc := lpm.blockchain.(*light.LightChain)
+	if _, err := lc.InsertHeaderChain(headers, 1); err != nil {
+		t.Fatalf("failed to insert headers: %v", err)
+	}
+	if head := lc.CurrentHeader(); head.Hash() != last.Hash() {
+		t.Fatalf("head mismatch: have #%d, want #%d", head.Number, last.Number)
+	}
+	// The witnesses of the new cycle must have been proven by the server
+	cycle := last.Time.Uint64() / params.CycleInterval
+	have, err := light.GetDevoteWitnesses(context.Background(), odr, last, cycle)
+	if err != nil {
+		t.Fatalf("failed to retrieve witnesses: %v", err)
+	}
+	devoteDB, _ := devotedb.NewDevoteByProtocol(devotedb.NewDatabase(db), last.Protocol)
+	if want, _ := devoteDB.GetWitnesses(cycle); !reflect.DeepEqual(have, want) {
+		t.Errorf("witnesses mismatch: have %v, want %v", have, want)
+	}
+	// A header sealed by a witness not scheduled for its slot must be rejected
+	forged := types.CopyHeader(last)
+	for _, witness := range witnesses {
+		if witness != last.Witness {
+			forged.Witness = witness
+		}
+	}
+	if _, err := lc.InsertHeaderChain([]*types.Header{forged}, 1); err == nil {
+		t.Fatalf("header of unscheduled witness accepted")
+	}
+}
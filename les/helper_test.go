@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/consensus"
 	"github.com/etherzero/go-etherzero/consensus/ethash"
 	"github.com/etherzero/go-etherzero/core"
 	"github.com/etherzero/go-etherzero/core/types"
@@ -147,13 +148,19 @@ func testRCL() RequestCostList {
 // with the given number of blocks already known, potential notification
 // channels for different events and relative chain indexers array.
 func newTestProtocolManager(lightSync bool, blocks int, generator func(int, *core.BlockGen), odr *LesOdr, peers *peerSet, db ethdb.Database) (*ProtocolManager, error) {
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  core.GenesisAlloc{testBankAddress: {Balance: testBankFunds}},
+	}
+	return newTestProtocolManagerWithEngine(lightSync, blocks, generator, odr, peers, db, gspec, ethash.NewFaker())
+}
+
+// newTestProtocolManagerWithEngine creates a new protocol manager for testing
+// purposes like newTestProtocolManager, on top of the given genesis and sealing
+// the blocks with the given consensus engine.
+func newTestProtocolManagerWithEngine(lightSync bool, blocks int, generator func(int, *core.BlockGen), odr *LesOdr, peers *peerSet, db ethdb.Database, gspec *core.Genesis, engine consensus.Engine) (*ProtocolManager, error) {
 	var (
-		evmux  = new(event.TypeMux)
-		engine = ethash.NewFaker()
-		gspec  = core.Genesis{
-			Config: params.TestChainConfig,
-			Alloc:  core.GenesisAlloc{testBankAddress: {Balance: testBankFunds}},
-		}
+		evmux   = new(event.TypeMux)
 		genesis = gspec.MustCommit(db)
 		chain   BlockChain
 	)
@@ -165,7 +172,7 @@ func newTestProtocolManager(lightSync bool, blocks int, generator func(int, *cor
 		chain, _ = light.NewLightChain(odr, gspec.Config, engine)
 	} else {
 		blockchain, _ := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil)
-		gchain, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, blocks, generator)
+		gchain, _ := core.GenerateChain(gspec.Config, genesis, engine, db, blocks, generator)
 		if _, err := blockchain.InsertChain(gchain); err != nil {
 			panic(err)
 		}
@@ -302,6 +309,9 @@ func (p *testPeer) handshake(t *testing.T, td *big.Int, head common.Hash, headNu
 	expList = expList.add("serveChainSince", uint64(0))
 	expList = expList.add("serveStateSince", uint64(0))
 	expList = expList.add("txRelay", nil)
+	if p.version >= lpv3 {
+		expList = expList.add("serveDevote", nil)
+	}
 	expList = expList.add("flowControl/BL", testBufLimit)
 	expList = expList.add("flowControl/MRR", uint64(1))
 	expList = expList.add("flowControl/MRC", testRCL())
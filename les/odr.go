@@ -18,8 +18,11 @@ package les
 
 import (
 	"context"
+	"math/big"
+	"sync"
 
 	"github.com/etherzero/go-etherzero/core"
+	"github.com/etherzero/go-etherzero/core/state"
 	"github.com/etherzero/go-etherzero/ethdb"
 	"github.com/etherzero/go-etherzero/light"
 	"github.com/etherzero/go-etherzero/log"
@@ -32,6 +35,10 @@ type LesOdr struct {
 	chtIndexer, bloomTrieIndexer, bloomIndexer *core.ChainIndexer
 	retriever                                  *retrieveManager
 	stop                                       chan struct{}
+
+	mu              sync.Mutex
+	fixedPowerBlock *big.Int // Fixed-point power fork block of the stored chain config
+	configLoaded    bool     // Whether the chain config was found in the database
 }
 
 func NewLesOdr(db ethdb.Database, config *light.IndexerConfig, retriever *retrieveManager) *LesOdr {
@@ -53,6 +60,19 @@ func (odr *LesOdr) Database() ethdb.Database {
 	return odr.db
 }
 
+// FixedPowerBlock retrieves the fixed-point power fork block from the chain config
+// stored in the database. It is cached once the config is found, the genesis may
+// not be written yet when the backend is created.
+func (odr *LesOdr) FixedPowerBlock() *big.Int {
+	odr.mu.Lock()
+	defer odr.mu.Unlock()
+
+	if !odr.configLoaded {
+		odr.fixedPowerBlock, odr.configLoaded = state.ReadFixedPowerBlock(odr.db)
+	}
+	return odr.fixedPowerBlock
+}
+
 // SetIndexers adds the necessary chain indexers to the ODR backend
 func (odr *LesOdr) SetIndexers(chtIndexer, bloomTrieIndexer, bloomIndexer *core.ChainIndexer) {
 	odr.chtIndexer = chtIndexer
@@ -88,6 +108,7 @@ const (
 	MsgProofsV2
 	MsgHeaderProofs
 	MsgHelperTrieProofs
+	MsgDevoteProofs
 )
 
 // Msg encodes a LES message that delivers reply data for a request
@@ -68,6 +68,8 @@ func LesRequest(req light.OdrRequest) LesOdrRequest {
 		return (*ChtRequest)(r)
 	case *light.BloomRequest:
 		return (*BloomRequest)(r)
+	case *light.DevoteRequest:
+		return (*DevoteRequest)(r)
 	default:
 		return nil
 	}
@@ -193,7 +195,7 @@ func (r *TrieRequest) GetCost(peer *peer) uint64 {
 	switch peer.version {
 	case lpv1:
 		return peer.GetRequestCost(GetProofsV1Msg, 1)
-	case lpv2:
+	case lpv2, lpv3:
 		return peer.GetRequestCost(GetProofsV2Msg, 1)
 	default:
 		panic(nil)
@@ -256,6 +258,63 @@ func (r *TrieRequest) Validate(db ethdb.Database, msg *Msg) error {
 	}
 }
 
+type DevoteProofReq struct {
+	BHash     common.Hash
+	Trie      uint
+	Key       []byte
+	FromLevel uint
+}
+
+// ODR request type for devote cycle/stats trie entries, see LesOdrRequest interface
+type DevoteRequest light.DevoteRequest
+
+// GetCost returns the cost of the given ODR request according to the serving
+// peer's cost table (implementation of LesOdrRequest)
+func (r *DevoteRequest) GetCost(peer *peer) uint64 {
+	return peer.GetRequestCost(GetDevoteProofsMsg, 1)
+}
+
+// CanSend tells if a certain peer is suitable for serving the given request
+func (r *DevoteRequest) CanSend(peer *peer) bool {
+	return peer.serveDevote && peer.HasBlock(r.BlockHash, r.BlockNumber, false)
+}
+
+// Request sends an ODR request to the LES network (implementation of LesOdrRequest)
+func (r *DevoteRequest) Request(reqID uint64, peer *peer) error {
+	peer.Log().Debug("Requesting devote proof", "root", r.Root, "key", r.Key)
+	req := DevoteProofReq{
+		BHash: r.BlockHash,
+		Trie:  r.Trie,
+		Key:   r.Key,
+	}
+	return peer.RequestDevoteProofs(reqID, r.GetCost(peer), []DevoteProofReq{req})
+}
+
+// Valid processes an ODR request reply message from the LES network
+// returns true and stores results in memory if the message was a valid reply
+// to the request (implementation of LesOdrRequest)
+func (r *DevoteRequest) Validate(db ethdb.Database, msg *Msg) error {
+	log.Debug("Validating devote proof", "root", r.Root, "key", r.Key)
+
+	// Ensure we have a correct message with a single proof
+	if msg.MsgType != MsgDevoteProofs {
+		return errInvalidMessageType
+	}
+	proofs := msg.Obj.(light.NodeList)
+	// Verify the proof and store if checks out
+	nodeSet := proofs.NodeSet()
+	reads := &readTraceDB{db: nodeSet}
+	if _, _, err := trie.VerifyProof(r.Root, r.Key, reads); err != nil {
+		return fmt.Errorf("merkle proof verification failed: %v", err)
+	}
+	// check if all nodes have been read by VerifyProof
+	if len(reads.reads) != nodeSet.KeyCount() {
+		return errUselessNodes
+	}
+	r.Proof = nodeSet
+	return nil
+}
+
 type CodeReq struct {
 	BHash  common.Hash
 	AccKey []byte
@@ -353,7 +412,7 @@ func (r *ChtRequest) GetCost(peer *peer) uint64 {
 	switch peer.version {
 	case lpv1:
 		return peer.GetRequestCost(GetHeaderProofsMsg, 1)
-	case lpv2:
+	case lpv2, lpv3:
 		return peer.GetRequestCost(GetHelperTrieProofsMsg, 1)
 	default:
 		panic(nil)
@@ -389,7 +448,7 @@ func (r *ChtRequest) Request(reqID uint64, peer *peer) error {
 		// convert HelperTrie request to old CHT request
 		reqsV1 = ChtReq{ChtNum: (req.TrieIdx + 1) * (r.Config.ChtSize / r.Config.PairChtSize), BlockNum: blockNum, FromLevel: req.FromLevel}
 		return peer.RequestHelperTrieProofs(reqID, r.GetCost(peer), []ChtReq{reqsV1})
-	case lpv2:
+	case lpv2, lpv3:
 		return peer.RequestHelperTrieProofs(reqID, r.GetCost(peer), []HelperTrieReq{req})
 	default:
 		panic(nil)
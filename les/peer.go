@@ -58,6 +58,8 @@ type peer struct {
 
 	announceType, requestAnnounceType uint64
 
+	serveDevote bool // Whether the peer serves devote trie proofs
+
 	id string
 
 	headInfo *announceData
@@ -217,6 +219,11 @@ func (p *peer) SendProofsV2(reqID, bv uint64, proofs light.NodeList) error {
 	return sendResponse(p.rw, ProofsV2Msg, reqID, bv, proofs)
 }
 
+// SendDevoteProofs sends a batch of devote trie merkle proofs, corresponding to the ones requested.
+func (p *peer) SendDevoteProofs(reqID, bv uint64, proofs light.NodeList) error {
+	return sendResponse(p.rw, DevoteProofsMsg, reqID, bv, proofs)
+}
+
 // SendHeaderProofs sends a batch of legacy LES/1 header proofs, corresponding to the ones requested.
 func (p *peer) SendHeaderProofs(reqID, bv uint64, proofs []ChtResp) error {
 	return sendResponse(p.rw, HeaderProofsMsg, reqID, bv, proofs)
@@ -272,13 +279,19 @@ func (p *peer) RequestProofs(reqID, cost uint64, reqs []ProofReq) error {
 	switch p.version {
 	case lpv1:
 		return sendRequest(p.rw, GetProofsV1Msg, reqID, cost, reqs)
-	case lpv2:
+	case lpv2, lpv3:
 		return sendRequest(p.rw, GetProofsV2Msg, reqID, cost, reqs)
 	default:
 		panic(nil)
 	}
 }
 
+// RequestDevoteProofs fetches a batch of devote trie merkle proofs from a remote node.
+func (p *peer) RequestDevoteProofs(reqID, cost uint64, reqs []DevoteProofReq) error {
+	p.Log().Debug("Fetching batch of devote proofs", "count", len(reqs))
+	return sendRequest(p.rw, GetDevoteProofsMsg, reqID, cost, reqs)
+}
+
 // RequestHelperTrieProofs fetches a batch of HelperTrie merkle proofs from a remote node.
 func (p *peer) RequestHelperTrieProofs(reqID, cost uint64, data interface{}) error {
 	switch p.version {
@@ -289,7 +302,7 @@ func (p *peer) RequestHelperTrieProofs(reqID, cost uint64, data interface{}) err
 		}
 		p.Log().Debug("Fetching batch of header proofs", "count", len(reqs))
 		return sendRequest(p.rw, GetHeaderProofsMsg, reqID, cost, reqs)
-	case lpv2:
+	case lpv2, lpv3:
 		reqs, ok := data.([]HelperTrieReq)
 		if !ok {
 			return errInvalidHelpTrieReq
@@ -313,7 +326,7 @@ func (p *peer) SendTxs(reqID, cost uint64, txs types.Transactions) error {
 	switch p.version {
 	case lpv1:
 		return p2p.Send(p.rw, SendTxMsg, txs) // old message format does not include reqID
-	case lpv2:
+	case lpv2, lpv3:
 		return sendRequest(p.rw, SendTxV2Msg, reqID, cost, txs)
 	default:
 		panic(nil)
@@ -405,6 +418,9 @@ func (p *peer) Handshake(td *big.Int, head common.Hash, headNum uint64, genesis
 		send = send.add("serveChainSince", uint64(0))
 		send = send.add("serveStateSince", uint64(0))
 		send = send.add("txRelay", nil)
+		if p.version >= lpv3 {
+			send = send.add("serveDevote", nil)
+		}
 		send = send.add("flowControl/BL", server.defParams.BufLimit)
 		send = send.add("flowControl/MRR", server.defParams.MinRecharge)
 		list := server.fcCostStats.getCurrentList()
@@ -471,6 +487,7 @@ func (p *peer) Handshake(td *big.Int, head common.Hash, headNum uint64, genesis
 		if recv.get("txRelay", nil) != nil {
 			return errResp(ErrUselessPeer, "peer cannot relay transactions")
 		}
+		p.serveDevote = p.version >= lpv3 && recv.get("serveDevote", nil) == nil
 		params := &flowcontrol.ServerParams{}
 		if err := recv.get("flowControl/BL", &params.BufLimit); err != nil {
 			return err
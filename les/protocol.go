@@ -36,17 +36,18 @@ import (
 const (
 	lpv1 = 1
 	lpv2 = 2
+	lpv3 = 3
 )
 
 // Supported versions of the les protocol (first is primary)
 var (
-	ClientProtocolVersions    = []uint{lpv2, lpv1}
-	ServerProtocolVersions    = []uint{lpv2, lpv1}
-	AdvertiseProtocolVersions = []uint{lpv2} // clients are searching for the first advertised protocol in the list
+	ClientProtocolVersions    = []uint{lpv3, lpv2, lpv1}
+	ServerProtocolVersions    = []uint{lpv3, lpv2, lpv1}
+	AdvertiseProtocolVersions = []uint{lpv3, lpv2} // clients are searching for the first advertised protocol in the list
 )
 
 // Number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = map[uint]uint64{lpv1: 15, lpv2: 22}
+var ProtocolLengths = map[uint]uint64{lpv1: 15, lpv2: 22, lpv3: 24}
 
 const (
 	NetworkId          = 1
@@ -79,6 +80,9 @@ const (
 	SendTxV2Msg            = 0x13
 	GetTxStatusMsg         = 0x14
 	TxStatusMsg            = 0x15
+	// Protocol messages belonging to LPV3, only served by peers announcing serveDevote
+	GetDevoteProofsMsg = 0x16
+	DevoteProofsMsg    = 0x17
 )
 
 type errCode int
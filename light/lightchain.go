@@ -26,6 +26,7 @@ import (
 
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/consensus"
+	"github.com/etherzero/go-etherzero/consensus/devote"
 	"github.com/etherzero/go-etherzero/core"
 	"github.com/etherzero/go-etherzero/core/rawdb"
 	"github.com/etherzero/go-etherzero/core/state"
@@ -41,6 +42,8 @@ import (
 var (
 	bodyCacheLimit  = 256
 	blockCacheLimit = 256
+
+	witnessRetrievalTimeout = 10 * time.Second // Time allowed to retrieve the witness proofs of a header batch
 )
 
 // LightChain represents a canonical chain that by default only handles block
@@ -54,8 +57,10 @@ type LightChain struct {
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
+	finalizedFeed event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
+	lastFinalized uint64 // Number of the last finalized header announced (protected by chainmu)
 
 	mu      sync.RWMutex
 	chainmu sync.RWMutex
@@ -106,6 +111,9 @@ func NewLightChain(odr OdrBackend, config *params.ChainConfig, engine consensus.
 	if err := bc.loadLastState(); err != nil {
 		return nil, err
 	}
+	if finalized := bc.CurrentFinalizedHeader(); finalized != nil {
+		bc.lastFinalized = finalized.Number.Uint64()
+	}
 	// Check the current state of the block hashes and make sure that we do not have any of the bad blocks in our chain
 	for hash := range core.BadHashes {
 		if header := bc.GetHeaderByHash(hash); header != nil {
@@ -339,6 +347,8 @@ func (self *LightChain) postChainEvents(events []interface{}) {
 			self.chainFeed.Send(ev)
 		case core.ChainSideEvent:
 			self.chainSideFeed.Send(ev)
+		case core.ChainFinalizedEvent:
+			self.finalizedFeed.Send(ev)
 		}
 	}
 }
@@ -370,11 +380,30 @@ func (self *LightChain) InsertHeaderChain(chain []*types.Header, checkFreq int)
 	self.wg.Add(1)
 	defer self.wg.Done()
 
+	// Devote seals are checked against the witnesses scheduled in the parent's
+	// cycle trie, retrieve them before touching the chain
+	var witnesses map[common.Hash][]string
+
+	devoteEngine, isDevote := self.engine.(*devote.Devote)
+	if isDevote {
+		var (
+			i   int
+			err error
+		)
+		if witnesses, i, err = self.retrieveWitnesses(chain); err != nil {
+			return i, err
+		}
+	}
 	var events []interface{}
 	whFunc := func(header *types.Header) error {
 		self.mu.Lock()
 		defer self.mu.Unlock()
 
+		if isDevote {
+			if err := devoteEngine.VerifyScheduledSeal(self.hc, header, witnesses[header.Hash()]); err != nil {
+				return err
+			}
+		}
 		status, err := self.hc.WriteHeader(header)
 
 		switch status {
@@ -382,6 +411,11 @@ func (self *LightChain) InsertHeaderChain(chain []*types.Header, checkFreq int)
 			log.Debug("Inserted new header", "number", header.Number, "hash", header.Hash())
 			events = append(events, core.ChainEvent{Block: types.NewBlockWithHeader(header), Hash: header.Hash()})
 
+			if finalized := self.CurrentFinalizedHeader(); finalized != nil && finalized.Number.Uint64() > self.lastFinalized {
+				self.lastFinalized = finalized.Number.Uint64()
+				events = append(events, core.ChainFinalizedEvent{Header: finalized})
+			}
+
 		case core.SideStatTy:
 			log.Debug("Inserted forked header", "number", header.Number, "hash", header.Hash())
 			events = append(events, core.ChainSideEvent{Block: types.NewBlockWithHeader(header)})
@@ -393,12 +427,53 @@ func (self *LightChain) InsertHeaderChain(chain []*types.Header, checkFreq int)
 	return i, err
 }
 
+// retrieveWitnesses retrieves the devote witness list each header of the chain
+// is sealed against, keyed by header hash. On failure the index of the first
+// header whose witnesses could not be retrieved is returned.
+func (self *LightChain) retrieveWitnesses(chain []*types.Header) (map[common.Hash][]string, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), witnessRetrievalTimeout)
+	defer cancel()
+
+	witnesses := make(map[common.Hash][]string)
+	for i, header := range chain {
+		var parent *types.Header
+		if i > 0 {
+			parent = chain[i-1]
+		} else {
+			parent = self.hc.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+		}
+		if parent == nil {
+			return nil, i, consensus.ErrUnknownAncestor
+		}
+		list, err := GetDevoteWitnesses(ctx, self.odr, parent, parent.Time.Uint64()/params.CycleInterval)
+		if err != nil {
+			return nil, i, err
+		}
+		witnesses[header.Hash()] = list
+	}
+	return witnesses, 0, nil
+}
+
 // CurrentHeader retrieves the current head header of the canonical chain. The
 // header is retrieved from the HeaderChain's internal cache.
 func (self *LightChain) CurrentHeader() *types.Header {
 	return self.hc.CurrentHeader()
 }
 
+// CurrentFinalizedHeader retrieves the last header finalized by the consensus
+// engine, or nil if the engine has no notion of finality.
+func (self *LightChain) CurrentFinalizedHeader() *types.Header {
+	devoteEngine, isDevote := self.engine.(*devote.Devote)
+	if !isDevote {
+		return nil
+	}
+	header := devoteEngine.ConfirmedBlockHeader(self.hc)
+	if header == nil || rawdb.ReadCanonicalHash(self.chainDb, header.Number.Uint64()) != header.Hash() {
+		return nil
+	}
+	return header
+}
+
 // GetTd retrieves a block's total difficulty in the canonical chain from the
 // database by hash and number, caching it if found.
 func (self *LightChain) GetTd(hash common.Hash, number uint64) *big.Int {
@@ -522,6 +597,11 @@ func (self *LightChain) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) e
 	return self.scope.Track(self.chainSideFeed.Subscribe(ch))
 }
 
+// SubscribeChainFinalizedEvent registers a subscription of ChainFinalizedEvent.
+func (self *LightChain) SubscribeChainFinalizedEvent(ch chan<- core.ChainFinalizedEvent) event.Subscription {
+	return self.scope.Track(self.finalizedFeed.Subscribe(ch))
+}
+
 // SubscribeLogsEvent implements the interface of filters.Backend
 // LightChain does not send logs events, so return an empty subscription.
 func (self *LightChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
@@ -18,14 +18,17 @@ package light
 
 import (
 	"context"
+	"encoding/binary"
 	"math/big"
 	"testing"
 
 	"github.com/etherzero/go-etherzero/common"
+	"github.com/etherzero/go-etherzero/consensus/devote"
 	"github.com/etherzero/go-etherzero/consensus/ethash"
 	"github.com/etherzero/go-etherzero/core"
 	"github.com/etherzero/go-etherzero/core/rawdb"
 	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/devotedb"
 	"github.com/etherzero/go-etherzero/ethdb"
 	"github.com/etherzero/go-etherzero/params"
 )
@@ -245,7 +248,10 @@ func TestBrokenHeaderChain(t *testing.T) {
 func makeHeaderChainWithDiff(genesis *types.Block, d []int, seed byte) []*types.Header {
 	var chain []*types.Header
 	for i, difficulty := range d {
+		// Headers without devote roots can't be decoded from the database
+		protocol := genesis.Header().Protocol
 		header := &types.Header{
+			Protocol:    &devotedb.DevoteProtocol{CycleHash: protocol.CycleHash, StatsHash: protocol.StatsHash},
 			Coinbase:    common.Address{seed},
 			Number:      big.NewInt(int64(i + 1)),
 			Difficulty:  big.NewInt(int64(difficulty)),
@@ -352,3 +358,75 @@ func TestReorgBadHeaderHashes(t *testing.T) {
 		t.Errorf("last header hash mismatch: have: %x, want %x", ncm.CurrentHeader().Hash(), headers[2].Hash())
 	}
 }
+
+// Tests that devote headers are only accepted by the light chain if they were
+// sealed by the witness scheduled in their parent's cycle, retrieving the cycle
+// trie proofs on demand.
+func TestDevoteHeaderVerification(t *testing.T) {
+	witnesses := []string{"0123456789abcdef", "fedcba9876543210"}
+
+	config := *params.AllEthashProtocolChanges
+	config.Ethash = nil
+	config.Devote = &params.DevoteConfig{Witnesses: witnesses}
+
+	// Generate a chain crossing into a new cycle on the server, so the witnesses
+	// of the later headers are unknown to the client
+	sdb := ethdb.NewMemDatabase()
+	gspec := core.Genesis{Config: &config, Difficulty: big.NewInt(1)}
+	genesis := gspec.MustCommit(sdb)
+
+	blocks, _ := core.GenerateChain(&config, genesis, devote.NewFaker(witnesses, sdb), sdb, 8, func(i int, b *core.BlockGen) {
+		if i == 4 {
+			b.OffsetTime(int64(params.CycleInterval))
+		}
+	})
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	last := headers[len(headers)-1]
+
+	ldb := ethdb.NewMemDatabase()
+	gspec.MustCommit(ldb)
+	odr := &testOdr{sdb: sdb, ldb: ldb, indexerConfig: TestClientIndexerConfig}
+	lc, err := NewLightChain(odr, &config, devote.NewFaker(witnesses, ldb))
+	if err != nil {
+		t.Fatalf("failed to create light chain: %v", err)
+	}
+	// Without a server the witnesses of the new cycle cannot be verified
+	odr.disable = true
+	if _, err := lc.InsertHeaderChain(headers, 1); err != ErrOdrDisabled {
+		t.Fatalf("offline insertion error mismatch: have %v, want %v", err, ErrOdrDisabled)
+	}
+	odr.disable = false
+
+	if _, err := lc.InsertHeaderChain(headers[:len(headers)-1], 1); err != nil {
+		t.Fatalf("failed to insert header chain: %v", err)
+	}
+	// Headers claiming an unscheduled witness must be rejected
+	forged := types.CopyHeader(last)
+	for _, witness := range witnesses {
+		if witness != last.Witness {
+			forged.Witness = witness
+		}
+	}
+	if _, err := lc.InsertHeaderChain([]*types.Header{forged}, 1); err != devote.ErrInvalidBlockWitness {
+		t.Errorf("forged header error mismatch: have %v, want %v", err, devote.ErrInvalidBlockWitness)
+	}
+	if _, err := lc.InsertHeaderChain([]*types.Header{last}, 1); err != nil {
+		t.Fatalf("failed to insert last header: %v", err)
+	}
+	// The stats of the new cycle must be retrievable on demand too
+	cycle := last.Time.Uint64() / params.CycleInterval
+	have, err := GetDevoteStats(context.Background(), odr, last, cycle, last.Witness)
+	if err != nil {
+		t.Fatalf("failed to retrieve devote stats: %v", err)
+	}
+	devoteDB, _ := devotedb.NewDevoteByProtocol(devotedb.NewDatabase(sdb), last.Protocol)
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, cycle)
+	key = append(key, []byte(last.Witness)...)
+	if want := devoteDB.GetStatsNumber(key); have != want || have == 0 {
+		t.Errorf("devote stats mismatch: have %d, want %d", have, want)
+	}
+}
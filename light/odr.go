@@ -45,6 +45,7 @@ type OdrBackend interface {
 	BloomIndexer() *core.ChainIndexer
 	Retrieve(ctx context.Context, req OdrRequest) error
 	IndexerConfig() *IndexerConfig
+	FixedPowerBlock() *big.Int
 }
 
 // OdrRequest is an interface for retrieval requests
@@ -95,6 +96,28 @@ func (req *TrieRequest) StoreResult(db ethdb.Database) {
 	req.Proof.Store(db)
 }
 
+// Devote tries of a block header that can be retrieved by a DevoteRequest
+const (
+	DevoteCycleTrie = iota // Witness list of each cycle
+	DevoteStatsTrie        // Number of blocks sealed by each witness in a cycle
+)
+
+// DevoteRequest is the ODR request type for devote cycle/stats trie entries
+type DevoteRequest struct {
+	OdrRequest
+	BlockHash   common.Hash
+	BlockNumber uint64
+	Trie        uint        // DevoteCycleTrie or DevoteStatsTrie
+	Root        common.Hash // Root of the requested trie in the header's devote protocol
+	Key         []byte
+	Proof       *NodeSet
+}
+
+// StoreResult stores the retrieved data in local database
+func (req *DevoteRequest) StoreResult(db ethdb.Database) {
+	req.Proof.Store(db)
+}
+
 // CodeRequest is the ODR request type for retrieving contract code
 type CodeRequest struct {
 	OdrRequest
@@ -64,6 +64,11 @@ func (odr *testOdr) Database() ethdb.Database {
 	return odr.ldb
 }
 
+func (odr *testOdr) FixedPowerBlock() *big.Int {
+	number, _ := state.ReadFixedPowerBlock(odr.ldb)
+	return number
+}
+
 var ErrOdrDisabled = errors.New("ODR disabled")
 
 func (odr *testOdr) Retrieve(ctx context.Context, req OdrRequest) error {
@@ -88,6 +93,11 @@ func (odr *testOdr) Retrieve(ctx context.Context, req OdrRequest) error {
 		req.Proof = nodes
 	case *CodeRequest:
 		req.Data, _ = odr.sdb.Get(req.Hash[:])
+	case *DevoteRequest:
+		t, _ := trie.New(req.Root, trie.NewDatabase(odr.sdb))
+		nodes := NewNodeSet()
+		t.Prove(req.Key, 0, nodes)
+		req.Proof = nodes
 	}
 	req.StoreResult(odr.ldb)
 	return nil
@@ -193,7 +203,7 @@ func odrContractCall(ctx context.Context, db ethdb.Database, bc *core.BlockChain
 		}
 
 		// Perform read-only call.
-		st.SetBalance(testBankAddress, math.MaxBig256)
+		st.SetBalance(testBankAddress, math.MaxBig256, header.Number)
 		msg := callmsg{types.NewMessage(testBankAddress, &testContractAddr, 0, new(big.Int), 1000000, new(big.Int), data, false)}
 		context := core.NewEVMContext(msg, header, chain, nil)
 		vmenv := vm.NewEVM(context, st, config, vm.Config{})
@@ -19,6 +19,7 @@ package light
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/core"
@@ -26,6 +27,7 @@ import (
 	"github.com/etherzero/go-etherzero/core/types"
 	"github.com/etherzero/go-etherzero/crypto"
 	"github.com/etherzero/go-etherzero/rlp"
+	"github.com/etherzero/go-etherzero/trie"
 )
 
 var sha3_nil = crypto.Keccak256Hash(nil)
@@ -232,3 +234,60 @@ func GetBloomBits(ctx context.Context, odr OdrBackend, bitIdx uint, sectionIdxLi
 		return result, nil
 	}
 }
+
+// GetDevoteWitnesses retrieves the witness list of a cycle from the devote cycle
+// trie of the given header, fetching its Merkle proof if not available locally.
+func GetDevoteWitnesses(ctx context.Context, odr OdrBackend, header *types.Header, cycle uint64) ([]string, error) {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, cycle)
+
+	data, err := getDevoteEntry(ctx, odr, header, DevoteCycleTrie, header.Protocol.CycleHash, key)
+	if err != nil {
+		return nil, err
+	}
+	var witnesses []string
+	if err := rlp.DecodeBytes(data, &witnesses); err != nil {
+		return nil, err
+	}
+	return witnesses, nil
+}
+
+// GetDevoteStats retrieves the number of blocks sealed by a witness in a cycle
+// from the devote stats trie of the given header, fetching its Merkle proof if
+// not available locally.
+func GetDevoteStats(ctx context.Context, odr OdrBackend, header *types.Header, cycle uint64, witness string) (uint64, error) {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, cycle)
+	key = append(key, []byte(witness)...)
+
+	data, err := getDevoteEntry(ctx, odr, header, DevoteStatsTrie, header.Protocol.StatsHash, key)
+	if err != nil || data == nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+// getDevoteEntry retrieves the value of key from the given devote trie of a
+// header, fetching the trie nodes leading to it if not available locally.
+func getDevoteEntry(ctx context.Context, odr OdrBackend, header *types.Header, kind uint, root common.Hash, key []byte) ([]byte, error) {
+	if t, err := trie.NewSecure(root, trie.NewDatabase(odr.Database()), 0); err == nil {
+		if data, err := t.TryGet(key); err == nil {
+			return data, nil
+		}
+	}
+	r := &DevoteRequest{
+		BlockHash:   header.Hash(),
+		BlockNumber: header.Number.Uint64(),
+		Trie:        kind,
+		Root:        root,
+		Key:         crypto.Keccak256(key),
+	}
+	if err := odr.Retrieve(ctx, r); err != nil {
+		return nil, err
+	}
+	t, err := trie.NewSecure(root, trie.NewDatabase(odr.Database()), 0)
+	if err != nil {
+		return nil, err
+	}
+	return t.TryGet(key)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/core/state"
@@ -89,6 +90,10 @@ func (db *odrDatabase) TrieDB() *trie.Database {
 	return nil
 }
 
+func (db *odrDatabase) FixedPowerBlock() *big.Int {
+	return db.backend.FixedPowerBlock()
+}
+
 type odrTrie struct {
 	db   *odrDatabase
 	id   *TrieID
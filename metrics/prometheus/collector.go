@@ -0,0 +1,124 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/etherzero/go-etherzero/metrics"
+)
+
+var (
+	// quantiles are the quantiles reported for histograms and timers.
+	quantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999}
+
+	// resettingPercentiles are the percentiles reported for resetting timers,
+	// which take them in percent instead of fractions.
+	resettingPercentiles = []float64{50, 95, 99}
+)
+
+// collector accumulates metrics in the Prometheus text exposition format.
+type collector struct {
+	buff *bytes.Buffer
+}
+
+// newCollector creates an empty Prometheus metrics collector.
+func newCollector() *collector {
+	return &collector{buff: new(bytes.Buffer)}
+}
+
+// Add writes a single metric of the registry, ignoring unsupported types.
+func (c *collector) Add(name string, i interface{}) {
+	switch m := i.(type) {
+	case metrics.Counter:
+		// Counters can be decremented, so they can't be exposed as Prometheus
+		// counters which must never go down
+		c.writeGauge(name, float64(m.Count()))
+	case metrics.Gauge:
+		c.writeGauge(name, float64(m.Value()))
+	case metrics.GaugeFloat64:
+		c.writeGauge(name, m.Value())
+	case metrics.Meter:
+		c.writeGauge(name, float64(m.Count()))
+	case metrics.Histogram:
+		s := m.Snapshot()
+		c.writeSummary(name, s.Count(), float64(s.Sum()), quantiles, s.Percentiles(quantiles))
+	case metrics.Timer:
+		s := m.Snapshot()
+		c.writeSummary(name, s.Count(), float64(s.Sum()), quantiles, s.Percentiles(quantiles))
+	case metrics.ResettingTimer:
+		s := m.Snapshot()
+
+		values := s.Values()
+		if len(values) == 0 {
+			return
+		}
+		var sum int64
+		for _, v := range values {
+			sum += v
+		}
+		ps := s.Percentiles(resettingPercentiles)
+
+		qs, vs := make([]float64, len(ps)), make([]float64, len(ps))
+		for i, p := range ps {
+			qs[i], vs[i] = resettingPercentiles[i]/100, float64(p)
+		}
+		c.writeSummary(name, int64(len(values)), float64(sum), qs, vs)
+	}
+}
+
+// writeGauge writes a single gauge sample.
+func (c *collector) writeGauge(name string, value float64) {
+	name = mutateKey(name)
+
+	fmt.Fprintf(c.buff, "# TYPE %s gauge\n", name)
+	fmt.Fprintf(c.buff, "%s %s\n\n", name, formatFloat(value))
+}
+
+// writeSummary writes a summary with the given quantile values, along with the
+// total count and sum of the observations.
+func (c *collector) writeSummary(name string, count int64, sum float64, qs []float64, vs []float64) {
+	name = mutateKey(name)
+
+	fmt.Fprintf(c.buff, "# TYPE %s summary\n", name)
+	for i := range qs {
+		fmt.Fprintf(c.buff, "%s{quantile=\"%s\"} %s\n", name, formatFloat(qs[i]), formatFloat(vs[i]))
+	}
+	fmt.Fprintf(c.buff, "%s_sum %s\n", name, formatFloat(sum))
+	fmt.Fprintf(c.buff, "%s_count %d\n\n", name, count)
+}
+
+// mutateKey converts a registry metric name into a valid Prometheus metric name,
+// replacing all characters outside of [a-zA-Z0-9_:] with underscores.
+func mutateKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
+
+// formatFloat formats a sample value the way Prometheus parses it.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package prometheus exposes go-metrics registries in the Prometheus text format.
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/etherzero/go-etherzero/log"
+	"github.com/etherzero/go-etherzero/metrics"
+)
+
+// Handler returns an HTTP handler which dumps all metrics of the registry in the
+// Prometheus text exposition format, sorted by name.
+//
+// Note, resetting timers are reset by every scrape, the same way any other
+// reporter of the registry resets them.
+func Handler(reg metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Gather and pre-sort the metrics to avoid random listings
+		var names []string
+		reg.Each(func(name string, i interface{}) {
+			names = append(names, name)
+		})
+		sort.Strings(names)
+
+		// Aggregate all the metrics into a Prometheus collector
+		c := newCollector()
+		for _, name := range names {
+			if i := reg.Get(name); i != nil {
+				c.Add(name, i)
+			}
+		}
+		w.Header().Add("Content-Type", "text/plain; version=0.0.4")
+		w.Header().Add("Content-Length", fmt.Sprint(c.buff.Len()))
+		if _, err := w.Write(c.buff.Bytes()); err != nil {
+			log.Debug("Failed to write Prometheus metrics", "err", err)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etherzero/go-etherzero/metrics"
+)
+
+func init() {
+	metrics.Enabled = true
+}
+
+// Tests that every metric type of a registry is exposed in the Prometheus text
+// format when scraped over HTTP.
+func TestHandler(t *testing.T) {
+	reg := metrics.NewRegistry()
+
+	metrics.NewRegisteredCounter("txpool/pending/discard", reg).Inc(3)
+	metrics.NewRegisteredGauge("chain/head", reg).Update(42)
+	metrics.NewRegisteredGaugeFloat64("system/cpu.load", reg).Update(0.25)
+	metrics.NewRegisteredMeter("p2p/InboundTraffic", reg).Mark(7)
+
+	histogram := metrics.NewRegisteredHistogram("devote/missed", reg, metrics.NewUniformSample(100))
+	for i := int64(1); i <= 4; i++ {
+		histogram.Update(i)
+	}
+	timer := metrics.NewRegisteredTimer("chain/inserts", reg)
+	timer.Update(2 * time.Millisecond)
+	timer.Update(4 * time.Millisecond)
+
+	resetting := metrics.NewRegisteredResettingTimer("chain/account/reads", reg)
+	resetting.Update(10 * time.Nanosecond)
+	resetting.Update(30 * time.Nanosecond)
+
+	metrics.NewRegisteredResettingTimer("chain/account/writes", reg) // Empty, must be skipped
+
+	server := httptest.NewServer(Handler(reg))
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("failed to scrape metrics: %v", err)
+	}
+	defer res.Body.Close()
+
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type mismatch: have %q", ct)
+	}
+	blob, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("failed to read scrape: %v", err)
+	}
+	scrape := string(blob)
+
+	want := []string{
+		"# TYPE txpool_pending_discard gauge\ntxpool_pending_discard 3\n",
+		"# TYPE chain_head gauge\nchain_head 42\n",
+		"# TYPE system_cpu_load gauge\nsystem_cpu_load 0.25\n",
+		"# TYPE p2p_InboundTraffic gauge\np2p_InboundTraffic 7\n",
+		"# TYPE devote_missed summary\n",
+		"devote_missed{quantile=\"0.5\"} 2.5\n",
+		"devote_missed_sum 10\ndevote_missed_count 4\n",
+		"# TYPE chain_inserts summary\n",
+		"chain_inserts{quantile=\"0.9999\"} 4e+06\n",
+		"chain_inserts_sum 6e+06\nchain_inserts_count 2\n",
+		"# TYPE chain_account_reads summary\n",
+		"chain_account_reads{quantile=\"0.5\"} 10\n",
+		"chain_account_reads{quantile=\"0.99\"} 30\n",
+		"chain_account_reads_sum 40\nchain_account_reads_count 2\n",
+	}
+	for _, line := range want {
+		if !strings.Contains(scrape, line) {
+			t.Errorf("scrape missing %q:\n%s", line, scrape)
+		}
+	}
+	if strings.Contains(scrape, "chain_account_writes") {
+		t.Errorf("empty resetting timer exposed:\n%s", scrape)
+	}
+	// Metrics are listed in sorted order
+	if strings.Index(scrape, "chain_head") > strings.Index(scrape, "txpool_pending_discard") {
+		t.Errorf("metrics not sorted:\n%s", scrape)
+	}
+}
+
+func TestMutateKey(t *testing.T) {
+	tests := map[string]string{
+		"txpool/pending/discard": "txpool_pending_discard",
+		"system/cpu.load":        "system_cpu_load",
+		"eth/db/chaindata/disk":  "eth_db_chaindata_disk",
+		"les:serve-time":         "les:serve_time",
+	}
+	for in, want := range tests {
+		if have := mutateKey(in); have != want {
+			t.Errorf("%s: key mismatch: have %s, want %s", in, have, want)
+		}
+	}
+}
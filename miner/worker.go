@@ -287,13 +287,21 @@ func (self *worker) mine(now int64) {
 	self.mu.Unlock()
 }
 
+// now returns the current time of the devote engine's clock, which the witness
+// slots are scheduled with.
+func (self *worker) now() time.Time {
+	if engine, ok := self.engine.(*devote.Devote); ok {
+		return engine.Clock().Now()
+	}
+	return time.Now()
+}
+
 func (self *worker) mineLoop() {
 	ticker := time.NewTicker(time.Second).C
 	for {
 		select {
-		case now := <-ticker:
-			//	drift := time.Duration(discover.NanoDrift())
-			self.mine(now.Unix())
+		case <-ticker:
+			self.mine(self.now().Unix())
 		case <-self.stopper:
 			close(self.quitCh)
 			self.quitCh = make(chan struct{}, 1)
@@ -440,7 +448,7 @@ func (self *worker) makeCurrent(parent *types.Block, header *types.Header) error
 	if err != nil {
 		return err
 	}
-	devoteDB, err := devotedb.NewDevoteByProtocol(devotedb.NewDatabase(self.chainDb), parent.Header().Protocol)
+	devoteDB, err := devotedb.NewDevoteByProtocol(self.chain.DevoteCache(), parent.Header().Protocol)
 	if err != nil {
 		return err
 	}
@@ -482,12 +490,12 @@ func (self *worker) commitNewWork() (*Work, error) {
 	tstart := time.Now()
 	parent := self.chain.CurrentBlock()
 
-	tstamp := tstart.Unix()
+	tstamp := self.now().Unix()
 	if parent.Time().Cmp(new(big.Int).SetInt64(tstamp)) >= 0 {
 		tstamp = parent.Time().Int64() + 1
 	}
 	// this will ensure we're not going off too far in the future
-	if now := time.Now().Unix(); tstamp > now+1 {
+	if now := self.now().Unix(); tstamp > now+1 {
 		wait := time.Duration(tstamp-now) * time.Second
 		log.Info("Mining too far in the future", "wait", common.PrettyDuration(wait))
 		time.Sleep(wait)
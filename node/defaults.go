@@ -32,6 +32,9 @@ const (
 	DefaultHTTPPort = 9646        // Default TCP port for the HTTP RPC server
 	DefaultWSHost   = "localhost" // Default host interface for the websocket RPC server
 	DefaultWSPort   = 9647        // Default TCP port for the websocket RPC server
+
+	DefaultGraphQLHost = "localhost" // Default host interface for the GraphQL server
+	DefaultGraphQLPort = 9648        // Default TCP port for the GraphQL server
 )
 
 // DefaultConfig contains reasonable default settings.
@@ -43,6 +46,10 @@ var DefaultConfig = Config{
 	HTTPTimeouts:     rpc.DefaultHTTPTimeouts,
 	WSPort:           DefaultWSPort,
 	WSModules:        []string{"net", "web3"},
+
+	GraphQLPort:         DefaultGraphQLPort,
+	GraphQLVirtualHosts: []string{"localhost"},
+
 	P2P: p2p.Config{
 		ListenAddr: ":21212",
 		MaxPeers:   25,
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -31,6 +32,8 @@ import (
 	"github.com/etherzero/go-etherzero/event"
 	"github.com/etherzero/go-etherzero/internal/debug"
 	"github.com/etherzero/go-etherzero/log"
+	"github.com/etherzero/go-etherzero/metrics"
+	"github.com/etherzero/go-etherzero/metrics/prometheus"
 	"github.com/etherzero/go-etherzero/p2p"
 	"github.com/etherzero/go-etherzero/rpc"
 	"github.com/prometheus/prometheus/util/flock"
@@ -67,6 +70,10 @@ type Node struct {
 	wsListener net.Listener // Websocket RPC listener socket to server API requests
 	wsHandler  *rpc.Server  // Websocket RPC request handler to process the API requests
 
+	prometheusEndpoint string       // Prometheus metrics endpoint (interface + port) to listen at (empty = disabled)
+	prometheusListener net.Listener // Prometheus metrics listener socket to serve scrapes
+	prometheusServer   *http.Server // Prometheus metrics HTTP server handling the scrapes
+
 	stop chan struct{} // Channel to wait for termination notifications
 	lock sync.RWMutex
 
@@ -109,15 +116,16 @@ func New(conf *Config) (*Node, error) {
 	// Note: any interaction with Config that would create/touch files
 	// in the data directory or instance directory is delayed until Start.
 	return &Node{
-		accman:            am,
-		ephemeralKeystore: ephemeralKeystore,
-		config:            conf,
-		serviceFuncs:      []ServiceConstructor{},
-		ipcEndpoint:       conf.IPCEndpoint(),
-		httpEndpoint:      conf.HTTPEndpoint(),
-		wsEndpoint:        conf.WSEndpoint(),
-		eventmux:          new(event.TypeMux),
-		log:               conf.Logger,
+		accman:             am,
+		ephemeralKeystore:  ephemeralKeystore,
+		config:             conf,
+		serviceFuncs:       []ServiceConstructor{},
+		ipcEndpoint:        conf.IPCEndpoint(),
+		httpEndpoint:       conf.HTTPEndpoint(),
+		wsEndpoint:         conf.WSEndpoint(),
+		prometheusEndpoint: conf.PrometheusEndpoint,
+		eventmux:           new(event.TypeMux),
+		log:                conf.Logger,
 	}, nil
 }
 
@@ -274,6 +282,13 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 		n.stopInProc()
 		return err
 	}
+	if err := n.startPrometheus(n.prometheusEndpoint); err != nil {
+		n.stopWS()
+		n.stopHTTP()
+		n.stopIPC()
+		n.stopInProc()
+		return err
+	}
 	// All API endpoints started successfully
 	n.rpcAPIs = apis
 	return nil
@@ -396,6 +411,45 @@ func (n *Node) stopWS() {
 	}
 }
 
+// startPrometheus initializes and starts the Prometheus metrics endpoint.
+func (n *Node) startPrometheus(endpoint string) error {
+	// Short circuit if the Prometheus endpoint isn't being exposed
+	if endpoint == "" {
+		return nil
+	}
+	if !metrics.Enabled {
+		n.log.Warn("Prometheus endpoint enabled without metrics collection")
+	}
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", prometheus.Handler(metrics.DefaultRegistry))
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+
+	n.log.Info("Prometheus endpoint opened", "url", fmt.Sprintf("http://%s/metrics", listener.Addr()))
+	// All listeners booted successfully
+	n.prometheusEndpoint = endpoint
+	n.prometheusListener = listener
+	n.prometheusServer = server
+
+	return nil
+}
+
+// stopPrometheus terminates the Prometheus metrics endpoint.
+func (n *Node) stopPrometheus() {
+	if n.prometheusServer != nil {
+		n.prometheusServer.Close() // Closes the listener too
+		n.prometheusServer = nil
+		n.prometheusListener = nil
+
+		n.log.Info("Prometheus endpoint closed", "url", fmt.Sprintf("http://%s/metrics", n.prometheusEndpoint))
+	}
+}
+
 // Stop terminates a running node along with all it's services. In the node was
 // not started, an error is returned.
 func (n *Node) Stop() error {
@@ -408,6 +462,7 @@ func (n *Node) Stop() error {
 	}
 
 	// Terminate the API, services and the p2p server.
+	n.stopPrometheus()
 	n.stopWS()
 	n.stopHTTP()
 	n.stopIPC()
@@ -569,6 +624,18 @@ func (n *Node) WSEndpoint() string {
 	return n.wsEndpoint
 }
 
+// PrometheusEndpoint retrieves the current Prometheus metrics endpoint used by
+// the protocol stack.
+func (n *Node) PrometheusEndpoint() string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.prometheusListener != nil {
+		return n.prometheusListener.Addr().String()
+	}
+	return n.prometheusEndpoint
+}
+
 // EventMux retrieves the event multiplexer used by all the network services in
 // the current protocol stack.
 func (n *Node) EventMux() *event.TypeMux {
@@ -18,13 +18,17 @@ package node
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/etherzero/go-etherzero/crypto"
+	"github.com/etherzero/go-etherzero/metrics"
 	"github.com/etherzero/go-etherzero/p2p"
 	"github.com/etherzero/go-etherzero/rpc"
 )
@@ -74,6 +78,43 @@ func TestNodeLifeCycle(t *testing.T) {
 	}
 }
 
+// Tests that the Prometheus endpoint serves the default metrics registry while
+// the node is running, and is closed when it's stopped.
+func TestNodePrometheusEndpoint(t *testing.T) {
+	metrics.NewRegisteredCounter("node/test/scrapes", nil)
+
+	config := testNodeConfig()
+	config.PrometheusEndpoint = "127.0.0.1:0"
+
+	stack, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	url := fmt.Sprintf("http://%s/metrics", stack.PrometheusEndpoint())
+
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("failed to scrape metrics: %v", err)
+	}
+	blob, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to read scrape: %v", err)
+	}
+	if !strings.Contains(string(blob), "# TYPE node_test_scrapes gauge") {
+		t.Errorf("registered metric missing from scrape:\n%s", blob)
+	}
+	if err := stack.Stop(); err != nil {
+		t.Fatalf("failed to stop node: %v", err)
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Errorf("metrics still served after stop")
+	}
+}
+
 // Tests that if the data dir is already in use, an appropriate error is returned.
 func TestNodeUsedDataDir(t *testing.T) {
 	// Create a temporary folder to use as the data directory
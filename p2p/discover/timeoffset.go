@@ -0,0 +1,108 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/etherzero/go-etherzero/p2p/enode"
+)
+
+const (
+	minOffsetSamples = 5                // Number of peer samples needed before adjusting the clock
+	maxOffsetSamples = 256              // Number of peer samples kept, the oldest one is replaced
+	maxPeerOffset    = 10 * time.Minute // Offsets beyond this are deemed broken peer clocks
+)
+
+// TimeOffsets calculates the offset of the local clock from the network time,
+// as the median of the clock offsets measured against distinct peers. A single
+// peer may contribute one sample, so that the median can only be skewed by a
+// majority of the sampled peers.
+type TimeOffsets struct {
+	samples map[enode.ID]time.Duration // Latest offset measured per peer
+	order   []enode.ID                 // Sampled peers, from the oldest sample
+	lock    sync.RWMutex
+}
+
+// NewTimeOffsets creates an empty peer time offset calculator.
+func NewTimeOffsets() *TimeOffsets {
+	return &TimeOffsets{samples: make(map[enode.ID]time.Duration)}
+}
+
+// Add records the offset of the clock of a peer from the local clock, replacing
+// any previous sample of the same peer. Offsets beyond maxPeerOffset are dropped.
+func (t *TimeOffsets) Add(id enode.ID, offset time.Duration) {
+	if offset > maxPeerOffset || offset < -maxPeerOffset {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.samples[id]; !ok {
+		if len(t.order) >= maxOffsetSamples {
+			delete(t.samples, t.order[0])
+			t.order = t.order[1:]
+		}
+		t.order = append(t.order, id)
+	}
+	t.samples[id] = offset
+}
+
+// Offset returns the median offset of the peer clocks from the local clock, or
+// zero if too few peers were sampled yet.
+func (t *TimeOffsets) Offset() time.Duration {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if len(t.samples) < minOffsetSamples {
+		return 0
+	}
+	offsets := make([]time.Duration, 0, len(t.samples))
+	for _, offset := range t.samples {
+		offsets = append(offsets, offset)
+	}
+	sort.Sort(durationSlice(offsets))
+
+	middle := len(offsets) / 2
+	if len(offsets)%2 == 0 {
+		return (offsets[middle-1] + offsets[middle]) / 2
+	}
+	return offsets[middle]
+}
+
+// peerOffsets collects the clock offsets of the peers answering the pings of
+// the discovery protocol. Unsolicited pings are not sampled, as anybody can
+// send them from any number of node keys.
+var peerOffsets = NewTimeOffsets()
+
+// PeerTimeOffset returns the median offset of the clocks of the discovery peers
+// from the local clock. Adding it to the local time yields the network time.
+// The peers are unauthenticated, so users must bound the applied offset.
+func PeerTimeOffset() time.Duration {
+	return peerOffsets.Offset()
+}
+
+// samplePeerTime records the clock offset of a peer from the expiration of its
+// pong packet, which the sender sets to its own time plus the fixed packet
+// expiration window. The timestamp is truncated to seconds, so half a second is
+// added to center the estimate.
+func samplePeerTime(id enode.ID, ts uint64) {
+	sent := time.Unix(int64(ts), 0).Add(time.Second/2 - expiration)
+	peerOffsets.Add(id, sent.Sub(time.Now()))
+}
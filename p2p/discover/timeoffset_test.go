@@ -0,0 +1,66 @@
+// Copyright 2018 The go-etherzero Authors
+// This file is part of the go-etherzero library.
+//
+// The go-etherzero library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-etherzero library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-etherzero library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"testing"
+	"time"
+
+	"github.com/etherzero/go-etherzero/p2p/enode"
+)
+
+// Tests that the peer time offset is the median of the latest sample of every
+// peer, and that a minority of broken clocks can't skew it.
+func TestTimeOffsets(t *testing.T) {
+	offsets := NewTimeOffsets()
+	peer := func(i byte) enode.ID { return enode.ID{i} }
+
+	// Too few samples must leave the clock alone
+	for i := byte(0); i < minOffsetSamples-1; i++ {
+		offsets.Add(peer(i), time.Second)
+	}
+	if offset := offsets.Offset(); offset != 0 {
+		t.Fatalf("offset with too few samples: have %v, want 0", offset)
+	}
+	// Repeated samples of one peer must count once
+	for i := 0; i < 10; i++ {
+		offsets.Add(peer(0), 5*time.Minute)
+	}
+	if offset := offsets.Offset(); offset != 0 {
+		t.Fatalf("offset with repeated samples: have %v, want 0", offset)
+	}
+	offsets.Add(peer(minOffsetSamples), 2*time.Second)
+	if offset := offsets.Offset(); offset != time.Second {
+		t.Fatalf("median offset mismatch: have %v, want %v", offset, time.Second)
+	}
+	offsets.Add(peer(minOffsetSamples+1), 2*time.Second)
+	if offset := offsets.Offset(); offset != 3*time.Second/2 {
+		t.Fatalf("median offset mismatch: have %v, want %v", offset, 3*time.Second/2)
+	}
+	// Broken clocks must be ignored
+	offsets.Add(peer(minOffsetSamples+2), time.Hour)
+	if offset := offsets.Offset(); offset != 3*time.Second/2 {
+		t.Fatalf("median offset with broken clock: have %v, want %v", offset, 3*time.Second/2)
+	}
+	// The oldest peers must be replaced once full
+	for i := 0; i < maxOffsetSamples; i++ {
+		offsets.Add(enode.ID{0xff, byte(i)}, -time.Second)
+	}
+	if offset := offsets.Offset(); offset != -time.Second {
+		t.Fatalf("median offset after eviction: have %v, want %v", offset, -time.Second)
+	}
+}
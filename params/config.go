@@ -70,6 +70,17 @@ var (
 				"3b9471c1b4d93a45",
 				"8375c6b34607d06b",
 			},
+			Schedule: []*DevoteParams{
+				{
+					Block:           big.NewInt(0),
+					MaxWitnessSize:  21,
+					SafeSize:        15,
+					ConsensusSize:   15,
+					BlockReward:     big.NewInt(0.3375e+18),
+					CommunityReward: big.NewInt(0.1125e+18),
+					PingTimeout:     3600,
+				},
+			},
 		},
 	}
 
@@ -123,16 +134,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), new(EthashConfig), nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), new(EthashConfig), nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -175,6 +186,8 @@ type ChainConfig struct {
 	ConstantinopleBlock *big.Int `json:"constantinopleBlock,omitempty"` // Constantinople switch block (nil = no fork, 0 = already activated)
 	EWASMBlock          *big.Int `json:"ewasmBlock,omitempty"`          // EWASM switch block (nil = no fork, 0 = already activated)
 
+	FixedPowerBlock *big.Int `json:"fixedPowerBlock,omitempty"` // Fixed-point power calculation switch block (nil = no fork, 0 = already activated)
+
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
@@ -202,7 +215,59 @@ func (c *CliqueConfig) String() string {
 
 // MasternodeConfig is the consensus engine configs for devote + delegated proof-of-stake based sealing.
 type DevoteConfig struct {
-	Witnesses []string `json:"witnesses"` // Genesis witness list
+	Witnesses      []string        `json:"witnesses"`                // Genesis witness list
+	Schedule       []*DevoteParams `json:"schedule,omitempty"`       // Consensus parameters by activation block, in ascending order
+	MaxFutureDrift uint64          `json:"maxFutureDrift,omitempty"` // Seconds a block may be timestamped ahead of the local clock (0 = default)
+}
+
+// DevoteParams are the devote consensus parameters in effect from their activation
+// block until the next entry of the schedule.
+type DevoteParams struct {
+	Block           *big.Int `json:"block"`           // Activation block of the parameters
+	MaxWitnessSize  uint64   `json:"maxWitnessSize"`  // Maximum number of witnesses elected per cycle
+	SafeSize        int      `json:"safeSize"`        // Minimum number of masternodes required for an election
+	ConsensusSize   int      `json:"consensusSize"`   // Number of distinct witnesses needed to confirm a block
+	BlockReward     *big.Int `json:"blockReward"`     // Reward in wei to the witness sealing a block
+	CommunityReward *big.Int `json:"communityReward"` // Reward in wei to the governance contract per block
+	PingTimeout     uint64   `json:"pingTimeout"`     // Blocks after the last ping a masternode is considered offline
+}
+
+// DefaultDevoteParams are the devote parameters used until the first entry of the
+// schedule is activated, fit for a single witness private network.
+var DefaultDevoteParams = &DevoteParams{
+	Block:           big.NewInt(0),
+	MaxWitnessSize:  1,
+	SafeSize:        1,
+	ConsensusSize:   1,
+	BlockReward:     big.NewInt(0.3375e+18),
+	CommunityReward: big.NewInt(0.1125e+18),
+	PingTimeout:     3600,
+}
+
+// Params returns the devote parameters in effect at the given block.
+func (d *DevoteConfig) Params(num *big.Int) *DevoteParams {
+	params := DefaultDevoteParams
+	if d == nil {
+		return params
+	}
+	for _, p := range d.Schedule {
+		if !isForked(p.Block, num) {
+			break
+		}
+		params = p
+	}
+	return params
+}
+
+// equal reports whether two parameter sets enforce the same consensus rules.
+func (p *DevoteParams) equal(q *DevoteParams) bool {
+	return configNumEqual(p.Block, q.Block) &&
+		p.MaxWitnessSize == q.MaxWitnessSize &&
+		p.SafeSize == q.SafeSize &&
+		p.ConsensusSize == q.ConsensusSize &&
+		configNumEqual(p.BlockReward, q.BlockReward) &&
+		configNumEqual(p.CommunityReward, q.CommunityReward) &&
+		p.PingTimeout == q.PingTimeout
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -221,7 +286,7 @@ func (c *ChainConfig) String() string {
 	default:
 		engine = "unknown"
 	}
-	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v FixedPower: %v Engine: %v}",
 		c.ChainID,
 		c.HomesteadBlock,
 		c.DAOForkBlock,
@@ -231,6 +296,7 @@ func (c *ChainConfig) String() string {
 		c.EIP158Block,
 		c.ByzantiumBlock,
 		c.ConstantinopleBlock,
+		c.FixedPowerBlock,
 		engine,
 	)
 }
@@ -348,9 +414,42 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
+	if isForkIncompatible(c.FixedPowerBlock, newcfg.FixedPowerBlock, head) {
+		return newCompatError("fixed-point power fork block", c.FixedPowerBlock, newcfg.FixedPowerBlock)
+	}
+	if block := isDevoteScheduleIncompatible(c.Devote, newcfg.Devote, head); block != nil {
+		return newCompatError("devote schedule", block, block)
+	}
 	return nil
 }
 
+// isDevoteScheduleIncompatible returns the activation block of the first devote
+// parameters which differ between the two configs although head is already past
+// them, or nil if the schedules are compatible.
+func isDevoteScheduleIncompatible(c1, c2 *DevoteConfig, head *big.Int) *big.Int {
+	var blocks []*big.Int
+	if c1 != nil {
+		for _, p := range c1.Schedule {
+			blocks = append(blocks, p.Block)
+		}
+	}
+	if c2 != nil {
+		for _, p := range c2.Schedule {
+			blocks = append(blocks, p.Block)
+		}
+	}
+	var first *big.Int
+	for _, block := range blocks {
+		if !isForked(block, head) || c1.Params(block).equal(c2.Params(block)) {
+			continue
+		}
+		if first == nil || block.Cmp(first) < 0 {
+			first = block
+		}
+	}
+	return first
+}
+
 // isForkIncompatible returns true if a fork scheduled at s1 cannot be rescheduled to
 // block s2 because head is already past the fork.
 func isForkIncompatible(s1, s2, head *big.Int) bool {
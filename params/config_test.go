@@ -70,6 +70,23 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			stored:  &ChainConfig{Devote: &DevoteConfig{Schedule: []*DevoteParams{{Block: big.NewInt(10), MaxWitnessSize: 21}}}},
+			new:     &ChainConfig{Devote: &DevoteConfig{Schedule: []*DevoteParams{{Block: big.NewInt(20), MaxWitnessSize: 21}}}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Devote: &DevoteConfig{Schedule: []*DevoteParams{{Block: big.NewInt(10), MaxWitnessSize: 21}}}},
+			new:    &ChainConfig{Devote: &DevoteConfig{Schedule: []*DevoteParams{{Block: big.NewInt(10), MaxWitnessSize: 17}}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "devote schedule",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -79,3 +96,30 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestDevoteParams(t *testing.T) {
+	config := &DevoteConfig{
+		Schedule: []*DevoteParams{
+			{Block: big.NewInt(10), MaxWitnessSize: 21},
+			{Block: big.NewInt(20), MaxWitnessSize: 31},
+		},
+	}
+	tests := []struct {
+		config *DevoteConfig
+		number int64
+		want   uint64
+	}{
+		{nil, 100, DefaultDevoteParams.MaxWitnessSize},
+		{config, 0, DefaultDevoteParams.MaxWitnessSize},
+		{config, 9, DefaultDevoteParams.MaxWitnessSize},
+		{config, 10, 21},
+		{config, 19, 21},
+		{config, 20, 31},
+		{config, 1000, 31},
+	}
+	for _, test := range tests {
+		if have := test.config.Params(big.NewInt(test.number)).MaxWitnessSize; have != test.want {
+			t.Errorf("block %d: max witness size mismatch: have %d, want %d", test.number, have, test.want)
+		}
+	}
+}
@@ -30,6 +30,8 @@ import (
 	"github.com/etherzero/go-etherzero/accounts/usbwallet"
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/common/hexutil"
+	"github.com/etherzero/go-etherzero/consensus/devote"
+	"github.com/etherzero/go-etherzero/core/types"
 	"github.com/etherzero/go-etherzero/crypto"
 	"github.com/etherzero/go-etherzero/internal/ethapi"
 	"github.com/etherzero/go-etherzero/log"
@@ -49,6 +51,8 @@ type ExternalAPI interface {
 	SignTransaction(ctx context.Context, args SendTxArgs, methodSelector *string) (*ethapi.SignTransactionResult, error)
 	// Sign - request to sign the given data (plus prefix)
 	Sign(ctx context.Context, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error)
+	// SignDevoteHeader - request to seal the given devote block header
+	SignDevoteHeader(ctx context.Context, addr common.MixedcaseAddress, header hexutil.Bytes) (hexutil.Bytes, error)
 	// Export - request to export an account
 	Export(ctx context.Context, addr common.Address) (json.RawMessage, error)
 	// Import - request to import an account
@@ -548,6 +552,41 @@ func (api *SignerAPI) Sign(ctx context.Context, addr common.MixedcaseAddress, da
 	return signature, nil
 }
 
+// SignDevoteHeader seals a RLP encoded devote block header on behalf of a
+// masternode. Unlike Sign, the seal hash of the header is signed without any
+// prefix and V is kept at 0/1, as expected by the devote consensus engine.
+func (api *SignerAPI) SignDevoteHeader(ctx context.Context, addr common.MixedcaseAddress, blob hexutil.Bytes) (hexutil.Bytes, error) {
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(blob, header); err != nil {
+		return nil, fmt.Errorf("invalid devote header: %v", err)
+	}
+	sighash := devote.SealHash(header)
+	msg := fmt.Sprintf("Seal devote block #%v of witness %s", header.Number, header.Witness)
+
+	// We make the request prior to looking up if we actually have the account, to prevent
+	// account-enumeration via the API
+	req := &SignDataRequest{Address: addr, Rawdata: blob, Message: msg, Hash: sighash.Bytes(), Meta: MetadataFromContext(ctx)}
+	res, err := api.UI.ApproveSignData(req)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Approved {
+		return nil, ErrRequestDenied
+	}
+	// Look up the wallet containing the requested signer
+	account := accounts.Account{Address: addr.Address()}
+	wallet, err := api.am.Find(account)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := wallet.SignHashWithPassphrase(account, res.Password, sighash.Bytes())
+	if err != nil {
+		api.UI.ShowError(err.Error())
+		return nil, err
+	}
+	return signature, nil
+}
+
 // SignHash is a helper function that calculates a hash for the given message that can be
 // safely used to calculate a signature from.
 //
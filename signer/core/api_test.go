@@ -32,7 +32,10 @@ import (
 	"github.com/etherzero/go-etherzero/cmd/utils"
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/common/hexutil"
+	"github.com/etherzero/go-etherzero/consensus/devote"
 	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/devotedb"
+	"github.com/etherzero/go-etherzero/crypto"
 	"github.com/etherzero/go-etherzero/internal/ethapi"
 	"github.com/etherzero/go-etherzero/rlp"
 )
@@ -284,6 +287,54 @@ func TestSignData(t *testing.T) {
 		t.Errorf("Expected 65 byte signature (got %d bytes)", len(h))
 	}
 }
+
+func TestSignDevoteHeader(t *testing.T) {
+	api, control := setup(t)
+	createAccount(control, api, t)
+	control <- "A"
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := common.NewMixedcaseAddress(list[0])
+
+	header := &types.Header{Number: big.NewInt(1), Time: big.NewInt(100), Witness: "0123456789abcdef", Extra: make([]byte, 65), Protocol: new(devotedb.DevoteProtocol)}
+	blob, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Malformed headers are rejected before asking the user
+	if _, err := api.SignDevoteHeader(context.Background(), a, []byte{0x01}); err == nil {
+		t.Errorf("Expected error on malformed header")
+	}
+	control <- "No way"
+	if sig, err := api.SignDevoteHeader(context.Background(), a, blob); sig != nil || err != ErrRequestDenied {
+		t.Errorf("Expected ErrRequestDenied! %x %v", sig, err)
+	}
+	control <- "Y"
+	control <- "wrongpassword"
+	if sig, err := api.SignDevoteHeader(context.Background(), a, blob); sig != nil || err != keystore.ErrDecrypt {
+		t.Errorf("Expected ErrDecrypt! %x %v", sig, err)
+	}
+	control <- "Y"
+	control <- "a_long_password"
+	sig, err := api.SignDevoteHeader(context.Background(), a, blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The seal hash is signed without prefix, and V is left at 0/1 for the engine
+	if len(sig) != 65 || sig[64] > 1 {
+		t.Fatalf("Expected 65 byte signature with V 0/1, got %x", sig)
+	}
+	pubkey, err := crypto.SigToPub(devote.SealHash(header).Bytes(), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr := crypto.PubkeyToAddress(*pubkey); addr != a.Address() {
+		t.Errorf("Expected seal by %x, got %x", a.Address(), addr)
+	}
+}
+
 func mkTestTx(from common.MixedcaseAddress) SendTxArgs {
 	to := common.NewMixedcaseAddress(common.HexToAddress("0x1337"))
 	gas := hexutil.Uint64(21000)
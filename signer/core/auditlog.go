@@ -71,6 +71,14 @@ func (l *AuditLogger) Sign(ctx context.Context, addr common.MixedcaseAddress, da
 	return b, e
 }
 
+func (l *AuditLogger) SignDevoteHeader(ctx context.Context, addr common.MixedcaseAddress, header hexutil.Bytes) (hexutil.Bytes, error) {
+	l.log.Info("SignDevoteHeader", "type", "request", "metadata", MetadataFromContext(ctx).String(),
+		"addr", addr.String(), "header", common.Bytes2Hex(header))
+	b, e := l.api.SignDevoteHeader(ctx, addr, header)
+	l.log.Info("SignDevoteHeader", "type", "response", "data", common.Bytes2Hex(b), "error", e)
+	return b, e
+}
+
 func (l *AuditLogger) Export(ctx context.Context, addr common.Address) (json.RawMessage, error) {
 	l.log.Info("Export", "type", "request", "metadata", MetadataFromContext(ctx).String(),
 		"addr", addr.Hex())
@@ -17,16 +17,24 @@
 package rules
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
 	"math/big"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/etherzero/go-etherzero/accounts"
+	"github.com/etherzero/go-etherzero/accounts/keystore"
 	"github.com/etherzero/go-etherzero/common"
 	"github.com/etherzero/go-etherzero/common/hexutil"
+	"github.com/etherzero/go-etherzero/consensus/devote"
 	"github.com/etherzero/go-etherzero/core/types"
+	"github.com/etherzero/go-etherzero/core/types/devotedb"
+	"github.com/etherzero/go-etherzero/crypto"
 	"github.com/etherzero/go-etherzero/internal/ethapi"
+	"github.com/etherzero/go-etherzero/rlp"
 	"github.com/etherzero/go-etherzero/signer/core"
 	"github.com/etherzero/go-etherzero/signer/storage"
 )
@@ -656,3 +664,53 @@ function ApproveSignData(r){
 		t.Fatalf("Expected approved")
 	}
 }
+
+// Tests that devote seals can be approved by a rule, so that masternodes keep
+// sealing without anyone confirming every block.
+func TestSignDevoteHeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rules-devote-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	account, err := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP).NewAccount("a_long_password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	js := fmt.Sprintf(`function ApproveSignData(r){
+    if(r.address.toLowerCase() == "%s" && r.message.indexOf("Seal devote block #") == 0){
+        return "Approve"
+    }
+    // Otherwise goes to manual processing
+}`, strings.ToLower(account.Address.Hex()))
+
+	// Anything not approved by the rule is denied by the next UI
+	credentials := storage.NewEphemeralStorage()
+	credentials.Put(strings.ToLower(account.Address.String()), "a_long_password")
+	r, err := NewRuleEvaluator(&alwaysDenyUI{}, storage.NewEphemeralStorage(), credentials)
+	if err != nil {
+		t.Fatalf("Failed to create js engine: %v", err)
+	}
+	if err = r.Init(js); err != nil {
+		t.Fatalf("Failed to load bootstrap js: %v", err)
+	}
+	api := core.NewSignerAPI(1, dir, true, r, nil, true, true)
+	addr := common.NewMixedcaseAddress(account.Address)
+
+	header := &types.Header{Number: big.NewInt(1), Time: big.NewInt(100), Witness: "0123456789abcdef", Extra: make([]byte, 65), Protocol: new(devotedb.DevoteProtocol)}
+	blob, _ := rlp.EncodeToBytes(header)
+
+	sig, err := api.SignDevoteHeader(context.Background(), addr, blob)
+	if err != nil {
+		t.Fatalf("Seal not approved: %v", err)
+	}
+	pubkey, err := crypto.SigToPub(devote.SealHash(header).Bytes(), sig)
+	if err != nil || crypto.PubkeyToAddress(*pubkey) != account.Address {
+		t.Errorf("Seal not signed by the masternode account: %v", err)
+	}
+	// Other data of the masternode account still needs manual approval
+	if _, err := api.Sign(context.Background(), addr, []byte("Seal devote block #1")); err != core.ErrRequestDenied {
+		t.Errorf("Expected ErrRequestDenied, got %v", err)
+	}
+}